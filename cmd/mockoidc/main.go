@@ -0,0 +1,50 @@
+// Command mockoidc adalah provider OpenID Connect minimal untuk menguji login sosial secara lokal tanpa jaringan.
+//
+// Pemakaian:
+//
+//	go run ./cmd/mockoidc
+//	# .env API: OAUTH_PROVIDERS=mock, OAUTH_MOCK_ISSUER=http://localhost:9000,
+//	#           OAUTH_MOCK_CLIENT_ID=ecommerce, OAUTH_MOCK_CLIENT_SECRET=mock-secret
+//	# buka http://localhost:8080/auth/oauth/mock/start
+//
+// Halaman authorize langsung menyetujui login sebagai MOCK_OIDC_EMAIL, atau sebagai
+// email di parameter login_hint (mis. .../start lalu tambahkan &login_hint=a@b.com pada URL authorize).
+// Set MOCK_OIDC_EMAIL_VERIFIED=false untuk menguji email yang belum diverifikasi provider.
+// Provider-nya sendiri ada di internal/oauth/oauthtest. Tidak untuk produksi.
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/oauth/oauthtest"
+)
+
+func main() {
+	addr := env("MOCK_OIDC_ADDR", ":9000")
+	provider, err := oauthtest.NewProvider(
+		env("MOCK_OIDC_CLIENT_ID", "ecommerce"),
+		env("MOCK_OIDC_CLIENT_SECRET", "mock-secret"),
+		env("MOCK_OIDC_EMAIL", "mock.user@example.com"),
+	)
+	if err != nil {
+		log.Fatalf("gagal membuat kunci RSA: %v", err)
+	}
+	provider.Issuer = strings.TrimRight(env("MOCK_OIDC_ISSUER", "http://localhost:9000"), "/")
+	if provider.EmailVerified, err = strconv.ParseBool(env("MOCK_OIDC_EMAIL_VERIFIED", "true")); err != nil {
+		log.Fatalf("MOCK_OIDC_EMAIL_VERIFIED tidak valid: %v", err)
+	}
+
+	log.Printf("mock OIDC provider di %s (issuer %s)", addr, provider.Issuer)
+	log.Fatal(http.ListenAndServe(addr, provider.Handler()))
+}
+
+func env(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -9,10 +10,17 @@ import (
 	"github.com/itujun/project-ecommerce-go-next/internal/config"
 	"github.com/itujun/project-ecommerce-go-next/internal/database"
 	"github.com/itujun/project-ecommerce-go-next/internal/handler"
+	"github.com/itujun/project-ecommerce-go-next/internal/jobs"
+	"github.com/itujun/project-ecommerce-go-next/internal/mail"
 	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
+	"github.com/itujun/project-ecommerce-go-next/internal/oauth"
+	"github.com/itujun/project-ecommerce-go-next/internal/password"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
 	"github.com/itujun/project-ecommerce-go-next/internal/repository/gorm"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository/memory"
 	"github.com/itujun/project-ecommerce-go-next/internal/routes"
 	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"github.com/itujun/project-ecommerce-go-next/internal/sms"
 	"go.uber.org/zap"
 )
 
@@ -44,30 +52,159 @@ func main() {
 	userRepo	:= gorm.NewUserRepository(db)
 	roleRepo	:= gorm.NewRoleRepository(db)
 	rtRepo   	:= gorm.NewRefreshTokenRepository(db)
-	jwtService 	:= service.NewJWTService(cfg)
-	userService := service.NewUserService(userRepo, roleRepo, rtRepo, jwtService, cfg.JWTAccessSecret)
-	authHandler := handler.NewAuthHandler(userService, jwtService)
+	// Kunci tanda tangan AT: dimuat dari JWT_KEY_DIR dan dirotasi berkala
+	keyManager, err := service.NewKeyManager(cfg, logger)
+	if err != nil {
+		logger.Fatal("❌gagal memuat kunci JWT", zap.Error(err))
+	}
+	go keyManager.Run(context.Background(), cfg.JWTKeyCheckInterval)
+
+	jwtService 	:= service.NewJWTService(cfg, keyManager)
+
+	// Proteksi brute-force login; store database dibagi antar instance
+	var loginAttemptRepo repository.LoginAttemptRepository
+	if cfg.LoginAttemptStore == "memory" {
+		loginAttemptRepo = memory.NewLoginAttemptRepository(max(cfg.LoginFailureWindow, cfg.LoginLockoutDuration))
+	} else {
+		loginAttemptRepo = gorm.NewLoginAttemptRepository(db)
+	}
+	loginGuard := service.NewLoginGuard(loginAttemptRepo, cfg, logger)
+
+	// Denylist AT (jti & cutoff per user); entri kedaluwarsa dibersihkan berkala
+	var revocationRepo repository.TokenRevocationRepository
+	if cfg.TokenRevocationStore == "memory" {
+		revocationRepo = memory.NewTokenRevocationRepository()
+	} else {
+		revocationRepo = gorm.NewTokenRevocationRepository(db)
+	}
+	revocationService := service.NewTokenRevocationService(revocationRepo, cfg, logger)
+	go revocationService.Run(context.Background(), cfg.TokenRevocationPurge)
 
-	// Inisialisasi enforcer Casbin
-    enforcer, err := authorization.NewEnforcer("config/rbac_model.conf", "config/rbac_policy.csv")
+	// Hash password: argon2id/bcrypt sesuai PASSWORD_HASH_ALGORITHM, hash lama di-upgrade saat login
+	passwordHasher, err := password.NewFromConfig(cfg)
+	if err != nil {
+		logger.Fatal("❌gagal inisialisasi password hasher", zap.Error(err))
+	}
+	// Kebijakan password (PASSWORD_*): panjang, jenis karakter, daftar password umum/bocor
+	passwordPolicy, err := password.NewPolicyFromConfig(cfg, logger)
+	if err != nil {
+		logger.Fatal("❌gagal inisialisasi kebijakan password", zap.Error(err))
+	}
+	defer passwordPolicy.Close()
+	userService := service.NewUserService(userRepo, roleRepo, rtRepo, jwtService, loginGuard, passwordHasher, passwordPolicy, cfg, logger)
+	userTokenRepo := gorm.NewUserTokenRepository(db)
+
+	// Pengirim email (MAIL_DRIVER=log untuk dev, file untuk test)
+	mailer, err := mail.NewSender(cfg, logger)
+	if err != nil {
+		logger.Fatal("❌gagal inisialisasi mail sender", zap.Error(err))
+	}
+	verificationService := service.NewEmailVerificationService(userRepo, userTokenRepo, mailer, cfg, logger)
+
+	// Pengirim SMS (SMS_DRIVER=log untuk dev, file untuk test); OTP untuk verifikasi nomor, login, dan 2FA SMS
+	smsSender, err := sms.NewSender(cfg, logger)
+	if err != nil {
+		logger.Fatal("❌gagal inisialisasi sms sender", zap.Error(err))
+	}
+	phoneService := service.NewPhoneService(userRepo, gorm.NewPhoneOTPRepository(db), smsSender, cfg, logger)
+
+	// 2FA (TOTP/SMS); LoginFlow memutuskan apakah login butuh langkah kedua
+	mfaRepo := gorm.NewMFARepository(db)
+	mfaService := service.NewMFAService(mfaRepo, userRepo, phoneService, loginGuard, cfg, logger)
+	// Token CSRF double-submit untuk request yang diautentikasi lewat cookie
+	csrfService := service.NewCSRFService(cfg)
+	loginFlow := handler.NewLoginFlow(userService, jwtService, mfaService, csrfService)
+	mfaHandler := handler.NewMFAHandler(mfaService, userService, jwtService, revocationService, loginFlow)
+
+	// Login sosial OIDC (OAUTH_PROVIDERS)
+	identityRepo := gorm.NewUserIdentityRepository(db)
+	oauthService := service.NewOAuthService(oauth.NewRegistry(cfg), userRepo, roleRepo, identityRepo, cfg, logger)
+	oauthHandler := handler.NewOAuthHandler(oauthService, jwtService, loginFlow, cfg)
+
+	authHandler := handler.NewAuthHandler(userService, jwtService, verificationService, loginFlow, revocationService)
+	verificationHandler := handler.NewEmailVerificationHandler(verificationService)
+	passwordResetService := service.NewPasswordResetService(userRepo, userTokenRepo, rtRepo, revocationService, mailer, passwordHasher, passwordPolicy, cfg, logger)
+	passwordResetHandler := handler.NewPasswordResetHandler(passwordResetService)
+	magicLinkService := service.NewMagicLinkService(userRepo, userTokenRepo, jwtService, mailer, cfg, logger)
+	magicLinkHandler := handler.NewMagicLinkHandler(magicLinkService, loginFlow, cfg)
+	profileService := service.NewProfileService(userRepo, userTokenRepo, rtRepo, revocationService, passwordHasher, passwordPolicy, mailer, cfg, logger)
+	profileHandler := handler.NewProfileHandler(profileService, jwtService)
+	sessionHandler := handler.NewSessionHandler(userService, revocationService)
+	jwksHandler := handler.NewJWKSHandler(keyManager)
+	// Audit log (impersonasi, perubahan policy & role)
+	auditService := service.NewAuditService(gorm.NewAuditLogRepository(db), logger)
+	// API key untuk integrasi machine-to-machine (ERP seller)
+	apiKeyRepo := gorm.NewAPIKeyRepository(db)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, logger)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	// Passkey (WebAuthn) untuk login tanpa password
+	webauthnCredentialRepo := gorm.NewWebAuthnCredentialRepository(db)
+	webauthnService, err := service.NewWebAuthnService(webauthnCredentialRepo, userRepo, jwtService, cfg, logger)
+	if err != nil {
+		logger.Fatal("❌gagal inisialisasi WebAuthn", zap.Error(err))
+	}
+	webauthnHandler := handler.NewWebAuthnHandler(webauthnService, loginFlow)
+	phoneHandler := handler.NewPhoneHandler(phoneService, loginFlow)
+
+	// Inisialisasi enforcer Casbin; policy di tabel casbin_rule (diisi dari CASBIN_POLICY_SEED saat kosong)
+	policyRepo := gorm.NewPolicyRepository(db)
+    enforcer, err := authorization.NewEnforcer("config/rbac_model.conf", cfg.CasbinPolicySeed, policyRepo, logger)
     if err != nil {
         logger.Fatal("gagal inisialisasi Casbin", zap.Error(err))
     }
+	// Reload enforcer saat policy diubah instance lain
+	go authorization.NewPolicyWatcher(policyRepo, enforcer, logger).Run(context.Background(), cfg.CasbinPolicyPoll)
+	policyHandler := handler.NewPolicyHandler(service.NewPolicyService(enforcer, auditService, logger))
+	// Administrasi user; enforcer dipakai untuk mengenali role yang mewarisi super_admin
+	adminUserService := service.NewAdminUserService(userRepo, roleRepo, rtRepo, revocationService, enforcer, logger)
+	adminUserHandler := handler.NewAdminUserHandler(userService, adminUserService)
+	// Impersonasi; enforcer dipakai untuk menolak target yang mewarisi role admin
+	impersonationService := service.NewImpersonationService(userRepo, jwtService, revocationService, auditService, enforcer, cfg, logger)
+	impersonationHandler := handler.NewImpersonationHandler(impersonationService, auditService)
+	// Role kustom (izinnya diatur lewat policy)
+	roleHandler := handler.NewRoleHandler(service.NewRoleService(roleRepo, auditService, logger))
 	
-	// Inisialisasi JWT middleware
-    jwtMiddleware := middleware.NewJWTMiddleware(cfg.JWTSecret)
+	// Inisialisasi Authenticator (cookie access_token atau header Bearer)
+    authenticator := middleware.NewAuthenticator(jwtService, roleRepo, revocationService, apiKeyService, csrfService, auditService)
 	
 	// Inisialisasi repository dan service
     productRepo 	:= gorm.NewProductRepository(db)
 	orderRepo 		:= gorm.NewOrderRepository(db)
     orderItemRepo 	:= gorm.NewOrderItemRepository(db)
     productService 	:= service.NewProductService(productRepo, userRepo)
-	orderService 	:= service.NewOrderService(orderRepo, orderItemRepo, productRepo, userRepo)
+	// Pemesanan butuh email terverifikasi jika EMAIL_VERIFICATION_MODE = login/order
+	requireVerifiedEmail := cfg.EmailVerificationMode != config.EmailVerificationOff
+	orderService 	:= service.NewOrderService(orderRepo, orderItemRepo, productRepo, userRepo, requireVerifiedEmail)
     productHandler 	:= handler.NewProductHandler(productService)
 	orderHandler 	:= handler.NewOrderHandler(orderService)
+	// Pemuat pemilik resource untuk syarat kepemilikan di policy Casbin (AuthorizeResource)
+	owners := authorization.Owners{
+		"product": productService.ProductOwner,
+		"order":   orderService.OrderOwner,
+	}
+
+	// Ekspor data pribadi & penghapusan akun (GDPR)
+	privacyService := service.NewPrivacyService(userRepo, orderRepo, rtRepo, identityRepo, apiKeyRepo, webauthnCredentialRepo, revocationService, passwordHasher, mailer, cfg, logger)
+	privacyHandler := handler.NewPrivacyHandler(privacyService)
+
+	// Job pemeliharaan terjadwal (pembersihan refresh_tokens, anonimisasi akun, dst.)
+	if cfg.JobsEnabled {
+		var jobLockRepo repository.JobLockRepository
+		if cfg.JobLockStore == "memory" {
+			jobLockRepo = memory.NewJobLockRepository()
+		} else {
+			jobLockRepo = gorm.NewJobLockRepository(db)
+		}
+		jobRunner := jobs.NewRunner(jobLockRepo, cfg.JobsInterval, logger)
+		jobRunner.Register(
+			jobs.NewRefreshTokenCleanup(rtRepo, cfg.RefreshTokenRetention, cfg.JobsBatchSize, logger),
+			jobs.NewAccountDeletion(privacyService, cfg.JobsBatchSize, logger),
+		)
+		go jobRunner.Run(context.Background())
+	}
 	
-	// Router dengan authHandler (dari langkah 3), productHandler, jwtMiddleware, enforcer
-    router := routes.NewRouter(authHandler, productHandler, orderHandler, jwtMiddleware, enforcer)
+	// Router dengan authHandler (dari langkah 3), productHandler, authenticator, enforcer, owners
+    router := routes.NewRouter(authHandler, sessionHandler, verificationHandler, passwordResetHandler, magicLinkHandler, webauthnHandler, phoneHandler, profileHandler, privacyHandler, jwksHandler, mfaHandler, adminUserHandler, impersonationHandler, oauthHandler, apiKeyHandler, policyHandler, roleHandler, productHandler, orderHandler, authenticator, enforcer, owners)
 
 	// Jalankan server HTTP
 	logger.Info("✅server dijalankan", zap.String("port", cfg.AppPort))
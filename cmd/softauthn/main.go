@@ -0,0 +1,138 @@
+// Command softauthn adalah authenticator WebAuthn software untuk menguji passkey secara lokal tanpa browser.
+//
+// Pemakaian (API berjalan, user sudah terdaftar dan tanpa 2FA):
+//
+//	SOFTAUTHN_EMAIL=buyer@example.com SOFTAUTHN_PASSWORD=rahasia123 go run ./cmd/softauthn
+//
+// Alur yang dijalankan:
+//  1. login dengan password, lalu daftarkan passkey baru dari internal/webauthntest
+//     (attestation "none", kunci ECDSA P-256 di memori)
+//  2. dengan cookie jar kosong, login memakai passkey tersebut (discoverable credential) dan panggil GET /auth/me
+//  3. hapus passkey lagi kecuali SOFTAUTHN_KEEP=true
+//
+// Variabel lain: SOFTAUTHN_API (default http://localhost:8080) dan SOFTAUTHN_ORIGIN
+// (default http://localhost:3000, harus termasuk WEBAUTHN_RP_ORIGINS). Tidak untuk produksi.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/webauthntest"
+)
+
+func main() {
+	api := env("SOFTAUTHN_API", "http://localhost:8080")
+	email, password := os.Getenv("SOFTAUTHN_EMAIL"), os.Getenv("SOFTAUTHN_PASSWORD")
+	if email == "" || password == "" {
+		log.Fatal("SOFTAUTHN_EMAIL dan SOFTAUTHN_PASSWORD wajib diisi")
+	}
+	auth, err := webauthntest.New(env("SOFTAUTHN_ORIGIN", "http://localhost:3000"))
+	if err != nil {
+		log.Fatalf("gagal membuat kunci: %v", err)
+	}
+
+	// 1) Login password lalu daftarkan passkey
+	c := newClient(api)
+	var login map[string]any
+	c.mustCall(http.MethodPost, "/auth/login", map[string]string{"email": email, "password": password}, http.StatusOK, &login)
+	if login["mfa_required"] == true || login["mfa_enrollment_required"] == true {
+		log.Fatal("akun membutuhkan 2FA; pakai akun tanpa 2FA untuk uji ini")
+	}
+	var creation json.RawMessage
+	c.mustCall(http.MethodPost, "/auth/webauthn/register/begin", nil, http.StatusOK, &creation)
+	credential, err := auth.Create(creation)
+	if err != nil {
+		log.Fatalf("gagal membuat attestation: %v", err)
+	}
+	var registered struct {
+		ID string `json:"id"`
+	}
+	c.mustCall(http.MethodPost, "/auth/webauthn/register/finish",
+		map[string]any{"name": "softauthn", "credential": credential}, http.StatusCreated, &registered)
+	log.Printf("passkey terdaftar: %s", registered.ID)
+
+	// 2) Login dengan passkey di "browser" baru
+	c = newClient(api)
+	var request json.RawMessage
+	c.mustCall(http.MethodPost, "/auth/webauthn/login/begin", nil, http.StatusOK, &request)
+	assertion, err := auth.Get(request)
+	if err != nil {
+		log.Fatalf("gagal membuat assertion: %v", err)
+	}
+	var result map[string]any
+	c.mustCall(http.MethodPost, "/auth/webauthn/login/finish", assertion, http.StatusOK, &result)
+	var me map[string]any
+	c.mustCall(http.MethodGet, "/auth/me", nil, http.StatusOK, &me)
+	log.Printf("login passkey berhasil: %v", me)
+
+	// 3) Bersihkan agar uji berulang tidak menabrak batas jumlah passkey
+	if os.Getenv("SOFTAUTHN_KEEP") != "true" {
+		c.mustCall(http.MethodDelete, "/auth/webauthn/credentials/"+registered.ID, nil, http.StatusNoContent, nil)
+		log.Printf("passkey dihapus")
+	}
+}
+
+// client adalah HTTP client dengan cookie jar sendiri (satu "browser") yang mengirim ulang token CSRF.
+type client struct {
+	api  *url.URL
+	http *http.Client
+}
+
+func newClient(api string) *client {
+	u, err := url.Parse(api)
+	if err != nil {
+		log.Fatalf("SOFTAUTHN_API tidak valid: %v", err)
+	}
+	jar, _ := cookiejar.New(nil)
+	return &client{api: u, http: &http.Client{Jar: jar}}
+}
+
+// mustCall mengirim request JSON dan menghentikan program jika status tidak sesuai harapan.
+func (c *client) mustCall(method, path string, body any, wantStatus int, out any) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			log.Fatalf("%s %s: %v", method, path, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.api.String()+path, reader)
+	if err != nil {
+		log.Fatalf("%s %s: %v", method, path, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for _, cookie := range c.http.Jar.Cookies(c.api) {
+		if cookie.Name == "csrf_token" {
+			req.Header.Set("X-CSRF-Token", cookie.Value)
+		}
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		log.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+	data, _ := io.ReadAll(res.Body)
+	if res.StatusCode != wantStatus {
+		log.Fatalf("%s %s: status %d, body: %s", method, path, res.StatusCode, data)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			log.Fatalf("%s %s: respons bukan JSON: %v", method, path, err)
+		}
+	}
+}
+
+func env(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,6 @@
+DROP INDEX idx_rt_family ON refresh_tokens;
+
+ALTER TABLE refresh_tokens
+  DROP COLUMN revoked_at,
+  DROP COLUMN parent_id,
+  DROP COLUMN family_id;
@@ -0,0 +1,12 @@
+-- family_id: rantai rotasi RT sejak login; parent_id: RT sebelumnya dalam rantai
+ALTER TABLE refresh_tokens
+  ADD COLUMN family_id  CHAR(36)  NULL AFTER user_id,
+  ADD COLUMN parent_id  CHAR(36)  NULL AFTER family_id,
+  ADD COLUMN revoked_at DATETIME  NULL AFTER revoked;
+
+-- RT lama menjadi akar family masing-masing
+UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL;
+
+ALTER TABLE refresh_tokens MODIFY family_id CHAR(36) NOT NULL;
+
+CREATE INDEX idx_rt_family ON refresh_tokens(family_id);
@@ -0,0 +1,5 @@
+-- Info perangkat untuk daftar sesi aktif (satu sesi = satu family RT)
+ALTER TABLE refresh_tokens
+  ADD COLUMN user_agent   VARCHAR(255) NULL AFTER revoked_at,
+  ADD COLUMN ip_address   VARCHAR(45)  NULL AFTER user_agent,
+  ADD COLUMN last_used_at DATETIME     NULL AFTER ip_address;
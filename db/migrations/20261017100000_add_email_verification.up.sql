@@ -0,0 +1,19 @@
+-- email_verified_at: NULL berarti email belum diverifikasi
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME NULL AFTER role_id;
+
+-- User yang sudah ada dianggap terverifikasi agar tidak terkunci saat verifikasi diwajibkan
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+
+-- user_tokens: token sekali pakai (hash sha256) yang dikirim ke user, mis. link verifikasi email
+CREATE TABLE IF NOT EXISTS user_tokens (
+  id          CHAR(36)    NOT NULL PRIMARY KEY,
+  user_id     CHAR(36)    NOT NULL,
+  purpose     VARCHAR(32) NOT NULL,
+  token_hash  CHAR(64)    NOT NULL UNIQUE,
+  expires_at  DATETIME    NOT NULL,
+  used_at     DATETIME    NULL,
+  created_at  DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_user_tokens_user FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE INDEX idx_user_tokens_user_purpose ON user_tokens(user_id, purpose, created_at);
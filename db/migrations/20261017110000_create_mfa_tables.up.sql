@@ -0,0 +1,22 @@
+-- user_mfa: konfigurasi TOTP per user (secret terenkripsi AES-GCM)
+CREATE TABLE IF NOT EXISTS user_mfa (
+  user_id           CHAR(36)     NOT NULL PRIMARY KEY,
+  secret_encrypted  VARCHAR(255) NOT NULL,
+  enabled_at        DATETIME     NULL,                 -- NULL = enrollment belum dikonfirmasi
+  last_used_step    BIGINT       NOT NULL DEFAULT 0,   -- cegah pemakaian ulang kode TOTP
+  created_at        DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at        DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  CONSTRAINT fk_user_mfa_user FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- mfa_recovery_codes: kode cadangan sekali pakai (hash sha256)
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+  id          CHAR(36)  NOT NULL PRIMARY KEY,
+  user_id     CHAR(36)  NOT NULL,
+  code_hash   CHAR(64)  NOT NULL,
+  used_at     DATETIME  NULL,
+  created_at  DATETIME  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_mfa_rc_user FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE INDEX idx_mfa_rc_user ON mfa_recovery_codes(user_id, code_hash);
@@ -0,0 +1,10 @@
+-- login_attempts: counter gagal login beruntun per akun ("acct:<email>") dan per IP ("ip:<alamat>")
+CREATE TABLE IF NOT EXISTS login_attempts (
+  `key`           VARCHAR(191) NOT NULL PRIMARY KEY,
+  failures        INT          NOT NULL DEFAULT 0,
+  last_failed_at  DATETIME     NOT NULL,
+  locked_until    DATETIME     NULL,
+  updated_at      DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE INDEX idx_login_attempts_last_failed ON login_attempts(last_failed_at);
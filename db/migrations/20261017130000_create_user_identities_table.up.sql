@@ -0,0 +1,14 @@
+-- user_identities: tautan akun login sosial (OIDC) ke users
+CREATE TABLE IF NOT EXISTS user_identities (
+  id             CHAR(36)     NOT NULL PRIMARY KEY,
+  user_id        CHAR(36)     NOT NULL,
+  provider       VARCHAR(50)  NOT NULL,
+  subject        VARCHAR(255) NOT NULL,             -- klaim sub dari provider
+  email          VARCHAR(255) NULL,
+  last_login_at  DATETIME     NULL,
+  created_at     DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_identity_provider_subject UNIQUE (provider, subject),
+  CONSTRAINT fk_identity_user FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE INDEX idx_identity_user ON user_identities(user_id);
@@ -0,0 +1,19 @@
+-- revoked_access_tokens: denylist AT per jti (logout, pencabutan sesi)
+CREATE TABLE IF NOT EXISTS revoked_access_tokens (
+  jti         VARCHAR(64) NOT NULL PRIMARY KEY,
+  user_id     CHAR(36)    NOT NULL,
+  expires_at  DATETIME    NOT NULL,             -- exp AT; entri boleh dihapus setelahnya
+  created_at  DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE INDEX idx_revoked_at_expires ON revoked_access_tokens(expires_at);
+
+-- access_token_cutoffs: semua AT user yang diterbitkan sebelum revoked_before dianggap dicabut
+CREATE TABLE IF NOT EXISTS access_token_cutoffs (
+  user_id         CHAR(36) NOT NULL PRIMARY KEY,
+  revoked_before  DATETIME NOT NULL,
+  expires_at      DATETIME NOT NULL,              -- revoked_before + umur AT
+  updated_at      DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE INDEX idx_at_cutoffs_expires ON access_token_cutoffs(expires_at);
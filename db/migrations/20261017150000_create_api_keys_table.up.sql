@@ -0,0 +1,17 @@
+-- api_keys: kredensial machine-to-machine (format ek_<prefix>_<secret>, hanya hash yang disimpan)
+CREATE TABLE IF NOT EXISTS api_keys (
+  id            CHAR(36)     NOT NULL PRIMARY KEY,
+  user_id       CHAR(36)     NOT NULL,
+  name          VARCHAR(100) NOT NULL,
+  prefix        VARCHAR(16)  NOT NULL,
+  key_hash      CHAR(64)     NOT NULL,               -- sha256 hex dari key utuh
+  scopes        VARCHAR(512) NOT NULL,               -- dipisah koma, mis. product:create,order:read
+  expires_at    DATETIME     NULL,
+  last_used_at  DATETIME     NULL,
+  revoked_at    DATETIME     NULL,
+  created_at    DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_api_keys_prefix UNIQUE (prefix),
+  CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE INDEX idx_api_keys_user ON api_keys(user_id, revoked_at);
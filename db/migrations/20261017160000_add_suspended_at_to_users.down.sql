@@ -0,0 +1,3 @@
+DROP INDEX idx_users_suspended_at ON users;
+
+ALTER TABLE users DROP COLUMN suspended_at;
@@ -0,0 +1,4 @@
+-- suspended_at: diisi admin saat akun ditangguhkan (login, refresh, dan API key ditolak)
+ALTER TABLE users ADD COLUMN suspended_at DATETIME NULL AFTER email_verified_at;
+
+CREATE INDEX idx_users_suspended_at ON users(suspended_at);
@@ -0,0 +1,3 @@
+DROP INDEX idx_rt_revoked_at ON refresh_tokens;
+
+DROP TABLE IF EXISTS job_locks;
@@ -0,0 +1,10 @@
+-- job_locks: lease job pemeliharaan agar hanya satu replika yang menjalankan job per interval
+CREATE TABLE IF NOT EXISTS job_locks (
+  name          VARCHAR(100) NOT NULL PRIMARY KEY,
+  owner         VARCHAR(191) NOT NULL,
+  locked_until  DATETIME     NOT NULL,
+  updated_at    DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Dipakai job refresh_token_cleanup untuk mencari RT yang dicabut (idx_rt_expires untuk yang kedaluwarsa)
+CREATE INDEX idx_rt_revoked_at ON refresh_tokens(revoked, revoked_at);
@@ -0,0 +1,2 @@
+-- pending_email: email baru yang menunggu konfirmasi lewat link (token user_tokens purpose=email_change)
+ALTER TABLE users ADD COLUMN pending_email VARCHAR(255) NULL AFTER email;
@@ -0,0 +1,5 @@
+DROP INDEX idx_users_deletion_scheduled ON users;
+
+ALTER TABLE users
+  DROP COLUMN anonymized_at,
+  DROP COLUMN deletion_scheduled_at;
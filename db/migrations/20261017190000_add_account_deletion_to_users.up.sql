@@ -0,0 +1,7 @@
+-- deletion_scheduled_at: jadwal anonimisasi akun atas permintaan user (masa tenggang, bisa dibatalkan)
+-- anonymized_at: waktu PII dihapus; pesanan tetap menunjuk ke baris user yang sudah dianonimkan
+ALTER TABLE users
+  ADD COLUMN deletion_scheduled_at DATETIME NULL AFTER suspended_at,
+  ADD COLUMN anonymized_at         DATETIME NULL AFTER deletion_scheduled_at;
+
+CREATE INDEX idx_users_deletion_scheduled ON users(deletion_scheduled_at, anonymized_at);
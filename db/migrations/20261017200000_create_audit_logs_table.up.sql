@@ -0,0 +1,18 @@
+-- audit_logs: jejak tindakan sensitif (impersonasi admin & setiap mutasi selama impersonasi).
+-- Sengaja tanpa foreign key agar log tetap utuh apa pun yang terjadi pada baris user.
+CREATE TABLE IF NOT EXISTS audit_logs (
+  id          CHAR(36)     NOT NULL PRIMARY KEY,
+  actor_id    CHAR(36)     NOT NULL,              -- user yang benar-benar melakukan aksi (admin)
+  user_id     CHAR(36)     NULL,                  -- user yang terdampak / di-impersonate
+  action      VARCHAR(64)  NOT NULL,              -- mis. impersonation.start, impersonation.request
+  method      VARCHAR(10)  NULL,
+  path        VARCHAR(512) NULL,
+  status      INT          NULL,
+  ip_address  VARCHAR(45)  NULL,
+  user_agent  VARCHAR(255) NULL,
+  metadata    TEXT         NULL,                  -- JSON tambahan, mis. alasan impersonasi
+  created_at  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE INDEX idx_audit_logs_actor ON audit_logs(actor_id, created_at);
+CREATE INDEX idx_audit_logs_user ON audit_logs(user_id, created_at);
@@ -0,0 +1,20 @@
+-- webauthn_credentials: passkey/security key milik user (kunci publik saja; kunci privat tidak pernah meninggalkan authenticator)
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+  id                CHAR(36)        NOT NULL PRIMARY KEY,
+  user_id           CHAR(36)        NOT NULL,
+  credential_id     VARBINARY(255)  NOT NULL,            -- rawId dari authenticator
+  public_key        BLOB            NOT NULL,            -- kunci publik COSE
+  attestation_type  VARCHAR(32)     NOT NULL,
+  transports        VARCHAR(255)    NOT NULL DEFAULT '', -- dipisah koma, mis. internal,hybrid
+  aaguid            VARBINARY(16)   NULL,                -- model authenticator
+  flags             TINYINT UNSIGNED NOT NULL DEFAULT 0, -- flag authenticator data terakhir (UV, BE, BS, ...)
+  sign_count        INT UNSIGNED    NOT NULL DEFAULT 0,
+  clone_warning     BOOLEAN         NOT NULL DEFAULT FALSE, -- sign count mundur: kemungkinan authenticator digandakan
+  name              VARCHAR(100)    NOT NULL,
+  last_used_at      DATETIME        NULL,
+  created_at        DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_webauthn_credentials_credential UNIQUE (credential_id),
+  CONSTRAINT fk_webauthn_credentials_user FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE INDEX idx_webauthn_credentials_user ON webauthn_credentials(user_id);
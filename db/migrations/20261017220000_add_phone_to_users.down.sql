@@ -0,0 +1,6 @@
+DROP INDEX uq_users_phone ON users;
+
+ALTER TABLE users
+  DROP COLUMN phone_mfa_enabled_at,
+  DROP COLUMN phone_verified_at,
+  DROP COLUMN phone;
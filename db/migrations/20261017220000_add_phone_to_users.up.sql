@@ -0,0 +1,8 @@
+-- phone: nomor HP terverifikasi (E.164, mis. +6281234567890); hanya diisi setelah kode OTP dikonfirmasi
+-- phone_mfa_enabled_at: 2FA via SMS aktif untuk akun ini
+ALTER TABLE users
+  ADD COLUMN phone                VARCHAR(20) NULL AFTER pending_email,
+  ADD COLUMN phone_verified_at    DATETIME    NULL AFTER phone,
+  ADD COLUMN phone_mfa_enabled_at DATETIME    NULL AFTER phone_verified_at;
+
+CREATE UNIQUE INDEX uq_users_phone ON users(phone);
@@ -0,0 +1,16 @@
+-- phone_otps: kode OTP SMS 6 digit (hanya hash yang disimpan) dengan batas percobaan
+-- purpose: verify_phone (konfirmasi nomor baru), login (login dengan nomor HP), mfa (2FA via SMS)
+CREATE TABLE IF NOT EXISTS phone_otps (
+  id          CHAR(36)    NOT NULL PRIMARY KEY,
+  user_id     CHAR(36)    NOT NULL,
+  phone       VARCHAR(20) NOT NULL,                -- nomor tujuan SMS (untuk verify_phone: nomor yang akan disimpan)
+  purpose     VARCHAR(32) NOT NULL,
+  code_hash   CHAR(64)    NOT NULL,                -- sha256 hex dari kode
+  attempts    INT         NOT NULL DEFAULT 0,      -- jumlah tebakan salah
+  expires_at  DATETIME    NOT NULL,
+  used_at     DATETIME    NULL,
+  created_at  DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_phone_otps_user FOREIGN KEY (user_id) REFERENCES users(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE INDEX idx_phone_otps_user_purpose ON phone_otps(user_id, purpose, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS casbin_policy_version;
+DROP TABLE IF EXISTS casbin_rule;
@@ -0,0 +1,23 @@
+-- casbin_rule: policy Casbin (p = sub, obj, act; g = user/role, role) menggantikan config/rbac_policy.csv.
+-- Saat tabel kosong, aplikasi mengisinya sekali dari config/rbac_policy.csv.
+CREATE TABLE IF NOT EXISTS casbin_rule (
+  id     BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  ptype  VARCHAR(100)    NOT NULL,
+  v0     VARCHAR(100)    NOT NULL DEFAULT '',
+  v1     VARCHAR(100)    NOT NULL DEFAULT '',
+  v2     VARCHAR(100)    NOT NULL DEFAULT '',
+  v3     VARCHAR(100)    NOT NULL DEFAULT '',
+  v4     VARCHAR(100)    NOT NULL DEFAULT '',
+  v5     VARCHAR(100)    NOT NULL DEFAULT '',
+  UNIQUE KEY uq_casbin_rule (ptype, v0, v1, v2, v3, v4, v5)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- casbin_policy_version: satu baris yang dinaikkan setiap kali casbin_rule berubah;
+-- setiap instance memeriksanya berkala dan me-reload enforcer jika versinya berbeda.
+CREATE TABLE IF NOT EXISTS casbin_policy_version (
+  id          TINYINT UNSIGNED NOT NULL PRIMARY KEY,
+  version     BIGINT UNSIGNED  NOT NULL DEFAULT 0,
+  updated_at  DATETIME         NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT IGNORE INTO casbin_policy_version (id, version) VALUES (1, 0);
@@ -0,0 +1,3 @@
+UPDATE casbin_rule SET v3 = '' WHERE ptype = 'p' AND v3 IN ('true', 'r.res.Owner == r.sub.ID');
+
+UPDATE casbin_policy_version SET version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = 1;
@@ -0,0 +1,12 @@
+-- Model Casbin kini punya kolom kondisi (p = sub, obj, act, cond) di v3.
+-- Rule lama tanpa kondisi menjadi "true"; rule seller/buyer yang sebelumnya dicek manual di service
+-- kini dibatasi ke resource milik user sendiri.
+UPDATE casbin_rule SET v3 = 'true' WHERE ptype = 'p' AND v3 = '';
+
+UPDATE casbin_rule SET v3 = 'r.res.Owner == r.sub.ID'
+WHERE ptype = 'p' AND v0 = 'seller' AND v1 = 'product' AND v2 IN ('update', 'delete');
+
+UPDATE casbin_rule SET v3 = 'r.res.Owner == r.sub.ID'
+WHERE ptype = 'p' AND v0 = 'buyer' AND v1 = 'order' AND v2 = 'read';
+
+UPDATE casbin_policy_version SET version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = 1;
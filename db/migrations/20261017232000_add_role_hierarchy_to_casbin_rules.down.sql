@@ -0,0 +1,17 @@
+-- Seperti migrasi up, rule hanya dikembalikan jika policy sudah ada di database.
+INSERT IGNORE INTO casbin_rule (ptype, v0, v1, v2, v3)
+SELECT r.ptype, r.v0, r.v1, r.v2, r.v3 FROM (
+    SELECT 'p' AS ptype, 'admin' AS v0, 'user' AS v1, 'impersonate' AS v2, 'true' AS v3
+    UNION ALL SELECT 'p', 'admin', 'policy', 'manage', 'true'
+) AS r
+WHERE EXISTS (SELECT 1 FROM casbin_rule);
+
+DELETE FROM casbin_rule
+WHERE ptype = 'p' AND v0 = 'super_admin' AND ((v1 = 'user' AND v2 = 'impersonate') OR (v1 = 'role' AND v2 = 'manage') OR (v1 = 'policy' AND v2 = 'manage'));
+
+DELETE FROM casbin_rule WHERE ptype = 'p' AND v0 = 'admin' AND v1 = 'role' AND v2 = 'read';
+
+DELETE FROM casbin_rule
+WHERE ptype = 'g' AND ((v0 = 'super_admin' AND v1 = 'admin') OR (v0 = 'admin' AND v1 IN ('seller', 'buyer')));
+
+UPDATE casbin_policy_version SET version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = 1;
@@ -0,0 +1,27 @@
+-- Hierarki role: super_admin > admin > seller/buyer.
+-- Impersonasi, pengelolaan role, dan pengelolaan policy kini khusus super_admin.
+-- Pastikan minimal satu user sudah ber-role super_admin sebelum migrasi ini dijalankan,
+-- karena admin tidak lagi bisa mengubah policy lewat API.
+-- Hanya berlaku untuk policy yang sudah ada di database: pada instalasi baru casbin_rule masih kosong
+-- dan diisi aplikasi dari config/rbac_policy.csv (SeedIfEmpty), yang sudah memuat aturan ini.
+INSERT IGNORE INTO casbin_rule (ptype, v0, v1)
+SELECT r.ptype, r.v0, r.v1 FROM (
+    SELECT 'g' AS ptype, 'super_admin' AS v0, 'admin' AS v1
+    UNION ALL SELECT 'g', 'admin', 'seller'
+    UNION ALL SELECT 'g', 'admin', 'buyer'
+) AS r
+WHERE EXISTS (SELECT 1 FROM casbin_rule);
+
+INSERT IGNORE INTO casbin_rule (ptype, v0, v1, v2, v3)
+SELECT r.ptype, r.v0, r.v1, r.v2, r.v3 FROM (
+    SELECT 'p' AS ptype, 'admin' AS v0, 'role' AS v1, 'read' AS v2, 'true' AS v3
+    UNION ALL SELECT 'p', 'super_admin', 'user', 'impersonate', 'true'
+    UNION ALL SELECT 'p', 'super_admin', 'role', 'manage', 'true'
+    UNION ALL SELECT 'p', 'super_admin', 'policy', 'manage', 'true'
+) AS r
+WHERE EXISTS (SELECT 1 FROM casbin_rule);
+
+DELETE FROM casbin_rule
+WHERE ptype = 'p' AND v0 = 'admin' AND ((v1 = 'user' AND v2 = 'impersonate') OR (v1 = 'policy' AND v2 = 'manage'));
+
+UPDATE casbin_policy_version SET version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = 1;
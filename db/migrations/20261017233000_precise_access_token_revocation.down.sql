@@ -0,0 +1,3 @@
+ALTER TABLE refresh_tokens DROP COLUMN access_token_id;
+
+ALTER TABLE access_token_cutoffs MODIFY revoked_before DATETIME NOT NULL;
@@ -0,0 +1,6 @@
+-- Cutoff pencabutan AT per user disimpan dengan presisi mikrodetik (sama dengan klaim iat_us AT), sehingga AT
+-- yang diterbitkan di detik yang sama tetapi sebelum pencabutan ikut dicabut.
+ALTER TABLE access_token_cutoffs MODIFY revoked_before DATETIME(6) NOT NULL;
+
+-- jti AT terakhir per sesi; dimasukkan ke denylist saat sesi dicabut lewat DELETE /auth/sessions/{id}
+ALTER TABLE refresh_tokens ADD COLUMN access_token_id VARCHAR(36) NOT NULL DEFAULT '' AFTER revoked_at;
@@ -0,0 +1,47 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/casbin/govaluate"
+	"github.com/google/uuid"
+)
+
+// ConditionAlways adalah kondisi rule p tanpa syarat atribut (izin RBAC biasa).
+const ConditionAlways = "true"
+
+// ConditionOwner membatasi rule p ke resource milik subject sendiri.
+const ConditionOwner = "r.res.Owner == r.sub.ID"
+
+// ErrResourceNotFound dikembalikan OwnerFunc jika resource yang diminta tidak ada.
+var ErrResourceNotFound = errors.New("resource tidak ditemukan")
+
+// Subject adalah atribut peminta yang tersedia di matcher sebagai r.sub.ID dan r.sub.Role.
+type Subject struct {
+	ID   string
+	Role string
+}
+
+// Resource adalah atribut resource yang tersedia di matcher sebagai r.res.Owner.
+// Owner kosong berarti aksi tidak menyangkut resource tertentu (mis. create atau list).
+type Resource struct {
+	Owner string
+}
+
+// OwnerFunc mengembalikan ID user pemilik resource; ErrResourceNotFound jika tidak ada.
+type OwnerFunc func(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+
+// ValidateCondition memastikan kondisi rule p bisa di-parse sebelum disimpan; kondisi rusak
+// akan membuat setiap Enforce yang mencocokkan rule tersebut gagal.
+func ValidateCondition(cond string) error {
+	if _, err := govaluate.NewEvaluableExpression(cond); err != nil {
+		return fmt.Errorf("kondisi policy tidak valid: %w", err)
+	}
+	return nil
+}
+
+// Owners memetakan nama resource di policy (mis. "product") ke OwnerFunc-nya.
+// Resource baru cukup didaftarkan di sini untuk bisa memakai kondisi kepemilikan.
+type Owners map[string]OwnerFunc
@@ -0,0 +1,89 @@
+package authorization
+
+import (
+	"context"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+)
+
+// Adapter menyimpan policy Casbin di tabel casbin_rule lewat PolicyRepository.
+// Casbin tidak meneruskan context ke adapter sehingga operasi memakai context.Background().
+type Adapter struct {
+	repo repository.PolicyRepository
+}
+
+var (
+	_ persist.Adapter      = (*Adapter)(nil)
+	_ persist.BatchAdapter = (*Adapter)(nil)
+)
+
+// NewAdapter membuat adapter database untuk enforcer.
+func NewAdapter(repo repository.PolicyRepository) *Adapter {
+	return &Adapter{repo: repo}
+}
+
+// LoadPolicy memuat semua rule dari database ke model.
+func (a *Adapter) LoadPolicy(m model.Model) error {
+	rules, err := a.repo.List(context.Background())
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if err := persist.LoadPolicyArray(append([]string{rule.Ptype}, rule.Values()...), m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SavePolicy mengganti isi database dengan seluruh rule di model.
+func (a *Adapter) SavePolicy(m model.Model) error {
+	return a.repo.ReplaceAll(context.Background(), modelRules(m))
+}
+
+// AddPolicy menyimpan satu rule.
+func (a *Adapter) AddPolicy(_ string, ptype string, rule []string) error {
+	return a.repo.Add(context.Background(), []domain.CasbinRule{domain.NewCasbinRule(ptype, rule)})
+}
+
+// AddPolicies menyimpan beberapa rule dalam satu transaksi.
+func (a *Adapter) AddPolicies(_ string, ptype string, rules [][]string) error {
+	return a.repo.Add(context.Background(), toCasbinRules(ptype, rules))
+}
+
+// RemovePolicy menghapus satu rule.
+func (a *Adapter) RemovePolicy(_ string, ptype string, rule []string) error {
+	return a.repo.Remove(context.Background(), []domain.CasbinRule{domain.NewCasbinRule(ptype, rule)})
+}
+
+// RemovePolicies menghapus beberapa rule dalam satu transaksi.
+func (a *Adapter) RemovePolicies(_ string, ptype string, rules [][]string) error {
+	return a.repo.Remove(context.Background(), toCasbinRules(ptype, rules))
+}
+
+// RemoveFilteredPolicy menghapus rule yang cocok dengan filter kolom.
+func (a *Adapter) RemoveFilteredPolicy(_ string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return a.repo.RemoveFiltered(context.Background(), ptype, fieldIndex, fieldValues)
+}
+
+// modelRules mengubah seluruh rule p dan g di model menjadi baris casbin_rule.
+func modelRules(m model.Model) []domain.CasbinRule {
+	var rules []domain.CasbinRule
+	for _, sec := range []string{"p", "g"} {
+		for ptype, ast := range m[sec] {
+			rules = append(rules, toCasbinRules(ptype, ast.Policy)...)
+		}
+	}
+	return rules
+}
+
+func toCasbinRules(ptype string, rules [][]string) []domain.CasbinRule {
+	out := make([]domain.CasbinRule, 0, len(rules))
+	for _, rule := range rules {
+		out = append(out, domain.NewCasbinRule(ptype, rule))
+	}
+	return out
+}
@@ -1,15 +1,24 @@
 package authorization
 
 import (
+	"context"
+	"fmt"
+
 	casbin "github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
 	fileadapter "github.com/casbin/casbin/v2/persist/file-adapter"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
 )
 
-// NewEnforcer membuat model dan policy dari file.
-// Parameter modelPath dan policyPath adalah lokasi file .conf dan .csv.
-func NewEnforcer(modelPath, policyPath string) (*casbin.Enforcer, error) {
-	adapter := fileadapter.NewAdapter(policyPath)
-	enforcer, err := casbin.NewEnforcer(modelPath, adapter) 
+// NewEnforcer membuat SyncedEnforcer (aman dipakai bersamaan dengan reload) dengan policy dari database.
+// Parameter modelPath adalah lokasi file .conf; seedPolicyPath adalah file .csv yang dipakai
+// sekali untuk mengisi tabel casbin_rule saat masih kosong (deploy pertama).
+func NewEnforcer(modelPath, seedPolicyPath string, repo repository.PolicyRepository, logger *zap.Logger) (*casbin.SyncedEnforcer, error) {
+	if err := seedPolicy(modelPath, seedPolicyPath, repo, logger); err != nil {
+		return nil, err
+	}
+	enforcer, err := casbin.NewSyncedEnforcer(modelPath, NewAdapter(repo))
 	if err != nil {
 		return nil, err
 	}
@@ -19,6 +28,26 @@ func NewEnforcer(modelPath, policyPath string) (*casbin.Enforcer, error) {
 	return enforcer, nil
 }
 
-// - Agar Casbin dapat berjalan, Anda membutuhkan dua file konfigurasi di proyek Anda:
-// - rbac_model.conf – mendefinisikan struktur model otorisasi (bagaimana permintaan, policy, dan role dicocokkan).
-// - rbac_policy.csv – berisi daftar policy (aturan) yang menetapkan role mana yang boleh melakukan aksi apa terhadap resource tertentu.
\ No newline at end of file
+// seedPolicy menyalin rule dari file CSV ke database jika tabel casbin_rule masih kosong.
+func seedPolicy(modelPath, seedPolicyPath string, repo repository.PolicyRepository, logger *zap.Logger) error {
+	m, err := model.NewModelFromFile(modelPath)
+	if err != nil {
+		return err
+	}
+	if err := fileadapter.NewAdapter(seedPolicyPath).LoadPolicy(m); err != nil {
+		return fmt.Errorf("gagal membaca seed policy %s: %w", seedPolicyPath, err)
+	}
+	rules := modelRules(m)
+	seeded, err := repo.SeedIfEmpty(context.Background(), rules)
+	if err != nil {
+		return fmt.Errorf("gagal mengisi casbin_rule: %w", err)
+	}
+	if seeded {
+		logger.Info("policy Casbin diisi dari file", zap.String("path", seedPolicyPath), zap.Int("rules", len(rules)))
+	}
+	return nil
+}
+
+// - Model tetap dibaca dari rbac_model.conf – mendefinisikan struktur model otorisasi (bagaimana permintaan, policy, dan role dicocokkan).
+// - Policy (aturan role mana yang boleh melakukan aksi apa terhadap resource tertentu) disimpan di tabel casbin_rule
+//   dan dikelola lewat /admin/policies; rbac_policy.csv hanya menjadi isi awal.
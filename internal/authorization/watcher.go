@@ -0,0 +1,61 @@
+package authorization
+
+import (
+	"context"
+	"time"
+
+	casbin "github.com/casbin/casbin/v2"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+// PolicyWatcher menjaga enforcer setiap instance tetap sinkron dengan database:
+// versi policy diperiksa berkala dan enforcer di-reload hanya jika versinya berubah.
+type PolicyWatcher struct {
+	repo     repository.PolicyRepository
+	enforcer *casbin.SyncedEnforcer
+	logger   *zap.Logger
+	version  int64
+}
+
+// NewPolicyWatcher membuat watcher. Versi awal sengaja tidak diketahui (-1) sehingga pemeriksaan
+// pertama selalu me-reload; perubahan di antara NewEnforcer dan start watcher tidak terlewat.
+func NewPolicyWatcher(repo repository.PolicyRepository, enforcer *casbin.SyncedEnforcer, logger *zap.Logger) *PolicyWatcher {
+	return &PolicyWatcher{repo: repo, enforcer: enforcer, logger: logger, version: -1}
+}
+
+// Run memeriksa versi policy setiap interval sampai ctx selesai.
+func (w *PolicyWatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.sync(ctx); err != nil {
+				w.logger.Error("gagal menyinkronkan policy Casbin", zap.Error(err))
+			}
+		}
+	}
+}
+
+// sync me-reload enforcer jika versi di database berbeda dari versi terakhir yang dimuat.
+// Perubahan dari instance ini sendiri juga memicu reload; hasilnya sama sehingga aman.
+func (w *PolicyWatcher) sync(ctx context.Context) error {
+	version, err := w.repo.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if version == w.version {
+		return nil
+	}
+	if err := w.enforcer.LoadPolicy(); err != nil {
+		return err
+	}
+	if w.version >= 0 {
+		w.logger.Info("policy Casbin di-reload", zap.Int64("from_version", w.version), zap.Int64("to_version", version))
+	}
+	w.version = version
+	return nil
+}
@@ -2,11 +2,19 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+// Mode penegakan verifikasi email (EMAIL_VERIFICATION_MODE).
+const (
+	EmailVerificationOff   = "off"   // verifikasi opsional
+	EmailVerificationLogin = "login" // login (dan otomatis pemesanan) diblokir sampai email terverifikasi
+	EmailVerificationOrder = "order" // login boleh, pemesanan diblokir sampai email terverifikasi
+)
+
 // Config menampung seluruh konfigurasi alikasi yang dibaca dari file .env
 type Config struct {
 	AppPort		string // port aplikasi HTTP, contoh ":8080"
@@ -16,11 +24,98 @@ type Config struct {
 	DBPort		string // port db, contoh "3306"
 	DBName		string // nama db 
 	DBCharset	string // character set db
-	JWTSecret   string // Secret untuk menandatangani token JWT
-	JWTAccessSecret   	string 			// secret HMAC untuk AT
+	JWTSigningAlg		string 			// algoritma AT: RS256 atau EdDSA
+	JWTKeyDir			string 			// direktori kunci privat PEM (<kid>.pem)
+	JWTKeyRotation		time.Duration 	// umur kunci aktif sebelum dirotasi, mis. 720h
+	JWTKeyCheckInterval	time.Duration 	// interval pengecekan rotasi & reload kunci, mis. 1m
 	JWTRefreshSecret	string 			// secret HMAC untuk RT
+	CSRFSecret			string 			// secret HMAC untuk token CSRF (cookie csrf_token + header X-CSRF-Token)
 	AccessTTL			time.Duration 	// durasi AT, mis. 15m
 	RefreshTTL			time.Duration 	// durasi RT, mis. 168h (7d)
+	RefreshReuseGrace	time.Duration 	// toleransi refresh bersamaan sebelum dianggap replay, mis. 10s
+	ImpersonationTTL	time.Duration 	// masa berlaku AT impersonasi admin (tanpa RT), mis. 15m
+
+	FrontendURL			string 			// URL front-end untuk link di email, mis. http://localhost:3000
+	MailDriver			string 			// "log" atau "file"
+	MailFrom			string 			// alamat pengirim email
+	MailFileDir			string 			// direktori .eml untuk MAIL_DRIVER=file
+
+	EmailVerificationMode	string 			// off | login | order
+	EmailVerificationTTL	time.Duration 	// masa berlaku link verifikasi, mis. 24h
+	EmailVerificationPerHour	int 		// batas kirim ulang email verifikasi per jam per user
+
+	PasswordResetTTL		time.Duration 	// masa berlaku link reset password, mis. 30m
+	PasswordResetPerHour	int 			// batas permintaan reset password per jam per email
+	MagicLinkTTL			time.Duration 	// masa berlaku link login tanpa password, mis. 15m
+	MagicLinkPerHour		int 			// batas permintaan magic link per jam per email
+	APIBaseURL				string 			// URL publik API untuk link yang langsung menuju API (mis. callback magic link)
+
+	SMSDriver				string 			// "log" atau "file"
+	SMSFileDir				string 			// direktori .txt untuk SMS_DRIVER=file
+	PhoneDefaultCountryCode	string 			// kode negara untuk nomor lokal berawalan 0, mis. 62 (Indonesia)
+	PhoneOTPTTL				time.Duration 	// masa berlaku kode OTP SMS, mis. 5m
+	PhoneOTPMaxAttempts		int 			// batas tebakan salah per kode OTP sebelum kode hangus
+	PhoneOTPPerHour			int 			// batas pengiriman OTP per jam per user dan tujuan
+
+	PasswordHashAlgorithm	string 			// algoritma hash baru: "argon2id" atau "bcrypt"; hash lama di-upgrade saat login
+	Argon2Memory			uint32 			// memori argon2id dalam KiB, mis. 65536 (64 MiB)
+	Argon2Iterations		uint32 			// jumlah iterasi (time cost) argon2id
+	Argon2Parallelism		uint8 			// jumlah thread argon2id
+	BcryptCost				int 			// cost bcrypt (4-31)
+
+	PasswordMinLength			int 		// panjang minimal password (karakter)
+	PasswordMaxLength			int 		// panjang maksimal password (karakter); bcrypt tetap dibatasi 72 byte
+	PasswordMinCharClasses		int 		// jenis karakter minimal (0-4): huruf kecil, huruf besar, angka, simbol
+	PasswordBlockPersonalInfo	bool 		// tolak password yang memuat nama/email user
+	PasswordBreachedFile		string 		// file hash SHA-1 password bocor (format Pwned Passwords); kosong = nonaktif
+
+	MFAIssuer			string 			// nama issuer di aplikasi authenticator
+	MFAEncryptionKey	string 			// kunci untuk mengenkripsi secret TOTP di database
+	MFARequiredRoles	[]string 		// role yang wajib memakai 2FA, mis. admin,seller
+	MFATokenTTL			time.Duration 	// masa berlaku token "mfa pending" saat login dua langkah
+	MFAMaxFailures		int 			// kode 2FA salah beruntun per user sebelum verifikasi dikunci sementara
+
+	LoginAttemptStore		string 			// penyimpanan percobaan login: "database" (multi-instance) atau "memory"
+	LoginMaxFailures		int 			// gagal beruntun per akun sebelum akun dikunci sementara
+	LoginIPMaxFailures		int 			// gagal beruntun per IP sebelum IP dikunci sementara
+	LoginFailureWindow		time.Duration 	// counter gagal dimulai ulang jika tidak ada kegagalan selama window ini
+	LoginLockoutDuration	time.Duration 	// lama penguncian akun/IP, mis. 15m
+	LoginDelayBase			time.Duration 	// jeda awal setelah gagal; berlipat dua setiap kegagalan berikutnya
+
+	TokenRevocationStore	string 			// denylist AT: "database" (multi-instance) atau "memory"
+	TokenRevocationPurge	time.Duration 	// interval pembersihan entri denylist yang kedaluwarsa
+
+	CasbinPolicySeed		string 			// file CSV pengisi awal tabel casbin_rule saat masih kosong
+	CasbinPolicyPoll		time.Duration 	// interval pemeriksaan versi policy untuk reload enforcer antar instance
+
+	JobsEnabled				bool 			// jalankan job pemeliharaan di proses ini
+	JobsInterval			time.Duration 	// interval job pemeliharaan (sekaligus durasi lease antar replika)
+	JobsBatchSize			int 			// jumlah baris maksimal per DELETE agar tidak mengunci tabel lama
+	JobLockStore			string 			// lease job: "database" (multi-replika) atau "memory"
+	RefreshTokenRetention	time.Duration 	// RT kedaluwarsa/dicabut lebih lama dari ini dihapus
+	AccountDeletionGrace	time.Duration 	// masa tenggang sebelum akun yang diminta dihapus dianonimkan permanen
+
+	WebAuthnRPID			string 			// relying party ID passkey (domain tanpa skema/port), mis. localhost
+	WebAuthnRPName			string 			// nama layanan yang ditampilkan authenticator
+	WebAuthnRPOrigins		[]string 		// origin front-end yang boleh menjalankan ceremony WebAuthn
+
+	OAuthRedirectBaseURL	string 			// URL publik API untuk redirect_uri, mis. http://localhost:8080
+	OAuthProviders			[]OAuthProviderConfig // provider OIDC aktif (OAUTH_PROVIDERS)
+}
+
+// OAuthProviderConfig adalah konfigurasi satu provider OpenID Connect.
+// Dibaca dari OAUTH_<NAMA>_ISSUER, OAUTH_<NAMA>_CLIENT_ID, OAUTH_<NAMA>_CLIENT_SECRET, OAUTH_<NAMA>_SCOPES.
+type OAuthProviderConfig struct {
+	Name         string   // nama di URL, mis. "google" → /auth/oauth/google/start
+	Issuer       string   // issuer OIDC; discovery di <issuer>/.well-known/openid-configuration
+	ClientID     string
+	ClientSecret string
+	Scopes       []string // default: openid,email,profile
+}
+
+// defaultOAuthIssuers berisi issuer untuk provider terkenal sehingga OAUTH_<NAMA>_ISSUER boleh kosong.
+var defaultOAuthIssuers = map[string]string{
+	"google": "https://accounts.google.com",
 }
 
 // LoadConfig membaca konfigurasi file .env dan environment variables.
@@ -28,11 +123,69 @@ func LoadConfig() (*Config, error) {
 	viper.SetConfigFile(".env")				// tentukan file konfigurasi
 	viper.SetDefault("APP_PORT", ":8080")	// nilai default jika tidak diset
 	viper.SetDefault("DB_CHARSET", "utf8mb4")
-	viper.SetDefault("JWT_SECRET", "supersecret")
-	viper.SetDefault("JWT_ACCESS_SECRET", "super-at-secret")
+	viper.SetDefault("JWT_SIGNING_ALG", "RS256")
+	viper.SetDefault("JWT_KEY_DIR", "keys")
+	viper.SetDefault("JWT_KEY_ROTATION", "720h") // 30 hari
+	viper.SetDefault("JWT_KEY_CHECK_INTERVAL", "1m")
 	viper.SetDefault("JWT_REFRESH_SECRET", "super-rt-secret")
+	viper.SetDefault("CSRF_SECRET", "super-csrf-secret")
 	viper.SetDefault("JWT_ACCESS_TTL", "30m")
 	viper.SetDefault("JWT_REFRESH_TTL", "72h") // 7 hari
+	viper.SetDefault("JWT_REFRESH_REUSE_GRACE", "10s")
+	viper.SetDefault("IMPERSONATION_TTL", "15m")
+	viper.SetDefault("FRONTEND_URL", "http://localhost:3000")
+	viper.SetDefault("API_BASE_URL", "http://localhost:8080")
+	viper.SetDefault("MAGIC_LINK_TTL", "15m")
+	viper.SetDefault("MAGIC_LINK_PER_HOUR", 5)
+	viper.SetDefault("MAIL_DRIVER", "log")
+	viper.SetDefault("SMS_DRIVER", "log")
+	viper.SetDefault("SMS_FILE_DIR", "tmp/sms")
+	viper.SetDefault("PHONE_DEFAULT_COUNTRY_CODE", "62")
+	viper.SetDefault("PHONE_OTP_TTL", "5m")
+	viper.SetDefault("PHONE_OTP_MAX_ATTEMPTS", 5)
+	viper.SetDefault("PHONE_OTP_PER_HOUR", 5)
+	viper.SetDefault("MAIL_FROM", "no-reply@localhost")
+	viper.SetDefault("MAIL_FILE_DIR", "tmp/mail")
+	viper.SetDefault("EMAIL_VERIFICATION_MODE", EmailVerificationOff)
+	viper.SetDefault("EMAIL_VERIFICATION_TTL", "24h")
+	viper.SetDefault("EMAIL_VERIFICATION_PER_HOUR", 3)
+	viper.SetDefault("PASSWORD_RESET_TTL", "30m")
+	viper.SetDefault("PASSWORD_RESET_PER_HOUR", 3)
+	viper.SetDefault("PASSWORD_HASH_ALGORITHM", "argon2id")
+	viper.SetDefault("ARGON2_MEMORY_KIB", 65536)
+	viper.SetDefault("ARGON2_ITERATIONS", 3)
+	viper.SetDefault("ARGON2_PARALLELISM", 4)
+	viper.SetDefault("BCRYPT_COST", 10)
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 8)
+	viper.SetDefault("PASSWORD_MAX_LENGTH", 128)
+	viper.SetDefault("PASSWORD_MIN_CHAR_CLASSES", 3)
+	viper.SetDefault("PASSWORD_BLOCK_PERSONAL_INFO", true)
+	viper.SetDefault("MFA_ISSUER", "Ecommerce")
+	viper.SetDefault("MFA_ENCRYPTION_KEY", "super-mfa-secret")
+	viper.SetDefault("MFA_REQUIRED_ROLES", "")
+	viper.SetDefault("MFA_TOKEN_TTL", "5m")
+	viper.SetDefault("MFA_MAX_FAILURES", 5)
+	viper.SetDefault("LOGIN_ATTEMPT_STORE", "database")
+	viper.SetDefault("LOGIN_MAX_FAILURES", 5)
+	viper.SetDefault("LOGIN_IP_MAX_FAILURES", 50)
+	viper.SetDefault("LOGIN_FAILURE_WINDOW", "15m")
+	viper.SetDefault("LOGIN_LOCKOUT_DURATION", "15m")
+	viper.SetDefault("LOGIN_DELAY_BASE", "1s")
+	viper.SetDefault("TOKEN_REVOCATION_STORE", "database")
+	viper.SetDefault("TOKEN_REVOCATION_PURGE_INTERVAL", "10m")
+	viper.SetDefault("CASBIN_POLICY_SEED", "config/rbac_policy.csv")
+	viper.SetDefault("CASBIN_POLICY_POLL_INTERVAL", "10s")
+	viper.SetDefault("JOBS_ENABLED", true)
+	viper.SetDefault("JOBS_INTERVAL", "1h")
+	viper.SetDefault("JOBS_BATCH_SIZE", 1000)
+	viper.SetDefault("JOB_LOCK_STORE", "database")
+	viper.SetDefault("REFRESH_TOKEN_RETENTION", "168h")
+	viper.SetDefault("ACCOUNT_DELETION_GRACE", "336h")
+	viper.SetDefault("WEBAUTHN_RP_ID", "localhost")
+	viper.SetDefault("WEBAUTHN_RP_NAME", "Ecommerce")
+	viper.SetDefault("WEBAUTHN_RP_ORIGINS", "")
+	viper.SetDefault("OAUTH_REDIRECT_BASE_URL", "http://localhost:8080")
+	viper.SetDefault("OAUTH_PROVIDERS", "")
 
 	// Membaca file .env (jika ada)
 	if err := viper.ReadInConfig(); err != nil {
@@ -46,6 +199,111 @@ func LoadConfig() (*Config, error) {
 	if err != nil { return nil, err}
 	refreshTTL, err := time.ParseDuration(viper.GetString("JWT_REFRESH_TTL"))
 	if err != nil { return nil, err}
+	reuseGrace, err := time.ParseDuration(viper.GetString("JWT_REFRESH_REUSE_GRACE"))
+	if err != nil { return nil, err}
+	impersonationTTL, err := time.ParseDuration(viper.GetString("IMPERSONATION_TTL"))
+	if err != nil { return nil, err}
+	keyRotation, err := time.ParseDuration(viper.GetString("JWT_KEY_ROTATION"))
+	if err != nil { return nil, err}
+	keyCheckInterval, err := time.ParseDuration(viper.GetString("JWT_KEY_CHECK_INTERVAL"))
+	if err != nil { return nil, err}
+	verificationTTL, err := time.ParseDuration(viper.GetString("EMAIL_VERIFICATION_TTL"))
+	if err != nil { return nil, err}
+	resetTTL, err := time.ParseDuration(viper.GetString("PASSWORD_RESET_TTL"))
+	if err != nil { return nil, err}
+	magicLinkTTL, err := time.ParseDuration(viper.GetString("MAGIC_LINK_TTL"))
+	if err != nil { return nil, err}
+	phoneOTPTTL, err := time.ParseDuration(viper.GetString("PHONE_OTP_TTL"))
+	if err != nil { return nil, err}
+	if phoneOTPTTL <= 0 || viper.GetInt("PHONE_OTP_MAX_ATTEMPTS") < 1 {
+		return nil, fmt.Errorf("PHONE_OTP_TTL dan PHONE_OTP_MAX_ATTEMPTS harus lebih dari 0")
+	}
+	mfaTokenTTL, err := time.ParseDuration(viper.GetString("MFA_TOKEN_TTL"))
+	if err != nil { return nil, err}
+	loginFailureWindow, err := time.ParseDuration(viper.GetString("LOGIN_FAILURE_WINDOW"))
+	if err != nil { return nil, err}
+	loginLockout, err := time.ParseDuration(viper.GetString("LOGIN_LOCKOUT_DURATION"))
+	if err != nil { return nil, err}
+	loginDelayBase, err := time.ParseDuration(viper.GetString("LOGIN_DELAY_BASE"))
+	if err != nil { return nil, err}
+	revocationPurge, err := time.ParseDuration(viper.GetString("TOKEN_REVOCATION_PURGE_INTERVAL"))
+	if err != nil { return nil, err}
+	policyPoll, err := time.ParseDuration(viper.GetString("CASBIN_POLICY_POLL_INTERVAL"))
+	if err != nil { return nil, err}
+	if policyPoll <= 0 {
+		return nil, fmt.Errorf("CASBIN_POLICY_POLL_INTERVAL harus lebih dari 0")
+	}
+	jobsInterval, err := time.ParseDuration(viper.GetString("JOBS_INTERVAL"))
+	if err != nil { return nil, err}
+	rtRetention, err := time.ParseDuration(viper.GetString("REFRESH_TOKEN_RETENTION"))
+	if err != nil { return nil, err}
+	// RT yang dicabut harus tetap tersimpan selama masih bisa dipakai, agar replay-nya terdeteksi sebagai reuse
+	if rtRetention < refreshTTL {
+		return nil, fmt.Errorf("REFRESH_TOKEN_RETENTION (%s) tidak boleh lebih kecil dari JWT_REFRESH_TTL (%s)", rtRetention, refreshTTL)
+	}
+	deletionGrace, err := time.ParseDuration(viper.GetString("ACCOUNT_DELETION_GRACE"))
+	if err != nil { return nil, err}
+
+	verificationMode := viper.GetString("EMAIL_VERIFICATION_MODE")
+	switch verificationMode {
+	case EmailVerificationOff, EmailVerificationLogin, EmailVerificationOrder:
+	default:
+		return nil, fmt.Errorf("EMAIL_VERIFICATION_MODE tidak valid: %s", verificationMode)
+	}
+
+	hashAlgorithm := viper.GetString("PASSWORD_HASH_ALGORITHM")
+	switch hashAlgorithm {
+	case "argon2id", "bcrypt":
+	default:
+		return nil, fmt.Errorf("PASSWORD_HASH_ALGORITHM tidak valid: %s", hashAlgorithm)
+	}
+	argonMemory, argonIterations, argonParallelism := viper.GetUint32("ARGON2_MEMORY_KIB"), viper.GetUint32("ARGON2_ITERATIONS"), viper.GetUint("ARGON2_PARALLELISM")
+	if argonMemory < 8*uint32(argonParallelism) || argonIterations < 1 || argonParallelism < 1 || argonParallelism > 255 {
+		return nil, fmt.Errorf("parameter argon2id tidak valid: m=%d t=%d p=%d", argonMemory, argonIterations, argonParallelism)
+	}
+	bcryptCost := viper.GetInt("BCRYPT_COST")
+	if bcryptCost < 4 || bcryptCost > 31 {
+		return nil, fmt.Errorf("BCRYPT_COST tidak valid: %d", bcryptCost)
+	}
+	passwordMin, passwordMax, passwordClasses := viper.GetInt("PASSWORD_MIN_LENGTH"), viper.GetInt("PASSWORD_MAX_LENGTH"), viper.GetInt("PASSWORD_MIN_CHAR_CLASSES")
+	if passwordMin < 1 || (passwordMax > 0 && passwordMax < passwordMin) || passwordClasses < 0 || passwordClasses > 4 {
+		return nil, fmt.Errorf("kebijakan password tidak valid: min=%d max=%d classes=%d", passwordMin, passwordMax, passwordClasses)
+	}
+
+	loginAttemptStore := viper.GetString("LOGIN_ATTEMPT_STORE")
+	switch loginAttemptStore {
+	case "database", "memory":
+	default:
+		return nil, fmt.Errorf("LOGIN_ATTEMPT_STORE tidak valid: %s", loginAttemptStore)
+	}
+	revocationStore := viper.GetString("TOKEN_REVOCATION_STORE")
+	switch revocationStore {
+	case "database", "memory":
+	default:
+		return nil, fmt.Errorf("TOKEN_REVOCATION_STORE tidak valid: %s", revocationStore)
+	}
+
+	jobLockStore := viper.GetString("JOB_LOCK_STORE")
+	switch jobLockStore {
+	case "database", "memory":
+	default:
+		return nil, fmt.Errorf("JOB_LOCK_STORE tidak valid: %s", jobLockStore)
+	}
+	if jobsInterval <= 0 || viper.GetInt("JOBS_BATCH_SIZE") < 1 {
+		return nil, fmt.Errorf("JOBS_INTERVAL dan JOBS_BATCH_SIZE harus lebih dari 0")
+	}
+
+	// Origin passkey default ke FRONTEND_URL karena ceremony dijalankan di browser front-end
+	webauthnOrigins := splitList(viper.GetString("WEBAUTHN_RP_ORIGINS"))
+	if len(webauthnOrigins) == 0 {
+		webauthnOrigins = []string{strings.TrimRight(viper.GetString("FRONTEND_URL"), "/")}
+	}
+	if viper.GetString("WEBAUTHN_RP_ID") == "" {
+		return nil, fmt.Errorf("WEBAUTHN_RP_ID wajib diisi")
+	}
+
+	oauthProviders, err := loadOAuthProviders(splitList(viper.GetString("OAUTH_PROVIDERS")))
+	if err != nil { return nil, err}
 
 	cfg := &Config{
 		AppPort: 	viper.GetString("APP_PORT"),
@@ -55,11 +313,108 @@ func LoadConfig() (*Config, error) {
         DBPort:     viper.GetString("DB_PORT"),
         DBName:     viper.GetString("DB_NAME"),
         DBCharset:  viper.GetString("DB_CHARSET"),
-        JWTSecret:  viper.GetString("JWT_SECRET"),
-		JWTAccessSecret: viper.GetString("JWT_ACCESS_SECRET"),
+		JWTSigningAlg: viper.GetString("JWT_SIGNING_ALG"),
+		JWTKeyDir: viper.GetString("JWT_KEY_DIR"),
+		JWTKeyRotation: keyRotation,
+		JWTKeyCheckInterval: keyCheckInterval,
 		JWTRefreshSecret: viper.GetString("JWT_REFRESH_SECRET"),
+		CSRFSecret: viper.GetString("CSRF_SECRET"),
 		AccessTTL: accessTTL,
 		RefreshTTL: refreshTTL,
+		RefreshReuseGrace: reuseGrace,
+		ImpersonationTTL: impersonationTTL,
+		FrontendURL: viper.GetString("FRONTEND_URL"),
+		MailDriver: viper.GetString("MAIL_DRIVER"),
+		MailFrom: viper.GetString("MAIL_FROM"),
+		MailFileDir: viper.GetString("MAIL_FILE_DIR"),
+		EmailVerificationMode: verificationMode,
+		EmailVerificationTTL: verificationTTL,
+		EmailVerificationPerHour: viper.GetInt("EMAIL_VERIFICATION_PER_HOUR"),
+		PasswordResetTTL: resetTTL,
+		PasswordResetPerHour: viper.GetInt("PASSWORD_RESET_PER_HOUR"),
+		MagicLinkTTL: magicLinkTTL,
+		MagicLinkPerHour: viper.GetInt("MAGIC_LINK_PER_HOUR"),
+		SMSDriver: viper.GetString("SMS_DRIVER"),
+		SMSFileDir: viper.GetString("SMS_FILE_DIR"),
+		PhoneDefaultCountryCode: strings.TrimPrefix(viper.GetString("PHONE_DEFAULT_COUNTRY_CODE"), "+"),
+		PhoneOTPTTL: phoneOTPTTL,
+		PhoneOTPMaxAttempts: viper.GetInt("PHONE_OTP_MAX_ATTEMPTS"),
+		PhoneOTPPerHour: viper.GetInt("PHONE_OTP_PER_HOUR"),
+		APIBaseURL: strings.TrimRight(viper.GetString("API_BASE_URL"), "/"),
+		PasswordHashAlgorithm: hashAlgorithm,
+		Argon2Memory: argonMemory,
+		Argon2Iterations: argonIterations,
+		Argon2Parallelism: uint8(argonParallelism),
+		BcryptCost: bcryptCost,
+		PasswordMinLength: passwordMin,
+		PasswordMaxLength: passwordMax,
+		PasswordMinCharClasses: passwordClasses,
+		PasswordBlockPersonalInfo: viper.GetBool("PASSWORD_BLOCK_PERSONAL_INFO"),
+		PasswordBreachedFile: viper.GetString("PASSWORD_BREACHED_FILE"),
+		MFAIssuer: viper.GetString("MFA_ISSUER"),
+		MFAEncryptionKey: viper.GetString("MFA_ENCRYPTION_KEY"),
+		MFARequiredRoles: splitList(viper.GetString("MFA_REQUIRED_ROLES")),
+		MFATokenTTL: mfaTokenTTL,
+		MFAMaxFailures: viper.GetInt("MFA_MAX_FAILURES"),
+		LoginAttemptStore: loginAttemptStore,
+		LoginMaxFailures: viper.GetInt("LOGIN_MAX_FAILURES"),
+		LoginIPMaxFailures: viper.GetInt("LOGIN_IP_MAX_FAILURES"),
+		LoginFailureWindow: loginFailureWindow,
+		LoginLockoutDuration: loginLockout,
+		LoginDelayBase: loginDelayBase,
+		TokenRevocationStore: revocationStore,
+		TokenRevocationPurge: revocationPurge,
+		CasbinPolicySeed: viper.GetString("CASBIN_POLICY_SEED"),
+		CasbinPolicyPoll: policyPoll,
+		JobsEnabled: viper.GetBool("JOBS_ENABLED"),
+		JobsInterval: jobsInterval,
+		JobsBatchSize: viper.GetInt("JOBS_BATCH_SIZE"),
+		JobLockStore: jobLockStore,
+		RefreshTokenRetention: rtRetention,
+		AccountDeletionGrace: deletionGrace,
+		WebAuthnRPID: viper.GetString("WEBAUTHN_RP_ID"),
+		WebAuthnRPName: viper.GetString("WEBAUTHN_RP_NAME"),
+		WebAuthnRPOrigins: webauthnOrigins,
+		OAuthRedirectBaseURL: strings.TrimRight(viper.GetString("OAUTH_REDIRECT_BASE_URL"), "/"),
+		OAuthProviders: oauthProviders,
 	}
 	return cfg,nil
-}
\ No newline at end of file
+}
+
+// loadOAuthProviders membaca konfigurasi setiap provider yang terdaftar di OAUTH_PROVIDERS.
+func loadOAuthProviders(names []string) ([]OAuthProviderConfig, error) {
+	providers := make([]OAuthProviderConfig, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(name)
+		prefix := "OAUTH_" + strings.ToUpper(name) + "_"
+		p := OAuthProviderConfig{
+			Name:         name,
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+			Scopes:       splitList(viper.GetString(prefix + "SCOPES")),
+		}
+		if p.Issuer == "" {
+			p.Issuer = defaultOAuthIssuers[name]
+		}
+		if p.Issuer == "" || p.ClientID == "" {
+			return nil, fmt.Errorf("provider OAuth %s butuh %sISSUER dan %sCLIENT_ID", name, prefix, prefix)
+		}
+		if len(p.Scopes) == 0 {
+			p.Scopes = []string{"openid", "email", "profile"}
+		}
+		providers = append(providers, p)
+	}
+	return providers, nil
+}
+
+// splitList memecah nilai dipisah koma menjadi slice tanpa elemen kosong.
+func splitList(value string) []string {
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
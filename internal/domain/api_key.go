@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyScopes adalah scope yang boleh diberikan ke API key, berformat "<obj>:<act>"
+// sesuai objek & aksi Casbin. Scope hanya mempersempit izin: role pemilik tetap dicek Casbin.
+var APIKeyScopes = []string{
+	"product:create",
+	"product:update",
+	"product:delete",
+	"order:read",
+	"order:create",
+}
+
+// APIKey adalah kredensial machine-to-machine milik user (mis. sinkronisasi ERP seller).
+// Format key: ek_<prefix>_<secret>; hanya prefix dan hash sha256 dari key utuh yang disimpan.
+type APIKey struct {
+	ID         uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	UserID     uuid.UUID  `gorm:"type:char(36);not null;index"`
+	Name       string     `gorm:"size:100;not null"`
+	Prefix     string     `gorm:"size:16;uniqueIndex;not null"`
+	KeyHash    string     `gorm:"type:char(64);not null"`
+	Scopes     string     `gorm:"size:512;not null"` // dipisah koma, mis. "product:create,product:update"
+	ExpiresAt  *time.Time // nil = tidak kedaluwarsa
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// ScopeList mengembalikan scope sebagai slice.
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// HasScope mengecek apakah key memiliki scope "<obj>:<act>".
+func (k *APIKey) HasScope(obj, act string) bool {
+	return slices.Contains(k.ScopeList(), obj+":"+act)
+}
+
+// Active mengembalikan true jika key belum dicabut dan belum kedaluwarsa.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(now))
+}
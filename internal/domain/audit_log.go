@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Aksi yang dicatat di audit log.
+const (
+	AuditImpersonationStart   = "impersonation.start"
+	AuditImpersonationStop    = "impersonation.stop"
+	AuditImpersonationRequest = "impersonation.request" // mutasi (POST/PUT/PATCH/DELETE) selama impersonasi
+	AuditPolicyAdd            = "policy.add"            // rule Casbin (p/g) ditambahkan lewat /admin/policies
+	AuditPolicyRemove         = "policy.remove"         // rule Casbin (p/g) dihapus lewat /admin/policies
+	AuditRoleCreate           = "role.create"           // role kustom dibuat lewat /admin/roles
+)
+
+// AuditLog adalah satu entri jejak audit. Tabel ini hanya ditambah, tidak pernah diubah.
+type AuditLog struct {
+	ID        uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	ActorID   uuid.UUID  `gorm:"type:char(36);not null;index"` // yang benar-benar melakukan aksi
+	UserID    *uuid.UUID `gorm:"type:char(36);index"`          // user yang terdampak / di-impersonate
+	Action    string     `gorm:"size:64;not null"`
+	Method    string     `gorm:"size:10"`
+	Path      string     `gorm:"size:512"`
+	Status    int
+	IPAddress string `gorm:"size:45"`
+	UserAgent string `gorm:"size:255"`
+	Metadata  string `gorm:"type:text"` // JSON tambahan, mis. {"reason":"..."}
+	CreatedAt time.Time
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// CasbinRule adalah satu baris policy Casbin. Ptype "p" berisi (sub, obj, act) di V0–V2,
+// ptype "g" berisi (user/role, role induk) di V0–V1; kolom sisanya kosong.
+type CasbinRule struct {
+	ID    uint64 `gorm:"primaryKey;autoIncrement"`
+	Ptype string `gorm:"size:100;not null"`
+	V0    string `gorm:"size:100;not null"`
+	V1    string `gorm:"size:100;not null"`
+	V2    string `gorm:"size:100;not null"`
+	V3    string `gorm:"size:100;not null"`
+	V4    string `gorm:"size:100;not null"`
+	V5    string `gorm:"size:100;not null"`
+}
+
+// TableName mengikuti nama tabel bawaan adapter Casbin.
+func (CasbinRule) TableName() string {
+	return "casbin_rule"
+}
+
+// Values mengembalikan isi rule tanpa ptype, dengan kolom kosong di akhir dibuang.
+func (r CasbinRule) Values() []string {
+	values := []string{r.V0, r.V1, r.V2, r.V3, r.V4, r.V5}
+	for len(values) > 0 && values[len(values)-1] == "" {
+		values = values[:len(values)-1]
+	}
+	return values
+}
+
+// NewCasbinRule menyusun CasbinRule dari ptype dan nilai rule (maksimal 6 kolom).
+func NewCasbinRule(ptype string, values []string) CasbinRule {
+	rule := CasbinRule{Ptype: ptype}
+	fields := []*string{&rule.V0, &rule.V1, &rule.V2, &rule.V3, &rule.V4, &rule.V5}
+	for i, v := range values {
+		if i < len(fields) {
+			*fields[i] = v
+		}
+	}
+	return rule
+}
+
+// CasbinPolicyVersion adalah penanda perubahan policy (satu baris, id = 1).
+// Setiap perubahan casbin_rule menaikkan Version di transaksi yang sama.
+type CasbinPolicyVersion struct {
+	ID        uint8 `gorm:"primaryKey"`
+	Version   int64 `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// TableName mengembalikan nama tabel penanda versi policy.
+func (CasbinPolicyVersion) TableName() string {
+	return "casbin_policy_version"
+}
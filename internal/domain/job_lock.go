@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// JobLock adalah lease per job pemeliharaan agar hanya satu replika yang menjalankannya per interval.
+type JobLock struct {
+	Name        string    `gorm:"size:100;primaryKey"`
+	Owner       string    `gorm:"size:191;not null"` // ID instance pemegang lease
+	LockedUntil time.Time `gorm:"not null"`
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// LoginAttempt mencatat kegagalan login beruntun untuk satu kunci pelacakan:
+// "acct:<email>" (per akun) atau "ip:<alamat>" (per IP klien).
+type LoginAttempt struct {
+	Key          string     `gorm:"size:191;primaryKey"`
+	Failures     int        `gorm:"not null;default:0"`
+	LastFailedAt time.Time  `gorm:"not null"`
+	LockedUntil  *time.Time // terisi saat kunci sedang dikunci sementara
+	UpdatedAt    time.Time
+}
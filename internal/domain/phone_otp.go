@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tujuan kode OTP SMS yang tersimpan di tabel phone_otps.
+const (
+	PhoneOTPPurposeVerify = "verify_phone" // konfirmasi nomor HP baru milik user
+	PhoneOTPPurposeLogin  = "login"        // login dengan nomor HP sebagai faktor utama
+	PhoneOTPPurposeMFA    = "mfa"          // 2FA via SMS setelah password
+)
+
+// PhoneOTP menyimpan hash kode OTP 6 digit yang dikirim via SMS.
+// Kode berumur pendek dan hangus setelah terlalu banyak tebakan salah.
+type PhoneOTP struct {
+	ID        uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:char(36);not null"`
+	Phone     string     `gorm:"size:20;not null"`
+	Purpose   string     `gorm:"size:32;not null"`
+	CodeHash  string     `gorm:"type:char(64);not null"` // sha256 hex dari kode
+	Attempts  int        `gorm:"not null"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // terisi saat kode dipakai, diganti kode baru, atau hangus
+	CreatedAt time.Time
+}
@@ -6,13 +6,26 @@ import (
 	"github.com/google/uuid"
 )
 
+// RefreshToken menyimpan hash RT yang pernah diterbitkan.
+// Setiap rotasi menghasilkan anggota baru dalam family yang sama (FamilyID),
+// dengan ParentID menunjuk ke RT sebelumnya.
 type RefreshToken struct {
-	ID        uuid.UUID `gorm:"type:char(36);primaryKey"`
-	UserID    uuid.UUID `gorm:"type:char(36);not null"`
-	TokenHash string    `gorm:"type:char(64);not null"`
-	IssuedAt  time.Time `gorm:"not null"`
-	ExpiresAt time.Time `gorm:"not null"`
-	Revoked   bool      `gorm:"not null;default:false"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID        uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:char(36);not null"`
+	FamilyID  uuid.UUID  `gorm:"type:char(36);not null"` // sama dengan ID RT pertama saat login
+	ParentID  *uuid.UUID `gorm:"type:char(36)"`          // nil untuk RT pertama dalam family
+	TokenHash string     `gorm:"type:char(64);not null"`
+	IssuedAt  time.Time  `gorm:"not null"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	Revoked   bool       `gorm:"not null;default:false"`
+	RevokedAt *time.Time // waktu pencabutan; dipakai untuk grace window refresh bersamaan
+	// jti AT terakhir yang diterbitkan untuk sesi ini; masuk denylist saat sesi dicabut
+	AccessTokenID string `gorm:"size:36;not null;default:''"`
+
+	// Info perangkat untuk daftar sesi
+	UserAgent  string     `gorm:"size:255"`
+	IPAddress  string     `gorm:"size:45"`
+	LastUsedAt *time.Time // waktu login / rotasi terakhir
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevokedAccessToken adalah AT yang dicabut sebelum kedaluwarsa (logout, pencabutan sesi).
+// Entri boleh dihapus setelah ExpiresAt karena token-nya sudah tidak valid dengan sendirinya.
+type RevokedAccessToken struct {
+	JTI       string    `gorm:"column:jti;size:64;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// AccessTokenCutoff mencabut semua AT milik user yang diterbitkan sebelum RevokedBefore
+// (ganti role, suspend, reset password). Entri kedaluwarsa setelah ExpiresAt = RevokedBefore + umur AT.
+type AccessTokenCutoff struct {
+	UserID        uuid.UUID `gorm:"type:char(36);primaryKey"`
+	RevokedBefore time.Time `gorm:"not null"`
+	ExpiresAt     time.Time `gorm:"not null;index"`
+	UpdatedAt     time.Time
+}
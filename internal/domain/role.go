@@ -5,6 +5,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// Nama role bawaan hasil seed. Role kustom dapat ditambahkan lewat /admin/roles.
+const (
+    RoleSuperAdmin = "super_admin"
+    RoleAdmin      = "admin"
+)
+
 // Role menampung jenis peran (super_admin, admin, penjual, pembeli).
 type Role struct {
     ID          uuid.UUID `gorm:"type:char(36);primaryKey" json:"id"`
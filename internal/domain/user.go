@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -13,5 +15,18 @@ type User struct {
     Password string    `gorm:"size:255;not null" json:"-"`         // password hash, tidak diekspor ke JSON
     RoleID   uuid.UUID `gorm:"type:char(36);not null" json:"role_id"`
     Role     Role      `gorm:"foreignKey:RoleID" json:"role"`      // relasi ke Role
+    EmailVerifiedAt *time.Time `json:"email_verified_at"`          // nil jika email belum diverifikasi
+    SuspendedAt     *time.Time `json:"suspended_at"`               // diisi admin; user tidak bisa login selama tidak nil
+    PendingEmail    *string    `gorm:"size:255" json:"pending_email"` // email baru yang menunggu konfirmasi
+    Phone             *string    `gorm:"size:20;uniqueIndex" json:"phone"` // nomor HP terverifikasi (E.164), nil jika belum ada
+    PhoneVerifiedAt   *time.Time `json:"phone_verified_at"`
+    PhoneMFAEnabledAt *time.Time `json:"phone_mfa_enabled_at"`                // 2FA via SMS aktif
+    DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`     // akun dianonimkan pada waktu ini kecuali dibatalkan
+    AnonymizedAt        *time.Time `json:"-"`                         // terisi setelah PII dihapus (tidak bisa dibatalkan)
     gorm.Model // menyertakan CreatedAt, UpdatedAt, DeletedAt (untuk soft delete)
 }
+
+// Suspended mengembalikan true jika akun sedang ditangguhkan admin.
+func (u *User) Suspended() bool {
+    return u.SuspendedAt != nil
+}
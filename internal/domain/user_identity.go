@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity menautkan akun di provider login sosial (provider + subject) ke User lokal.
+type UserIdentity struct {
+	ID          uuid.UUID  `gorm:"type:char(36);primaryKey" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:char(36);not null" json:"-"`
+	Provider    string     `gorm:"size:50;not null;uniqueIndex:uq_identity_provider_subject" json:"provider"`
+	Subject     string     `gorm:"size:255;not null;uniqueIndex:uq_identity_provider_subject" json:"-"` // klaim sub dari provider
+	Email       string     `gorm:"size:255" json:"email"`                                               // email di provider saat terakhir login
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserMFA menyimpan konfigurasi TOTP milik user. Secret disimpan terenkripsi (AES-GCM).
+// EnabledAt nil berarti enrollment belum dikonfirmasi dengan kode pertama.
+type UserMFA struct {
+	UserID          uuid.UUID `gorm:"type:char(36);primaryKey"`
+	SecretEncrypted string    `gorm:"size:255;not null"`
+	EnabledAt       *time.Time
+	LastUsedStep    int64 `gorm:"not null;default:0"` // time step terakhir yang dipakai; cegah replay kode
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// TableName memakai nama tabel tunggal sesuai migrasi.
+func (UserMFA) TableName() string { return "user_mfa" }
+
+// MFARecoveryCode adalah kode cadangan sekali pakai jika perangkat authenticator hilang.
+type MFARecoveryCode struct {
+	ID        uuid.UUID `gorm:"type:char(36);primaryKey"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null"`
+	CodeHash  string    `gorm:"type:char(64);not null"` // sha256 hex dari kode
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tujuan token sekali pakai yang tersimpan di tabel user_tokens.
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailChange       = "email_change"
+	TokenPurposeMagicLink         = "magic_link"
+)
+
+// UserToken menyimpan hash token sekali pakai yang dikirim ke pengguna (mis. link verifikasi email atau reset password).
+// Token plaintext tidak pernah disimpan.
+type UserToken struct {
+	ID        uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:char(36);not null"`
+	Purpose   string     `gorm:"size:32;not null"`
+	TokenHash string     `gorm:"type:char(64);uniqueIndex;not null"` // sha256 hex dari token
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // terisi saat token dipakai; token tidak bisa dipakai dua kali
+	CreatedAt time.Time
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebAuthnCredential adalah passkey/security key yang didaftarkan user untuk login tanpa password.
+// Hanya kunci publik yang disimpan; verifikasi login memakai tanda tangan dari authenticator.
+type WebAuthnCredential struct {
+	ID              uuid.UUID `gorm:"type:char(36);primaryKey"`
+	UserID          uuid.UUID `gorm:"type:char(36);not null;index"`
+	CredentialID    []byte    `gorm:"type:varbinary(255);uniqueIndex;not null"`
+	PublicKey       []byte    `gorm:"type:blob;not null"`
+	AttestationType string    `gorm:"size:32;not null"`
+	Transports      string    `gorm:"size:255;not null"` // dipisah koma, mis. "internal,hybrid"
+	AAGUID          []byte    `gorm:"column:aaguid;type:varbinary(16)"`
+	Flags           uint8     `gorm:"not null"` // flag authenticator data terakhir (UV, BE, BS, ...)
+	SignCount       uint32    `gorm:"not null"`
+	CloneWarning    bool      `gorm:"not null"`
+	Name            string    `gorm:"size:100;not null"`
+	LastUsedAt      *time.Time
+	CreatedAt       time.Time
+}
+
+// TableName menyesuaikan nama tabel migrasi (GORM akan memakai "web_authn_credentials").
+func (WebAuthnCredential) TableName() string { return "webauthn_credentials" }
+
+// TransportList mengembalikan transport sebagai slice.
+func (c *WebAuthnCredential) TransportList() []string {
+	if c.Transports == "" {
+		return []string{}
+	}
+	return strings.Split(c.Transports, ",")
+}
@@ -0,0 +1,25 @@
+package dto
+
+// CreateAPIKeyRequest adalah payload pembuatan API key.
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"` // 0 = tidak kedaluwarsa
+}
+
+// APIKeyResponse menampilkan metadata API key (tanpa secret).
+type APIKeyResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"` // bagian awal key untuk identifikasi, mis. "ek_1a2b3c4d5e6f"
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  *string  `json:"expires_at"`   // RFC3339
+	LastUsedAt *string  `json:"last_used_at"` // RFC3339
+	CreatedAt  string   `json:"created_at"`   // RFC3339
+}
+
+// CreatedAPIKeyResponse berisi key plaintext; hanya ditampilkan sekali saat dibuat.
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
@@ -0,0 +1,49 @@
+package dto
+
+import "encoding/json"
+
+// StartImpersonationRequest adalah payload POST /admin/users/{id}/impersonate.
+type StartImpersonationRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"` // mis. nomor tiket support
+}
+
+// ImpersonationResponse berisi access token impersonasi. Token dipakai sebagai Bearer
+// (tidak diset sebagai cookie) agar sesi admin sendiri tidak tertimpa.
+type ImpersonationResponse struct {
+	AccessToken    string       `json:"access_token"`
+	ExpiresAt      string       `json:"expires_at"` // RFC3339
+	User           UserResponse `json:"user"`
+	ImpersonatorID string       `json:"impersonator_id"`
+}
+
+// ListAuditLogsQuery merepresentasikan query string GET /admin/audit-logs.
+type ListAuditLogsQuery struct {
+	ActorID  string `validate:"omitempty,uuid"`
+	UserID   string `validate:"omitempty,uuid"`
+	Action   string `validate:"max=64"`
+	Page     int    `validate:"min=1"`
+	PageSize int    `validate:"min=1,max=100"`
+}
+
+// AuditLogResponse adalah satu entri audit log.
+type AuditLogResponse struct {
+	ID        string          `json:"id"`
+	ActorID   string          `json:"actor_id"`
+	UserID    *string         `json:"user_id"`
+	Action    string          `json:"action"`
+	Method    string          `json:"method,omitempty"`
+	Path      string          `json:"path,omitempty"`
+	Status    int             `json:"status,omitempty"`
+	IPAddress string          `json:"ip_address,omitempty"`
+	UserAgent string          `json:"user_agent,omitempty"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt string          `json:"created_at"` // RFC3339
+}
+
+// AuditLogListResponse adalah satu halaman hasil GET /admin/audit-logs.
+type AuditLogListResponse struct {
+	Entries  []AuditLogResponse `json:"entries"`
+	Total    int64              `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+// MFAVerifyRequest adalah langkah kedua login: token "mfa pending" + kode TOTP, recovery code, atau kode SMS.
+type MFAVerifyRequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code" validate:"required_without_all=RecoveryCode SMSCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without_all=Code SMSCode"`
+	SMSCode      string `json:"sms_code" validate:"omitempty,len=6,numeric"`
+}
+
+// MFAEnrollRequest memulai enrollment. MFAToken hanya diisi jika user belum login
+// karena role-nya mewajibkan 2FA (login mengembalikan mfa_enrollment_required).
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+// MFAConfirmRequest mengonfirmasi enrollment dengan kode pertama dari aplikasi authenticator.
+type MFAConfirmRequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// MFACodeRequest berisi kode TOTP untuk aksi sensitif (nonaktifkan 2FA, buat ulang recovery code).
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// MFAEnrollmentResponse berisi secret dan URI otpauth:// untuk QR code.
+type MFAEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFARecoveryCodesResponse berisi recovery code plaintext; hanya ditampilkan sekali.
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAStatusResponse menampilkan status 2FA user.
+type MFAStatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	Required               bool  `json:"required"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+	SMSEnabled             bool  `json:"sms_enabled"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+import "time"
+
+// PhoneRequest berisi nomor HP (format lokal 08xx atau E.164 +62xx) untuk verifikasi atau login.
+type PhoneRequest struct {
+	Phone string `json:"phone" validate:"required,max=20"`
+}
+
+// PhoneCodeRequest berisi kode OTP 6 digit yang dikirim via SMS.
+type PhoneCodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// PhoneLoginRequest adalah login dengan nomor HP sebagai faktor utama: nomor + kode OTP.
+type PhoneLoginRequest struct {
+	Phone string `json:"phone" validate:"required,max=20"`
+	Code  string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// MFASMSCodeRequest berisi kode OTP SMS untuk aksi 2FA SMS (nonaktifkan).
+type MFASMSCodeRequest struct {
+	SMSCode string `json:"sms_code" validate:"required,len=6,numeric"`
+}
+
+// MFASMSSendRequest meminta kode 2FA SMS. MFAToken hanya diisi pada langkah kedua login
+// (user belum punya sesi); user yang sudah login memakai sesinya.
+type MFASMSSendRequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+// PhoneResponse menampilkan nomor HP user beserta status verifikasi dan 2FA SMS.
+type PhoneResponse struct {
+	Phone      *string    `json:"phone"`
+	VerifiedAt *time.Time `json:"verified_at"`
+	MFAEnabled bool       `json:"mfa_enabled"`
+}
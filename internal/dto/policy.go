@@ -0,0 +1,23 @@
+package dto
+
+// PolicyRule adalah rule "p": Subject (role) boleh melakukan Action terhadap Object (resource)
+// selama Condition bernilai true. Condition kosong berarti tanpa syarat ("true"); contoh syarat
+// kepemilikan: "r.res.Owner == r.sub.ID". Koma ditolak karena menjadi pemisah kolom di format policy Casbin.
+type PolicyRule struct {
+	Subject   string `json:"subject" validate:"required,max=100,excludesall=0x2C"`
+	Object    string `json:"object" validate:"required,max=100,excludesall=0x2C"`
+	Action    string `json:"action" validate:"required,max=100,excludesall=0x2C"`
+	Condition string `json:"condition" validate:"max=100,excludesall=0x2C"`
+}
+
+// GroupingRule adalah rule "g": Subject (role) mewarisi semua izin Role.
+type GroupingRule struct {
+	Subject string `json:"subject" validate:"required,max=100,excludesall=0x2C"`
+	Role    string `json:"role" validate:"required,max=100,excludesall=0x2C,nefield=Subject"`
+}
+
+// PolicyListResponse berisi seluruh policy yang sedang berlaku.
+type PolicyListResponse struct {
+	Policies  []PolicyRule   `json:"policies"`
+	Groupings []GroupingRule `json:"groupings"`
+}
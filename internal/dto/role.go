@@ -0,0 +1,16 @@
+package dto
+
+// CreateRoleRequest adalah payload pembuatan role kustom. Nama dipakai sebagai subject di policy Casbin,
+// jadi hanya huruf kecil, angka, dan underscore (diawali huruf).
+type CreateRoleRequest struct {
+	Name        string `json:"name" validate:"required,min=3,max=50"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+// RoleResponse menampilkan data role.
+type RoleResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	CreatedAt   string `json:"created_at"` // RFC3339
+}
@@ -0,0 +1,11 @@
+package dto
+
+// SessionResponse merepresentasikan satu sesi perangkat yang sedang login.
+type SessionResponse struct {
+	ID         string `json:"id"` // ID sesi (family RT)
+	UserAgent  string `json:"user_agent"`
+	IPAddress  string `json:"ip_address"`
+	LastUsedAt string `json:"last_used_at"` // RFC3339
+	ExpiresAt  string `json:"expires_at"`   // RFC3339
+	Current    bool   `json:"current"`      // true jika sesi ini dipakai oleh request saat ini
+}
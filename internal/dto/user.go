@@ -4,7 +4,7 @@ package dto
 type RegisterUserRequest struct {
     Name     string `json:"name" validate:"required,min=3,max=50"`
     Email    string `json:"email" validate:"required,email"`
-    Password string `json:"password" validate:"required,min=6"`
+    Password string `json:"password" validate:"required"` // kekuatan diperiksa password.Policy
 }
 
 // LoginRequest merepresentasikan payload JSON untuk login pengguna.
@@ -13,6 +13,37 @@ type LoginRequest struct {
     Password string `json:"password" validate:"required"`
 }
 
+// VerifyEmailRequest merepresentasikan payload JSON untuk verifikasi email.
+type VerifyEmailRequest struct {
+    Token string `json:"token" validate:"required"`
+}
+
+// ResendVerificationRequest merepresentasikan payload JSON untuk kirim ulang link verifikasi.
+type ResendVerificationRequest struct {
+    Email string `json:"email" validate:"required,email"`
+}
+
+// ForgotPasswordRequest merepresentasikan payload JSON untuk meminta link reset password.
+type ForgotPasswordRequest struct {
+    Email string `json:"email" validate:"required,email"`
+}
+
+// MagicLinkRequest merepresentasikan payload JSON untuk meminta link login tanpa password.
+type MagicLinkRequest struct {
+    Email string `json:"email" validate:"required,email"`
+}
+
+// MagicLinkConsumeRequest merepresentasikan payload JSON untuk menukar token magic link dengan sesi.
+type MagicLinkConsumeRequest struct {
+    Token string `json:"token" validate:"required"`
+}
+
+// ResetPasswordRequest merepresentasikan payload JSON untuk mengganti password dengan token reset.
+type ResetPasswordRequest struct {
+    Token    string `json:"token" validate:"required"`
+    Password string `json:"password" validate:"required"` // kekuatan diperiksa password.Policy
+}
+
 // UserResponse merepresentasikan data pengguna yang dikirim dalam response.
 type UserResponse struct {
     ID    string `json:"id"`
@@ -21,10 +52,78 @@ type UserResponse struct {
     Role  string `json:"role"`
 }
 
-// LoginResponse mengembalikan data pengguna dan token JWT setelah login berhasil.
+// LoginResponse mengembalikan data pengguna setelah login berhasil.
+// Token tidak dikirim di body; AT & RT diset sebagai cookie HttpOnly oleh AuthHandler.
 type LoginResponse struct {
-    User  UserResponse `json:"user"`
-    Token string       `json:"token"`
+    User UserResponse `json:"user"`
+}
+
+// menggunakan tag validate agar library validator/v10 dapat memeriksa input secara otomatis.
+
+// ListUsersQuery merepresentasikan query string GET /admin/users.
+type ListUsersQuery struct {
+    Query    string `validate:"max=100"`
+    Role     string `validate:"omitempty,max=50"`
+    Status   string `validate:"omitempty,oneof=active suspended"`
+    Page     int    `validate:"min=1"`
+    PageSize int    `validate:"min=1,max=100"`
+}
+
+// ChangeUserRoleRequest merepresentasikan payload JSON untuk mengganti role user.
+type ChangeUserRoleRequest struct {
+    RoleID string `json:"role_id" validate:"required,uuid"`
+}
+
+// AdminUserResponse adalah data user untuk halaman admin.
+type AdminUserResponse struct {
+    ID              string  `json:"id"`
+    Name            string  `json:"name"`
+    Email           string  `json:"email"`
+    RoleID          string  `json:"role_id"`
+    Role            string  `json:"role"`
+    EmailVerifiedAt *string `json:"email_verified_at"`
+    SuspendedAt     *string `json:"suspended_at"`
+    CreatedAt       string  `json:"created_at"`
+}
+
+// UserListResponse adalah satu halaman hasil GET /admin/users.
+type UserListResponse struct {
+    Users    []AdminUserResponse `json:"users"`
+    Total    int64               `json:"total"`
+    Page     int                 `json:"page"`
+    PageSize int                 `json:"page_size"`
+}
+
+// UpdateProfileRequest merepresentasikan payload JSON PATCH /auth/me; field yang tidak dikirim tidak diubah.
+type UpdateProfileRequest struct {
+    Name  *string `json:"name" validate:"omitempty,min=3,max=50"`
+    Email *string `json:"email" validate:"omitempty,email"`
+}
+
+// ConfirmEmailChangeRequest merepresentasikan payload JSON untuk konfirmasi email baru.
+type ConfirmEmailChangeRequest struct {
+    Token string `json:"token" validate:"required"`
+}
+
+// ChangePasswordRequest merepresentasikan payload JSON untuk mengganti password dari sesi yang login.
+type ChangePasswordRequest struct {
+    CurrentPassword string `json:"current_password" validate:"required"`
+    NewPassword     string `json:"new_password" validate:"required"` // kekuatan diperiksa password.Policy
+}
+
+// ProfileResponse adalah profil user setelah diperbarui.
+type ProfileResponse struct {
+    User         UserResponse `json:"user"`
+    PendingEmail *string      `json:"pending_email,omitempty"` // terisi jika email baru menunggu konfirmasi
+}
+
+// DeleteAccountRequest merepresentasikan payload JSON untuk meminta penghapusan akun.
+// Password wajib untuk akun yang memiliki password (diperiksa di service).
+type DeleteAccountRequest struct {
+    Password string `json:"password"`
 }
 
-// menggunakan tag validate agar library validator/v10 dapat memeriksa input secara otomatis.
\ No newline at end of file
+// AccountDeletionResponse berisi jadwal anonimisasi akun.
+type AccountDeletionResponse struct {
+    ScheduledAt string `json:"scheduled_at"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "encoding/json"
+
+// FinishWebAuthnRegistrationRequest adalah payload langkah akhir pendaftaran passkey.
+// Credential berisi hasil navigator.credentials.create() yang diserialisasi apa adanya (PublicKeyCredential JSON).
+type FinishWebAuthnRegistrationRequest struct {
+	Name       string          `json:"name" validate:"max=100"` // kosong = "Passkey"
+	Credential json.RawMessage `json:"credential" validate:"required"`
+}
+
+// WebAuthnCredentialResponse menampilkan metadata passkey (tanpa kunci publik).
+type WebAuthnCredentialResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Transports []string `json:"transports"`
+	Synced     bool     `json:"synced"`       // tersinkron ke cloud (flag backup state), mis. iCloud Keychain
+	LastUsedAt *string  `json:"last_used_at"` // RFC3339
+	CreatedAt  string   `json:"created_at"`   // RFC3339
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"github.com/itujun/project-ecommerce-go-next/internal/utils"
+)
+
+// defaultUserPageSize dipakai jika query page_size tidak dikirim.
+const defaultUserPageSize = 20
+
+// AdminUserHandler menangani endpoint administrasi user (khusus admin, dijaga Casbin).
+type AdminUserHandler struct {
+	userService      *service.UserService
+	adminUserService *service.AdminUserService
+}
+
+// NewAdminUserHandler membuat instance baru AdminUserHandler.
+func NewAdminUserHandler(userService *service.UserService, adminUserService *service.AdminUserService) *AdminUserHandler {
+	return &AdminUserHandler{userService: userService, adminUserService: adminUserService}
+}
+
+// List menangani GET /admin/users?q=&role=&status=active|suspended&page=&page_size=.
+func (h *AdminUserHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := dto.ListUsersQuery{
+		Query:    q.Get("q"),
+		Role:     q.Get("role"),
+		Status:   q.Get("status"),
+		Page:     1,
+		PageSize: defaultUserPageSize,
+	}
+	if !readPagination(w, q, &query.Page, &query.PageSize) {
+		return
+	}
+	res, err := h.adminUserService.List(r.Context(), query)
+	if err != nil {
+		writeAdminUserError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// Get menangani GET /admin/users/{id}.
+func (h *AdminUserHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	res, err := h.adminUserService.Get(r.Context(), id)
+	if err != nil {
+		writeAdminUserError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// ChangeRole menangani PUT /admin/users/{id}/role.
+func (h *AdminUserHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+	var req dto.ChangeUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	res, err := h.adminUserService.ChangeRole(r.Context(), principal.UserID, id, req)
+	if err != nil {
+		writeAdminUserError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// Suspend menangani POST /admin/users/{id}/suspend.
+func (h *AdminUserHandler) Suspend(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.adminUserService.Suspend(r.Context(), principal.UserID, id)
+	if err != nil {
+		writeAdminUserError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// Reactivate menangani POST /admin/users/{id}/reactivate.
+func (h *AdminUserHandler) Reactivate(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.adminUserService.Reactivate(r.Context(), principal.UserID, id)
+	if err != nil {
+		writeAdminUserError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// Delete menangani DELETE /admin/users/{id} (soft delete).
+func (h *AdminUserHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+	if err := h.adminUserService.Delete(r.Context(), principal.UserID, id); err != nil {
+		writeAdminUserError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Unlock menangani POST /admin/users/{id}/unlock: membuka kunci login akibat brute-force.
+func (h *AdminUserHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	if err := h.userService.UnlockUser(r.Context(), id); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readPagination membaca query page & page_size; false jika bukan angka (respons 400 sudah ditulis).
+func readPagination(w http.ResponseWriter, q url.Values, page, pageSize *int) bool {
+	var err error
+	if v := q.Get("page"); v != "" {
+		if *page, err = strconv.Atoi(v); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"page": "Halaman harus berupa angka"})
+			return false
+		}
+	}
+	if v := q.Get("page_size"); v != "" {
+		if *pageSize, err = strconv.Atoi(v); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"page_size": "Ukuran halaman harus berupa angka"})
+			return false
+		}
+	}
+	return true
+}
+
+// adminTarget mengambil admin yang login (Principal) dan ID user yang dituju dari URL.
+func adminTarget(w http.ResponseWriter, r *http.Request) (*middleware.Principal, uuid.UUID, bool) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return nil, uuid.Nil, false
+	}
+	return principal, id, true
+}
+
+// writeAdminUserError memetakan error AdminUserService ke status HTTP.
+func writeAdminUserError(w http.ResponseWriter, err error) {
+	var ve validator.ValidationErrors
+	switch {
+	case errors.As(err, &ve):
+		writeJSON(w, http.StatusBadRequest, utils.ValidationErrorsToMap(ve))
+	case errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrRoleNotFound):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"role": err.Error()})
+	case errors.Is(err, service.ErrSuperAdminOnly):
+		writeJSON(w, http.StatusForbidden, map[string]string{"general": err.Error()})
+	case errors.Is(err, service.ErrCannotModifySelf):
+		writeJSON(w, http.StatusConflict, map[string]string{"general": err.Error()})
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
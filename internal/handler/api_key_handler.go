@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"github.com/itujun/project-ecommerce-go-next/internal/utils"
+)
+
+// APIKeyHandler menangani pengelolaan API key milik user yang login.
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+// NewAPIKeyHandler membuat instance baru APIKeyHandler.
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// List menangani GET /auth/api-keys.
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	keys, err := h.apiKeyService.List(r.Context(), principal.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"api_keys": keys})
+}
+
+// Create menangani POST /auth/api-keys. Key plaintext hanya ditampilkan di respons ini.
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	res, err := h.apiKeyService.Create(r.Context(), principal.UserID, req)
+	if err != nil {
+		var ve validator.ValidationErrors
+		switch {
+		case errors.As(err, &ve):
+			writeJSON(w, http.StatusBadRequest, utils.ValidationErrorsToMap(ve))
+		case errors.Is(err, service.ErrInvalidAPIKeyScope):
+			writeJSON(w, http.StatusBadRequest, map[string]string{"scopes": err.Error()})
+		case errors.Is(err, service.ErrAPIKeyLimit):
+			writeJSON(w, http.StatusConflict, map[string]string{"general": err.Error()})
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSON(w, http.StatusCreated, res)
+}
+
+// Revoke menangani DELETE /auth/api-keys/{id}.
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid api key id", http.StatusBadRequest)
+		return
+	}
+	if err := h.apiKeyService.Revoke(r.Context(), principal.UserID, id); err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
 	"github.com/itujun/project-ecommerce-go-next/internal/service"
 	"github.com/itujun/project-ecommerce-go-next/internal/utils"
 )
@@ -18,13 +21,19 @@ import (
 type AuthHandler struct {
 	userService *service.UserService
 	jwtService  *service.JWTService // <-- tambahkan JWT service
+	verificationService *service.EmailVerificationService
+	loginFlow   *LoginFlow
+	revocation  *service.TokenRevocationService
 }
 
 // NewAuthHandler membuat instance baru AuthHandler
-func NewAuthHandler(userService *service.UserService, jwtService *service.JWTService) *AuthHandler {
+func NewAuthHandler(userService *service.UserService, jwtService *service.JWTService, verificationService *service.EmailVerificationService, loginFlow *LoginFlow, revocation *service.TokenRevocationService) *AuthHandler {
 	return &AuthHandler{
 		userService: userService,
 		jwtService:  jwtService,
+		verificationService: verificationService,
+		loginFlow:   loginFlow,
+		revocation:  revocation,
 	}
 }
 
@@ -44,29 +53,33 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 	res, err := h.userService.RegisterUser(context.Background(), req)
 	if err != nil {
-		// cek apakah err adalah ValidatonErrors
-		var ve validator.ValidationErrors
-		if errors.As(err, &ve) {
+		// cek apakah err adalah ValidatonErrors atau pelanggaran kebijakan password
+		if fieldErrors, ok := utils.FieldErrors(err); ok {
 			// terjemahkan ke map
-			fieldErrors := utils.ValidationErrorsToMap(ve)
 			writeJSON(w, http.StatusBadRequest, fieldErrors)
 			return
 		}
+		if errors.Is(err, service.ErrEmailAlreadyExists) {
+			writeJSON(w, http.StatusConflict, map[string]string{"email": err.Error()})
+			return
+		}
 		// error lain
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	// Kirim link verifikasi email; jika gagal, user masih bisa meminta kirim ulang
+	if uid, err := uuid.Parse(res.ID); err == nil {
+		_ = h.verificationService.SendVerification(r.Context(), uid)
+	}
 	writeJSON(w, http.StatusCreated, res)
 }
 
-// Login menangani POST /auth/login.
 // Login menangani POST /auth/login.
 // Flow:
 // 1) Validasi kredensial via userService.LoginUser
-// 2) Generate AT & RT via jwtService
-// 3) Simpan RT (hash) ke DB via userService.SaveRefreshToken
-// 4) Set cookie HttpOnly untuk AT & RT
-// 5) Kembalikan data user (tanpa token)
+// 2) Serahkan ke LoginFlow: minta kode 2FA jika aktif/wajib,
+//    atau terbitkan sesi (RT disimpan ke DB, AT membawa sid) dan set cookie HttpOnly
+// 3) Kembalikan data user (tanpa token) atau mfa_token untuk langkah kedua
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
     var req dto.LoginRequest
 	// Dekode body JSON ke struct LoginRequest
@@ -76,7 +89,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
     }
 
 	// Panggil service untuk login (cek email/password)
-    res, err := h.userService.LoginUser(context.Background(), req)
+    res, err := h.userService.LoginUser(r.Context(), req, clientIP(r))
     if err != nil {
         // Jika error adalah validasi field
         var ve validator.ValidationErrors
@@ -86,82 +99,49 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
             writeJSON(w, http.StatusBadRequest, fieldErrors)
             return
         }
-        // Jika error bukan validasi (contoh: email atau password salah)
-        writeJSON(w, http.StatusUnauthorized, map[string]string{
-			"general": err.Error(), // misalnya: "email atau password salah"
-		})
+        // Akun/IP dikunci sementara atau masih dalam jeda progresif
+        var throttled *service.LoginThrottledError
+        if errors.As(err, &throttled) {
+            w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+            writeJSON(w, http.StatusTooManyRequests, map[string]string{"general": err.Error()})
+            return
+        }
+        // Email belum diverifikasi (EMAIL_VERIFICATION_MODE=login)
+        if errors.Is(err, service.ErrEmailNotVerified) {
+            writeJSON(w, http.StatusForbidden, map[string]string{"general": err.Error()})
+            return
+        }
+        // Akun ditangguhkan admin
+        if errors.Is(err, service.ErrAccountSuspended) {
+            writeJSON(w, http.StatusForbidden, map[string]string{"general": err.Error()})
+            return
+        }
+        // Kredensial salah: pesan seragam "email atau password salah"
+        if errors.Is(err, service.ErrInvalidCredentials) {
+            writeJSON(w, http.StatusUnauthorized, map[string]string{"general": err.Error()})
+            return
+        }
+        http.Error(w, "cannot process login", http.StatusInternalServerError)
         return
     }
 
-	// Ambil ID & Role dari DTO
+	// Ambil user domain (butuh RoleID untuk AT)
 	uid, err := uuid.Parse(res.User.ID) // asumsi dto.UserResponse.ID berupa string UUID
 	if err != nil { http.Error(w, "invalid user id", http.StatusInternalServerError); return }
 
 	uDomain, err := h.userService.GetUserByID(r.Context(), uid)
 	if err != nil { http.Error(w, "user not found", http.StatusInternalServerError); return }
-	rid := uDomain.RoleID
-
-
-    // --- Generate Access Token & Refresh Token ---
-	atStr, atExp, err := h.jwtService.GenerateAccessToken(uid, rid)
-	if err != nil { http.Error(w, "cannot issue access token", http.StatusInternalServerError); return }
-
-	rtStr, rtExp, err := h.jwtService.GenerateRefreshToken(uid)
-	if err != nil { http.Error(w, "cannot issue refresh token", http.StatusInternalServerError); return }
-
-	// Ambil klaim RT (untuk issuedAt, expiresAt, jti)
-	// Pars RT untuk ambil jti/issuedAt/expiresAt → simpan hash RT di DB
-	rtClaims, err := h.jwtService.VerifyRefreshToken(rtStr)
-	if err != nil { 
-		http.Error(w, "invalid refresh token claims", http.StatusInternalServerError); return 
-	}
-
-	issuedAt := time.Unix(rtClaims.IssuedAt.Unix(), 0)
-	expiresAt := time.Unix(rtClaims.ExpiresAt.Unix(), 0)
-	jti := rtClaims.ID
-
-	// Simpan hash RT ke DB (best practice: jangan simpan plaintext)
-	if err := h.userService.SaveRefreshToken(r.Context(), uid, rtStr, issuedAt, expiresAt, jti); err != nil {
-    	http.Error(w, "cannot persist refresh token", http.StatusInternalServerError)
-    	return
-	}
-
-	// --- Set cookie HttpOnly untuk AT & RT ---
-	// NOTE: Secure=false untuk dev HTTP lokal; set true saat produksi (HTTPS)
-	http.SetCookie(w, &http.Cookie{
-		Name:     "access_token",
-		Value:    atStr,
-		Path:     "/",
-		Expires:  atExp,
-		MaxAge:   int(time.Until(atExp).Seconds()),
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		Secure:   false, // true di produksi (HTTPS)
-	})
-	http.SetCookie(w, &http.Cookie{
-		Name:     "refresh_token",
-		Value:    rtStr,
-		Path:     "/auth/refresh", // cakupan sempit; bisa "/" jika diinginkan
-		Expires:  rtExp,
-		MaxAge:   int(time.Until(rtExp).Seconds()),
-		HttpOnly: true,
-		SameSite: http.SameSiteStrictMode,
-		Secure:   false, // true di produksi (HTTPS)
-	})
 
-	// Kembalikan info user (tanpa token di body)
-	writeJSON(w, http.StatusOK, map[string]any{
-		"user":    res.User,
-		"message": "login success",
-	})
+	// --- Tantangan 2FA atau terbitkan sesi baru (RT family baru + AT) ---
+	h.loginFlow.Complete(w, r, uDomain, service.FirstFactorPassword)
 }
 
 // Refresh menangani POST /auth/refresh.
 // Flow:
 // 1) Ambil refresh_token dari cookie
-// 2) Verifikasi JWT RT + cek DB (not revoked, not expired)
+// 2) Verifikasi JWT RT + cek DB (not revoked, not expired, deteksi reuse)
 // 3) Generate AT baru + RT baru (rotasi RT)
-// 4) Revoke RT lama, simpan RT baru
+// 4) Revoke RT lama, simpan RT baru dalam family yang sama
 // 5) Set cookie baru
 func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	rtCookie, err := r.Cookie("refresh_token")
@@ -170,22 +150,22 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verifikasi RT di JWT & DB (dapatkan user & RT-ID lama)
-	user, oldRTID, err := h.userService.VerifyRefreshTokenDB(r.Context(), rtCookie.Value)
+	// Verifikasi RT di JWT & DB (dapatkan user & RT lama)
+	user, oldRT, err := h.userService.VerifyRefreshTokenDB(r.Context(), rtCookie.Value)
 	if err != nil {
-		http.Error(w, "invalid refresh token", http.StatusUnauthorized)
+		h.writeRefreshError(w, err)
 		return
 	}
 
-	// Buat AT & RT baru
-	atStr, atExp, err := h.jwtService.GenerateAccessToken(user.ID, user.RoleID)
+	// Buat RT baru; AT baru tetap membawa sid = family RT (sesi yang sama)
+	newRTStr, _, err := h.jwtService.GenerateRefreshToken(user.ID)
 	if err != nil {
-		http.Error(w, "cannot issue access token", http.StatusInternalServerError)
+		http.Error(w, "cannot issue refresh token", http.StatusInternalServerError)
 		return
 	}
-	newRTStr, _, err := h.jwtService.GenerateRefreshToken(user.ID)
+	atClaims, atStr, err := h.jwtService.GenerateAccessToken(user.ID, user.RoleID, oldRT.FamilyID)
 	if err != nil {
-		http.Error(w, "cannot issue refresh token", http.StatusInternalServerError)
+		http.Error(w, "cannot issue access token", http.StatusInternalServerError)
 		return
 	}
 
@@ -199,86 +179,84 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	newExpiresAt := time.Unix(newRTClaims.ExpiresAt.Unix(), 0)
 	newJTI := newRTClaims.ID
 
-	// Rotasi RT: revoke RT lama, simpan RT baru
-	if err := h.userService.RotateRefreshToken(r.Context(), user, *oldRTID, newRTStr, newIssuedAt, newExpiresAt, newJTI); err != nil {
-		http.Error(w, "cannot rotate refresh token", http.StatusInternalServerError)
+	// Rotasi RT: revoke RT lama, simpan RT baru (perangkat & waktu pakai ikut diperbarui)
+	if err := h.userService.RotateRefreshToken(r.Context(), user, oldRT, newRTStr, newIssuedAt, newExpiresAt, newJTI, sessionMeta(r)); err != nil {
+		h.writeRefreshError(w, err)
+		return
+	}
+	// Catat jti AT baru pada RT baru agar ikut dicabut saat sesi dicabut
+	if err := h.userService.RecordAccessToken(r.Context(), oldRT.FamilyID, atClaims.ID); err != nil {
+		http.Error(w, "cannot persist access token", http.StatusInternalServerError)
 		return
 	}
 
-	// Set cookie baru
-	http.SetCookie(w, &http.Cookie{
-		Name:     "access_token",
-		Value:    atStr,
-		Path:     "/",
-		Expires:  atExp,
-		MaxAge:   int(time.Until(atExp).Seconds()),
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		Secure:   false, // true di produksi
-	})
-	http.SetCookie(w, &http.Cookie{
-		Name:     "refresh_token",
-		Value:    newRTStr,
-		Path:     "/auth/refresh",
-		Expires:  newExpiresAt,
-		MaxAge:   int(time.Until(newExpiresAt).Seconds()),
-		HttpOnly: true,
-		SameSite: http.SameSiteStrictMode,
-		Secure:   false, // true di produksi
-	})
+	// Set cookie baru (AT, RT, dan token CSRF untuk sesi yang sama)
+	if err := h.loginFlow.setSessionCookies(w, oldRT.FamilyID, atStr, atClaims.ExpiresAt.Time, newRTStr, newExpiresAt); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	// Tidak perlu body; 204 cukup
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// writeRefreshError memetakan error rotasi RT ke status HTTP.
+// - ErrRefreshTokenConcurrent → 409: tab lain sudah merotasi RT; cookie terbaru sudah ada di browser, cukup ulangi request.
+// - ErrRefreshTokenReused → 401 + hapus cookie: family dicabut karena indikasi token bocor.
+// - ErrAccountSuspended → 403 + hapus cookie: akun ditangguhkan admin.
+func (h *AuthHandler) writeRefreshError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrRefreshTokenConcurrent):
+		http.Error(w, "refresh token already rotated", http.StatusConflict)
+	case errors.Is(err, service.ErrRefreshTokenReused):
+		clearAuthCookies(w)
+		http.Error(w, "invalid refresh token", http.StatusUnauthorized)
+	case errors.Is(err, service.ErrAccountSuspended):
+		clearAuthCookies(w)
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, "invalid refresh token", http.StatusUnauthorized)
+	}
+}
+
 // Logout menangani POST /auth/logout.
 // Flow:
 // - Hapus cookie AT & RT (expire).
-// - (Opsional) Revoke semua RT milik user di DB agar logout dari semua sesi.
+// - Revoke RT milik sesi saat ini (klaim sid) sehingga perangkat lain tetap login.
+// - Masukkan jti AT ke denylist agar AT tidak bisa dipakai lagi walau belum kedaluwarsa.
+//   Untuk keluar dari perangkat lain gunakan POST /auth/sessions/logout-others.
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
-	// Coba identifikasi user dari AT untuk revoke semua RT (opsional)
+	// Coba identifikasi sesi dari AT untuk revoke RT-nya
 	if atCookie, err := r.Cookie("access_token"); err == nil && atCookie.Value != "" {
 		if claims, err := h.jwtService.VerifyAccessToken(atCookie.Value); err == nil {
-			_ = h.userService.RevokeAllUserTokens(r.Context(), claims.UserID)
+			_, _ = h.userService.RevokeSession(r.Context(), claims.UserID, claims.SessionID)
+			_ = h.revocation.RevokeToken(r.Context(), claims)
 		}
 	}
 
-	// Hapus cookie dengan MaxAge negatif
-	del := func(name, path string) {
-		http.SetCookie(w, &http.Cookie{
-			Name:     name,
-			Value:    "",
-			Path:     path,
-			MaxAge:   -1,
-			HttpOnly: true,
-			SameSite: http.SameSiteLaxMode,
-			Secure:   false, // true di produksi
-		})
-	}
-	del("access_token", "/")
-	del("refresh_token", "/auth/refresh")
+	clearAuthCookies(w)
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
 // Me menangani GET /auth/me.
-// Verifikasi AT dari cookie lalu kembalikan info user.
+// Dipasang di belakang Authenticator; principal diambil dari context lalu kembalikan info user.
 // Berguna untuk FE mengecek status login tanpa menyentuh cookie secara langsung.
 func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
-	atCookie, err := r.Cookie("access_token")
-	if err != nil || atCookie.Value == "" {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
-	claims, err := h.jwtService.VerifyAccessToken(atCookie.Value)
+	user, err := h.userService.GetUserByID(r.Context(), principal.UserID)
 	if err != nil {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
-	user, err := h.userService.GetUserByID(r.Context(), claims.UserID)
-	if err != nil {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
-		return
+	res := map[string]any{"user": user}
+	// Front-end menampilkan banner selama admin meng-impersonate user ini
+	if principal.Impersonating() {
+		res["impersonator_id"] = principal.ActorID.String()
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"user": user})
+	writeJSON(w, http.StatusOK, res)
 }
\ No newline at end of file
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+)
+
+// newRefreshFixture menerbitkan satu sesi untuk user baru dan mengembalikan handler beserta cookie RT-nya.
+func newRefreshFixture(t *testing.T, grace time.Duration) (*AuthHandler, *testServices, *http.Cookie) {
+	t.Helper()
+	cfg := testConfig(t)
+	cfg.RefreshReuseGrace = grace
+	ts := newTestServices(t, cfg)
+	user := &domain.User{ID: uuid.New(), Name: "Budi", Email: "budi@example.com", RoleID: uuid.New()}
+	if err := ts.users.CreateUser(t.Context(), user); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	if err := ts.loginFlow.issueSession(rec, httptest.NewRequest(http.MethodPost, "/auth/login", nil), user); err != nil {
+		t.Fatalf("issueSession: %v", err)
+	}
+	return NewAuthHandler(ts.userSvc, ts.jwt, nil, ts.loginFlow, nil), ts, responseCookie(t, rec, "refresh_token")
+}
+
+func refresh(h *AuthHandler, rt *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", nil)
+	req.AddCookie(rt)
+	rec := httptest.NewRecorder()
+	h.Refresh(rec, req)
+	return rec
+}
+
+func responseCookie(t *testing.T, rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	t.Helper()
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("cookie %s tidak diset", name)
+	return nil
+}
+
+// sessionID mengembalikan ID family sesi baru, yaitu jti RT pertama.
+func sessionID(t *testing.T, ts *testServices, rt *http.Cookie) uuid.UUID {
+	t.Helper()
+	claims, err := ts.jwt.VerifyRefreshToken(rt.Value)
+	if err != nil {
+		t.Fatalf("VerifyRefreshToken: %v", err)
+	}
+	return uuid.MustParse(claims.ID)
+}
+
+func TestRefreshConcurrentRequestsRotateOnce(t *testing.T) {
+	h, ts, rt := newRefreshFixture(t, 10*time.Second)
+	family := sessionID(t, ts, rt)
+
+	const parallel = 20
+	// Semua request membaca RT yang masih aktif sebelum ada yang merotasinya
+	ts.rts.mu.Lock()
+	ts.rts.findBarrier = newBarrier(parallel)
+	ts.rts.mu.Unlock()
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	codes := make([]int, parallel)
+	for i := range parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			codes[i] = refresh(h, rt).Code
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	rotated := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusNoContent:
+			rotated++
+		case http.StatusConflict:
+			// RT sudah dirotasi request lain dalam grace window
+		default:
+			t.Errorf("status tak terduga %d", code)
+		}
+	}
+	if rotated != 1 {
+		t.Fatalf("rotasi berhasil %d kali, want tepat 1", rotated)
+	}
+
+	var active []domain.RefreshToken
+	for _, row := range ts.rts.family(family) {
+		if !row.Revoked {
+			active = append(active, row)
+		}
+	}
+	if len(active) != 1 || active[0].ParentID == nil || *active[0].ParentID != family {
+		t.Fatalf("family harus punya tepat satu RT aktif hasil rotasi, got %+v", active)
+	}
+}
+
+func TestRefreshReplayAfterGraceRevokesFamily(t *testing.T) {
+	const grace = 50 * time.Millisecond
+	h, ts, oldRT := newRefreshFixture(t, grace)
+	family := sessionID(t, ts, oldRT)
+
+	rec := refresh(h, oldRT)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("rotasi pertama: status %d", rec.Code)
+	}
+	newRT := responseCookie(t, rec, "refresh_token")
+
+	// Replay di dalam grace window dianggap refresh bersamaan: ditolak tanpa mencabut family
+	if code := refresh(h, oldRT).Code; code != http.StatusConflict {
+		t.Fatalf("replay dalam grace: status %d, want 409", code)
+	}
+	for _, row := range ts.rts.family(family) {
+		if row.ID != family && row.Revoked {
+			t.Fatal("replay dalam grace tidak boleh mencabut RT terbaru")
+		}
+	}
+
+	time.Sleep(2 * grace)
+	rec = refresh(h, oldRT)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("replay setelah grace: status %d, want 401", rec.Code)
+	}
+	if c := responseCookie(t, rec, "refresh_token"); c.MaxAge >= 0 {
+		t.Fatalf("cookie RT harus dihapus, MaxAge %d", c.MaxAge)
+	}
+	for _, row := range ts.rts.family(family) {
+		if !row.Revoked {
+			t.Fatalf("RT %s dalam family masih aktif setelah replay", row.ID)
+		}
+	}
+
+	// RT terbaru milik family yang dicabut juga tidak bisa dipakai lagi
+	time.Sleep(2 * grace)
+	if code := refresh(h, newRT).Code; code != http.StatusUnauthorized {
+		t.Fatalf("refresh dengan RT terbaru setelah family dicabut: status %d, want 401", code)
+	}
+}
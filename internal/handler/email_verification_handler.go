@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"github.com/itujun/project-ecommerce-go-next/internal/utils"
+)
+
+// EmailVerificationHandler menangani verifikasi email dan kirim ulang link verifikasi.
+type EmailVerificationHandler struct {
+	verificationService *service.EmailVerificationService
+}
+
+// NewEmailVerificationHandler membuat instance baru EmailVerificationHandler.
+func NewEmailVerificationHandler(verificationService *service.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{verificationService: verificationService}
+}
+
+// VerifyEmail menangani POST /auth/verify-email.
+func (h *EmailVerificationHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req dto.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.verificationService.Verify(r.Context(), req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			writeJSON(w, http.StatusBadRequest, utils.ValidationErrorsToMap(ve))
+			return
+		}
+		if errors.Is(err, service.ErrInvalidVerificationToken) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"general": err.Error()})
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "email berhasil diverifikasi"})
+}
+
+// ResendVerification menangani POST /auth/verify-email/resend.
+// Respons selalu sama untuk email valid agar tidak membocorkan akun mana yang terdaftar.
+func (h *EmailVerificationHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.verificationService.Resend(r.Context(), req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			writeJSON(w, http.StatusBadRequest, utils.ValidationErrorsToMap(ve))
+			return
+		}
+		http.Error(w, "cannot send verification email", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"message": "jika email terdaftar dan belum diverifikasi, link verifikasi telah dikirim",
+	})
+}
@@ -0,0 +1,334 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/password"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"go.uber.org/zap"
+)
+
+// testConfig adalah konfigurasi minimal untuk menerbitkan AT, RT, dan token CSRF di test.
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return &config.Config{
+		JWTKeyDir:         t.TempDir(),
+		JWTSigningAlg:     service.AlgEdDSA,
+		JWTKeyRotation:    time.Hour,
+		JWTRefreshSecret:  "test-refresh-secret",
+		CSRFSecret:        "test-csrf-secret",
+		AccessTTL:         15 * time.Minute,
+		RefreshTTL:        time.Hour,
+		RefreshReuseGrace: 10 * time.Second,
+	}
+}
+
+// testServices merangkai JWTService, UserService, MFAService, dan LoginFlow di atas repository in-memory.
+type testServices struct {
+	cfg       *config.Config
+	users     *memUsers
+	roles     *memRoles
+	rts       *memRefreshTokens
+	jwt       *service.JWTService
+	userSvc   *service.UserService
+	loginFlow *LoginFlow
+}
+
+func newTestServices(t *testing.T, cfg *config.Config) *testServices {
+	t.Helper()
+	keys, err := service.NewKeyManager(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewKeyManager: %v", err)
+	}
+	jwtService := service.NewJWTService(cfg, keys)
+	users := newMemUsers()
+	roles := &memRoles{buyer: domain.Role{ID: uuid.New(), Name: "buyer"}}
+	rts := newMemRefreshTokens()
+	hasher := password.New(&password.Bcrypt{Cost: 4})
+	userSvc := service.NewUserService(users, roles, rts, jwtService, nil, hasher, nil, cfg, zap.NewNop())
+	mfaSvc := service.NewMFAService(memMFA{}, users, nil, nil, cfg, zap.NewNop())
+	return &testServices{
+		cfg:       cfg,
+		users:     users,
+		roles:     roles,
+		rts:       rts,
+		jwt:       jwtService,
+		userSvc:   userSvc,
+		loginFlow: NewLoginFlow(userSvc, jwtService, mfaSvc, service.NewCSRFService(cfg)),
+	}
+}
+
+// memUsers adalah UserRepository in-memory; method yang tidak dipakai test akan panic.
+type memUsers struct {
+	repository.UserRepository
+
+	mu   sync.Mutex
+	byID map[uuid.UUID]*domain.User
+}
+
+func newMemUsers() *memUsers {
+	return &memUsers{byID: make(map[uuid.UUID]*domain.User)}
+}
+
+// add menyimpan user apa adanya (mis. user lokal yang sudah ada sebelum test).
+func (m *memUsers) add(user *domain.User) *domain.User {
+	if err := m.CreateUser(context.Background(), user); err != nil {
+		panic(err)
+	}
+	return user
+}
+
+// all mengembalikan salinan semua user.
+func (m *memUsers) all() []domain.User {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	users := make([]domain.User, 0, len(m.byID))
+	for _, u := range m.byID {
+		users = append(users, *u)
+	}
+	return users
+}
+
+func (m *memUsers) CreateUser(_ context.Context, user *domain.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.byID {
+		if u.Email == user.Email {
+			return repository.ErrDuplicateEmail
+		}
+	}
+	clone := *user
+	m.byID[user.ID] = &clone
+	return nil
+}
+
+func (m *memUsers) GetUserByID(_ context.Context, id uuid.UUID) (*domain.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.byID[id]
+	if !ok {
+		return nil, errors.New("user tidak ditemukan")
+	}
+	clone := *u
+	return &clone, nil
+}
+
+func (m *memUsers) GetUserByEmail(_ context.Context, email string) (*domain.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.byID {
+		if u.Email == email {
+			clone := *u
+			return &clone, nil
+		}
+	}
+	return nil, errors.New("user tidak ditemukan")
+}
+
+// memRoles hanya mengenal role buyer (role default user baru).
+type memRoles struct {
+	repository.RoleRepository
+	buyer domain.Role
+}
+
+func (m *memRoles) GetRoleByName(_ context.Context, name string) (*domain.Role, error) {
+	if name != m.buyer.Name {
+		return nil, errors.New("role tidak ditemukan")
+	}
+	role := m.buyer
+	return &role, nil
+}
+
+// memMFA menganggap tidak ada user yang mengaktifkan TOTP.
+type memMFA struct {
+	repository.MFARepository
+}
+
+func (memMFA) GetByUserID(context.Context, uuid.UUID) (*domain.UserMFA, error) { return nil, nil }
+
+// memRefreshTokens adalah RefreshTokenRepository in-memory. Setiap method memegang mutex sehingga
+// RevokeIfActive bersifat atomik seperti UPDATE ... WHERE revoked = false di database.
+type memRefreshTokens struct {
+	mu   sync.Mutex
+	rows map[uuid.UUID]*domain.RefreshToken
+	// findBarrier, jika diset, menahan FindByID sampai sejumlah pemanggil tiba agar request paralel
+	// benar-benar bertumpuk di antara verifikasi RT dan rotasinya.
+	findBarrier *barrier
+}
+
+var _ repository.RefreshTokenRepository = (*memRefreshTokens)(nil)
+
+func newMemRefreshTokens() *memRefreshTokens {
+	return &memRefreshTokens{rows: make(map[uuid.UUID]*domain.RefreshToken)}
+}
+
+func (m *memRefreshTokens) Save(_ context.Context, rt *domain.RefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clone := *rt
+	m.rows[rt.ID] = &clone
+	return nil
+}
+
+func (m *memRefreshTokens) FindByID(_ context.Context, id uuid.UUID) (*domain.RefreshToken, error) {
+	m.mu.Lock()
+	rt, ok := m.rows[id]
+	var clone domain.RefreshToken
+	if ok {
+		clone = *rt
+	}
+	findBarrier := m.findBarrier
+	m.mu.Unlock()
+	if findBarrier != nil {
+		findBarrier.wait()
+	}
+	if !ok {
+		return nil, errors.New("refresh token tidak ditemukan")
+	}
+	return &clone, nil
+}
+
+func (m *memRefreshTokens) Revoke(ctx context.Context, id uuid.UUID) error {
+	_, err := m.RevokeIfActive(ctx, id)
+	return err
+}
+
+func (m *memRefreshTokens) RevokeIfActive(_ context.Context, id uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rt, ok := m.rows[id]
+	if !ok || rt.Revoked {
+		return false, nil
+	}
+	revoke(rt, time.Now())
+	return true, nil
+}
+
+func (m *memRefreshTokens) RevokeFamily(_ context.Context, familyID uuid.UUID) error {
+	m.revokeWhere(func(rt *domain.RefreshToken) bool { return rt.FamilyID == familyID })
+	return nil
+}
+
+func (m *memRefreshTokens) SetAccessTokenID(_ context.Context, familyID uuid.UUID, jti string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, rt := range m.rows {
+		if rt.FamilyID == familyID && !rt.Revoked {
+			rt.AccessTokenID = jti
+		}
+	}
+	return nil
+}
+
+func (m *memRefreshTokens) RevokeAllByUser(_ context.Context, userID uuid.UUID) error {
+	m.revokeWhere(func(rt *domain.RefreshToken) bool { return rt.UserID == userID })
+	return nil
+}
+
+func (m *memRefreshTokens) RevokeAllByUserExceptFamily(_ context.Context, userID, familyID uuid.UUID) error {
+	m.revokeWhere(func(rt *domain.RefreshToken) bool { return rt.UserID == userID && rt.FamilyID != familyID })
+	return nil
+}
+
+func (m *memRefreshTokens) ListActiveByUser(_ context.Context, userID uuid.UUID) ([]domain.RefreshToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	var active []domain.RefreshToken
+	for _, rt := range m.rows {
+		if rt.UserID == userID && !rt.Revoked && rt.ExpiresAt.After(now) {
+			active = append(active, *rt)
+		}
+	}
+	return active, nil
+}
+
+func (m *memRefreshTokens) Update(_ context.Context, rt *domain.RefreshToken) error {
+	return m.Save(context.Background(), rt)
+}
+
+func (m *memRefreshTokens) DeleteExpiredBefore(_ context.Context, before time.Time, limit int) (int64, error) {
+	return m.deleteWhere(limit, func(rt *domain.RefreshToken) bool { return rt.ExpiresAt.Before(before) })
+}
+
+func (m *memRefreshTokens) DeleteRevokedBefore(_ context.Context, before time.Time, limit int) (int64, error) {
+	return m.deleteWhere(limit, func(rt *domain.RefreshToken) bool { return rt.RevokedAt != nil && rt.RevokedAt.Before(before) })
+}
+
+// family mengembalikan salinan semua RT dalam satu family.
+func (m *memRefreshTokens) family(familyID uuid.UUID) []domain.RefreshToken {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var rows []domain.RefreshToken
+	for _, rt := range m.rows {
+		if rt.FamilyID == familyID {
+			rows = append(rows, *rt)
+		}
+	}
+	return rows
+}
+
+func (m *memRefreshTokens) revokeWhere(match func(*domain.RefreshToken) bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for _, rt := range m.rows {
+		if !rt.Revoked && match(rt) {
+			revoke(rt, now)
+		}
+	}
+}
+
+func (m *memRefreshTokens) deleteWhere(limit int, match func(*domain.RefreshToken) bool) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for id, rt := range m.rows {
+		if int(n) == limit {
+			break
+		}
+		if match(rt) {
+			delete(m.rows, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func revoke(rt *domain.RefreshToken, at time.Time) {
+	rt.Revoked = true
+	rt.RevokedAt = &at
+}
+
+// barrier melepas semua goroutine setelah n goroutine memanggil wait, atau setelah timeout
+// agar test tidak macet jika sebagian request tidak pernah sampai.
+type barrier struct {
+	mu      sync.Mutex
+	n       int
+	release chan struct{}
+}
+
+func newBarrier(n int) *barrier {
+	return &barrier{n: n, release: make(chan struct{})}
+}
+
+func (b *barrier) wait() {
+	b.mu.Lock()
+	b.n--
+	if b.n == 0 {
+		close(b.release)
+	}
+	b.mu.Unlock()
+	select {
+	case <-b.release:
+	case <-time.After(2 * time.Second):
+	}
+}
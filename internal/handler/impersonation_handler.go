@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"github.com/itujun/project-ecommerce-go-next/internal/utils"
+)
+
+// ImpersonationHandler menangani impersonasi user oleh admin dan daftar audit log.
+type ImpersonationHandler struct {
+	impersonationService *service.ImpersonationService
+	auditService         *service.AuditService
+}
+
+// NewImpersonationHandler membuat instance baru ImpersonationHandler.
+func NewImpersonationHandler(impersonationService *service.ImpersonationService, auditService *service.AuditService) *ImpersonationHandler {
+	return &ImpersonationHandler{impersonationService: impersonationService, auditService: auditService}
+}
+
+// Start menangani POST /admin/users/{id}/impersonate.
+// AT impersonasi dikembalikan di body untuk dipakai sebagai Bearer; cookie sesi admin tidak disentuh.
+func (h *ImpersonationHandler) Start(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := adminTarget(w, r)
+	if !ok {
+		return
+	}
+	var req dto.StartImpersonationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	res, err := h.impersonationService.Start(r.Context(), principal.UserID, id, req, clientIP(r), r.UserAgent())
+	if err != nil {
+		writeImpersonationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, res)
+}
+
+// Stop menangani POST /auth/impersonation/stop dengan AT impersonasi; token langsung dicabut.
+func (h *ImpersonationHandler) Stop(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !principal.Impersonating() {
+		http.Error(w, "bukan sesi impersonasi", http.StatusBadRequest)
+		return
+	}
+	if err := h.impersonationService.Stop(r.Context(), principal.ActorID, principal.UserID, principal.TokenID, principal.ExpiresAt, clientIP(r), r.UserAgent()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListAuditLogs menangani GET /admin/audit-logs?actor_id=&user_id=&action=&page=&page_size=.
+func (h *ImpersonationHandler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := dto.ListAuditLogsQuery{
+		ActorID:  q.Get("actor_id"),
+		UserID:   q.Get("user_id"),
+		Action:   q.Get("action"),
+		Page:     1,
+		PageSize: defaultUserPageSize,
+	}
+	if !readPagination(w, q, &query.Page, &query.PageSize) {
+		return
+	}
+	res, err := h.auditService.List(r.Context(), query)
+	if err != nil {
+		writeImpersonationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// writeImpersonationError memetakan error ImpersonationService/AuditService ke status HTTP.
+func writeImpersonationError(w http.ResponseWriter, err error) {
+	var ve validator.ValidationErrors
+	switch {
+	case errors.As(err, &ve):
+		writeJSON(w, http.StatusBadRequest, utils.ValidationErrorsToMap(ve))
+	case errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrCannotModifySelf), errors.Is(err, service.ErrCannotImpersonate):
+		writeJSON(w, http.StatusConflict, map[string]string{"general": err.Error()})
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+)
+
+// JWKSHandler mempublikasikan kunci publik untuk verifikasi access token.
+type JWKSHandler struct {
+	keys *service.KeyManager
+}
+
+// NewJWKSHandler membuat instance baru JWKSHandler.
+func NewJWKSHandler(keys *service.KeyManager) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// JWKS menangani GET /.well-known/jwks.json.
+// Server Next.js dan layanan internal lain memverifikasi AT dengan memilih kunci berdasarkan kid.
+func (h *JWKSHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	// Cache singkat; kunci baru baru dipakai menandatangani setelah dipublikasikan lebih lama dari max-age,
+	// dan kunci lama baru dihapus setelah token terakhir yang ditandatanganinya kedaluwarsa
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(service.JWKSCacheMaxAge.Seconds())))
+	writeJSON(w, http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+)
+
+// LoginFlow adalah langkah akhir semua jalur login (password, 2FA, dst.):
+// memutuskan apakah perlu tantangan 2FA atau langsung menerbitkan sesi.
+type LoginFlow struct {
+	userService *service.UserService
+	jwtService  *service.JWTService
+	mfaService  *service.MFAService
+	csrf        *service.CSRFService
+}
+
+// NewLoginFlow membuat instance baru LoginFlow.
+func NewLoginFlow(userService *service.UserService, jwtService *service.JWTService, mfaService *service.MFAService, csrf *service.CSRFService) *LoginFlow {
+	return &LoginFlow{
+		userService: userService,
+		jwtService:  jwtService,
+		mfaService:  mfaService,
+		csrf:        csrf,
+	}
+}
+
+// Complete dipanggil setelah faktor pertama user (firstFactor, salah satu service.FirstFactor*) terverifikasi.
+// - 2FA aktif → {"mfa_required": true, "mfa_token": ...}; lanjut ke POST /auth/mfa/verify
+// - role wajib 2FA tapi belum enrollment → {"mfa_enrollment_required": true, "mfa_token": ...}
+// - login kode SMS untuk akun yang 2FA-nya hanya SMS → 403 (tidak ada faktor kedua yang berbeda)
+// - selain itu sesi langsung diterbitkan dan cookie diset.
+func (f *LoginFlow) Complete(w http.ResponseWriter, r *http.Request, user *domain.User, firstFactor string) {
+	challenge, mfaToken, err := f.challenge(r, user, firstFactor)
+	if errors.Is(err, service.ErrSMSSecondFactor) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"general": err.Error()})
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if challenge == service.MFAChallengeNone {
+		f.finish(w, r, user)
+		return
+	}
+	key := "mfa_required"
+	if challenge == service.MFAChallengeEnroll {
+		key = "mfa_enrollment_required"
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		key:         true,
+		"mfa_token": mfaToken,
+	})
+}
+
+// CompleteRedirect sama seperti Complete untuk login berbasis redirect (login sosial):
+// sesi diterbitkan lalu browser diarahkan ke target. Jika butuh 2FA, mfa_token dikirim
+// di fragment URL (#mfa=verify|enroll&mfa_token=...) agar tidak tercatat di log server.
+func (f *LoginFlow) CompleteRedirect(w http.ResponseWriter, r *http.Request, user *domain.User, firstFactor, target string) {
+	challenge, mfaToken, err := f.challenge(r, user, firstFactor)
+	if err != nil {
+		http.Redirect(w, r, target+"?error=server_error", http.StatusFound)
+		return
+	}
+	if challenge != service.MFAChallengeNone {
+		fragment := url.Values{"mfa": {challenge}, "mfa_token": {mfaToken}}
+		http.Redirect(w, r, target+"#"+fragment.Encode(), http.StatusFound)
+		return
+	}
+	if err := f.issueSession(w, r, user); err != nil {
+		code := "server_error"
+		if errors.Is(err, service.ErrAccountSuspended) {
+			code = "account_suspended"
+		}
+		http.Redirect(w, r, target+"?error="+code, http.StatusFound)
+		return
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// CompleteVerified menerbitkan sesi tanpa tantangan 2FA untuk faktor yang sudah memenuhi dua faktor
+// sendiri, yaitu passkey dengan user verification (perangkat + PIN/biometrik).
+func (f *LoginFlow) CompleteVerified(w http.ResponseWriter, r *http.Request, user *domain.User) {
+	f.finish(w, r, user)
+}
+
+// challenge menentukan tantangan 2FA dan menerbitkan mfa_token (beserta faktor pertamanya) jika diperlukan.
+func (f *LoginFlow) challenge(r *http.Request, user *domain.User, firstFactor string) (challenge, mfaToken string, err error) {
+	challenge, err = f.mfaService.Challenge(r.Context(), user, firstFactor)
+	if errors.Is(err, service.ErrSMSSecondFactor) {
+		return "", "", err
+	}
+	if err != nil {
+		return "", "", errors.New("cannot check 2FA status")
+	}
+	if challenge == service.MFAChallengeNone {
+		return challenge, "", nil
+	}
+	mfaToken, _, err = f.jwtService.GenerateMFAToken(user.ID, challenge, firstFactor)
+	if err != nil {
+		return "", "", errors.New("cannot issue mfa token")
+	}
+	return challenge, mfaToken, nil
+}
+
+// finish menerbitkan sesi lalu mengembalikan info user (tanpa token di body).
+func (f *LoginFlow) finish(w http.ResponseWriter, r *http.Request, user *domain.User) {
+	if err := f.issueSession(w, r, user); err != nil {
+		if errors.Is(err, service.ErrAccountSuspended) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"general": err.Error()})
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"user":    userResponse(user),
+		"message": "login success",
+	})
+}
+
+// issueSession menerbitkan sesi baru untuk user yang sudah terautentikasi:
+// RT menjadi akar family baru (ID family = ID sesi), AT membawa klaim sid, lalu keduanya diset sebagai cookie.
+// Akun yang ditangguhkan di tengah login (mis. saat menunggu kode 2FA) ditolak di sini.
+func (f *LoginFlow) issueSession(w http.ResponseWriter, r *http.Request, user *domain.User) error {
+	if user.Suspended() {
+		return service.ErrAccountSuspended
+	}
+	rtStr, _, err := f.jwtService.GenerateRefreshToken(user.ID)
+	if err != nil {
+		return errors.New("cannot issue refresh token")
+	}
+
+	// Pars RT untuk ambil jti/issuedAt/expiresAt → simpan hash RT di DB
+	rtClaims, err := f.jwtService.VerifyRefreshToken(rtStr)
+	if err != nil {
+		return errors.New("invalid refresh token claims")
+	}
+	issuedAt := time.Unix(rtClaims.IssuedAt.Unix(), 0)
+	expiresAt := time.Unix(rtClaims.ExpiresAt.Unix(), 0)
+	jti := rtClaims.ID
+
+	// Simpan hash RT ke DB (best practice: jangan simpan plaintext)
+	if err := f.userService.SaveRefreshToken(r.Context(), user.ID, rtStr, issuedAt, expiresAt, jti, sessionMeta(r)); err != nil {
+		return errors.New("cannot persist refresh token")
+	}
+
+	sessionID := uuid.MustParse(jti)
+	atClaims, atStr, err := f.jwtService.GenerateAccessToken(user.ID, user.RoleID, sessionID)
+	if err != nil {
+		return errors.New("cannot issue access token")
+	}
+	// Catat jti AT pada sesi agar ikut dicabut saat sesi dicabut (DELETE /auth/sessions/{id})
+	if err := f.userService.RecordAccessToken(r.Context(), sessionID, atClaims.ID); err != nil {
+		return errors.New("cannot persist access token")
+	}
+
+	return f.setSessionCookies(w, sessionID, atStr, atClaims.ExpiresAt.Time, rtStr, expiresAt)
+}
+
+// setSessionCookies menulis cookie AT & RT beserta token CSRF baru untuk sesi (login dan refresh).
+func (f *LoginFlow) setSessionCookies(w http.ResponseWriter, sessionID uuid.UUID, atStr string, atExp time.Time, rtStr string, rtExp time.Time) error {
+	csrfToken, err := f.csrf.Generate(sessionID)
+	if err != nil {
+		return errors.New("cannot issue csrf token")
+	}
+	setAuthCookies(w, atStr, atExp, rtStr, rtExp)
+	http.SetCookie(w, &http.Cookie{
+		Name:     service.CSRFCookieName,
+		Value:    csrfToken,
+		Path:     "/",
+		Expires:  rtExp,
+		MaxAge:   int(time.Until(rtExp).Seconds()),
+		HttpOnly: false, // dibaca front-end lalu dikirim di header X-CSRF-Token
+		SameSite: http.SameSiteLaxMode,
+		Secure:   false, // true di produksi (HTTPS)
+	})
+	return nil
+}
+
+// userResponse mengubah domain.User menjadi DTO respons (tanpa hash password).
+func userResponse(user *domain.User) dto.UserResponse {
+	return dto.UserResponse{
+		ID:    user.ID.String(),
+		Name:  user.Name,
+		Email: user.Email,
+		Role:  user.Role.Name,
+	}
+}
+
+// setAuthCookies menulis cookie HttpOnly untuk AT & RT.
+// NOTE: Secure=false untuk dev HTTP lokal; set true saat produksi (HTTPS)
+func setAuthCookies(w http.ResponseWriter, atStr string, atExp time.Time, rtStr string, rtExp time.Time) {
+	setAccessTokenCookie(w, atStr, atExp)
+	http.SetCookie(w, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    rtStr,
+		Path:     "/auth/refresh", // cakupan sempit; bisa "/" jika diinginkan
+		Expires:  rtExp,
+		MaxAge:   int(time.Until(rtExp).Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+		Secure:   false, // true di produksi (HTTPS)
+	})
+}
+
+// setAccessTokenCookie menulis cookie HttpOnly untuk AT saja (mis. AT diterbitkan ulang tanpa rotasi RT).
+func setAccessTokenCookie(w http.ResponseWriter, atStr string, atExp time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "access_token",
+		Value:    atStr,
+		Path:     "/",
+		Expires:  atExp,
+		MaxAge:   int(time.Until(atExp).Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   false, // true di produksi (HTTPS)
+	})
+}
+
+// clearAuthCookies menghapus cookie AT, RT, dan CSRF dengan MaxAge negatif.
+func clearAuthCookies(w http.ResponseWriter) {
+	del := func(name, path string) {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    "",
+			Path:     path,
+			MaxAge:   -1,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+			Secure:   false, // true di produksi
+		})
+	}
+	del("access_token", "/")
+	del("refresh_token", "/auth/refresh")
+	http.SetCookie(w, &http.Cookie{Name: service.CSRFCookieName, Value: "", Path: "/", MaxAge: -1, SameSite: http.SameSiteLaxMode})
+}
+
+// sessionMeta mengambil info perangkat (user agent & IP) untuk dicatat pada sesi.
+func sessionMeta(r *http.Request) service.SessionMeta {
+	return service.SessionMeta{
+		UserAgent: r.UserAgent(),
+		IPAddress: clientIP(r),
+	}
+}
+
+// clientIP mengambil IP klien dari RemoteAddr (tanpa port).
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"github.com/itujun/project-ecommerce-go-next/internal/utils"
+)
+
+// MagicLinkHandler menangani login tanpa password lewat link yang dikirim ke email.
+type MagicLinkHandler struct {
+	magicLinkService *service.MagicLinkService
+	loginFlow        *LoginFlow
+	cfg              *config.Config
+}
+
+// NewMagicLinkHandler membuat instance baru MagicLinkHandler.
+func NewMagicLinkHandler(magicLinkService *service.MagicLinkService, loginFlow *LoginFlow, cfg *config.Config) *MagicLinkHandler {
+	return &MagicLinkHandler{magicLinkService: magicLinkService, loginFlow: loginFlow, cfg: cfg}
+}
+
+// Request menangani POST /auth/magic-link.
+// Respons selalu 202 dengan pesan yang sama agar tidak membocorkan akun mana yang terdaftar.
+func (h *MagicLinkHandler) Request(w http.ResponseWriter, r *http.Request) {
+	var req dto.MagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.magicLinkService.Request(r.Context(), req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			writeJSON(w, http.StatusBadRequest, utils.ValidationErrorsToMap(ve))
+			return
+		}
+		http.Error(w, "cannot process request", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"message": "jika email terdaftar, link login telah dikirim",
+	})
+}
+
+// Callback menangani GET /auth/magic-link/callback?token=... (link di email).
+// GET tidak memakai token: pemindai link & prefetch email client juga mengirim GET dan akan
+// menghanguskan link sekali pakai. Browser diarahkan ke <FRONTEND_URL>/magic-link/callback
+// dengan token di fragment URL (#token=...), lalu frontend menukarnya lewat POST /auth/magic-link/consume.
+func (h *MagicLinkHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	target := h.cfg.FrontendURL + "/magic-link/callback"
+	// Jangan biarkan token ikut terkirim ke halaman lain lewat header Referer atau tersimpan di cache
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("Cache-Control", "no-store")
+	fragment := url.Values{"token": {r.URL.Query().Get("token")}}
+	http.Redirect(w, r, target+"#"+fragment.Encode(), http.StatusFound)
+}
+
+// Consume menangani POST /auth/magic-link/consume.
+// Flow:
+// 1) Verifikasi tanda tangan & masa berlaku token, lalu tandai terpakai (sekali pakai)
+// 2) Terbitkan sesi lewat LoginFlow seperti login biasa (termasuk tantangan 2FA)
+func (h *MagicLinkHandler) Consume(w http.ResponseWriter, r *http.Request) {
+	var req dto.MagicLinkConsumeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	user, err := h.magicLinkService.Consume(r.Context(), req.Token)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidMagicLink) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"general": err.Error()})
+			return
+		}
+		http.Error(w, "cannot process request", http.StatusInternalServerError)
+		return
+	}
+	h.loginFlow.Complete(w, r, user, service.FirstFactorMagicLink)
+}
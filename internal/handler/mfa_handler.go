@@ -0,0 +1,310 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"github.com/itujun/project-ecommerce-go-next/internal/utils"
+)
+
+// MFAHandler menangani enrollment, verifikasi, dan pengelolaan 2FA (TOTP dan SMS).
+type MFAHandler struct {
+	mfaService  *service.MFAService
+	userService *service.UserService
+	jwtService  *service.JWTService
+	revocation  *service.TokenRevocationService
+	loginFlow   *LoginFlow
+}
+
+// NewMFAHandler membuat instance baru MFAHandler.
+func NewMFAHandler(mfaService *service.MFAService, userService *service.UserService, jwtService *service.JWTService, revocation *service.TokenRevocationService, loginFlow *LoginFlow) *MFAHandler {
+	return &MFAHandler{
+		mfaService:  mfaService,
+		userService: userService,
+		jwtService:  jwtService,
+		revocation:  revocation,
+		loginFlow:   loginFlow,
+	}
+}
+
+// Status menangani GET /auth/mfa.
+func (h *MFAHandler) Status(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	res, err := h.mfaService.Status(r.Context(), principal.UserID)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// Enroll menangani POST /auth/mfa/enroll.
+// User yang sudah login memakai sesinya; user yang login-nya tertahan karena role wajib 2FA
+// mengirim mfa_token dari respons login.
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	var req dto.MFAEnrollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	userID, _, ok := h.enrollingUser(r, req.MFAToken)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	res, err := h.mfaService.BeginEnrollment(r.Context(), userID)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// ConfirmEnrollment menangani POST /auth/mfa/enroll/confirm.
+// Jika enrollment memakai mfa_token, sesi langsung diterbitkan setelah 2FA aktif dan token tidak bisa dipakai lagi.
+func (h *MFAHandler) ConfirmEnrollment(w http.ResponseWriter, r *http.Request) {
+	var req dto.MFAConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	userID, token, ok := h.enrollingUser(r, req.MFAToken)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	codes, err := h.mfaService.ConfirmEnrollment(r.Context(), userID, req)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	if token == nil {
+		writeJSON(w, http.StatusOK, codes)
+		return
+	}
+	if err := h.consumeMFAToken(r, token); err != nil {
+		http.Error(w, "cannot process request", http.StatusInternalServerError)
+		return
+	}
+
+	user, err := h.userService.GetUserByID(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusInternalServerError)
+		return
+	}
+	if err := h.loginFlow.issueSession(w, r, user); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"user":           userResponse(user),
+		"recovery_codes": codes.RecoveryCodes,
+		"message":        "login success",
+	})
+}
+
+// Verify menangani POST /auth/mfa/verify (langkah kedua login).
+// Percobaan dibatasi per user oleh MFAService; setelah kode valid jti mfa_token masuk denylist
+// sehingga token yang sama tidak bisa ditukar dengan sesi kedua.
+func (h *MFAHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	var req dto.MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	claims, ok := h.verifyMFAToken(r, req.MFAToken, service.MFAPurposeVerify)
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"general": "mfa token tidak valid atau kedaluwarsa"})
+		return
+	}
+	if err := h.mfaService.Verify(r.Context(), claims.UserID, claims.FirstFactor, req); err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	if err := h.consumeMFAToken(r, claims); err != nil {
+		http.Error(w, "cannot process request", http.StatusInternalServerError)
+		return
+	}
+	user, err := h.userService.GetUserByID(r.Context(), claims.UserID)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusInternalServerError)
+		return
+	}
+	h.loginFlow.finish(w, r, user)
+}
+
+// Disable menangani DELETE /auth/mfa.
+func (h *MFAHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.mfaService.Disable(r.Context(), principal.UserID, req); err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes menangani POST /auth/mfa/recovery-codes.
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	res, err := h.mfaService.RegenerateRecoveryCodes(r.Context(), principal.UserID, req)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// SendSMSCode menangani POST /auth/mfa/sms/send.
+// Pada langkah kedua login body berisi mfa_token dari respons login; user yang sudah login
+// (mis. sebelum menonaktifkan 2FA SMS) memakai sesinya.
+func (h *MFAHandler) SendSMSCode(w http.ResponseWriter, r *http.Request) {
+	var req dto.MFASMSSendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	var userID uuid.UUID
+	if principal, ok := middleware.PrincipalFromContext(r.Context()); ok {
+		userID = principal.UserID
+	} else {
+		claims, ok := h.verifyMFAToken(r, req.MFAToken, service.MFAPurposeVerify)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"general": "mfa token tidak valid atau kedaluwarsa"})
+			return
+		}
+		// Kode SMS tidak sah sebagai faktor kedua setelah login dengan kode SMS; tidak perlu dikirim
+		if claims.FirstFactor == service.FirstFactorPhone {
+			writeMFAError(w, service.ErrSMSSecondFactor)
+			return
+		}
+		userID = claims.UserID
+	}
+	if err := h.mfaService.SendSMSCode(r.Context(), userID); err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"message": "kode 2FA telah dikirim via SMS"})
+}
+
+// EnableSMS menangani POST /auth/mfa/sms (nomor HP harus sudah diverifikasi).
+func (h *MFAHandler) EnableSMS(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.mfaService.EnableSMS(r.Context(), principal.UserID); err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DisableSMS menangani DELETE /auth/mfa/sms dengan body {"sms_code": "..."}.
+func (h *MFAHandler) DisableSMS(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.MFASMSCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.mfaService.DisableSMS(r.Context(), principal.UserID, req); err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// enrollingUser menentukan user yang sedang enrollment: dari Principal (sudah login)
+// atau dari mfa_token bertujuan "enroll". token berisi klaim mfa_token untuk kasus kedua, nil untuk yang pertama.
+func (h *MFAHandler) enrollingUser(r *http.Request, mfaToken string) (userID uuid.UUID, token *service.MFAClaims, ok bool) {
+	if principal, ok := middleware.PrincipalFromContext(r.Context()); ok {
+		return principal.UserID, nil, true
+	}
+	if mfaToken == "" {
+		return uuid.Nil, nil, false
+	}
+	claims, ok := h.verifyMFAToken(r, mfaToken, service.MFAPurposeEnroll)
+	if !ok {
+		return uuid.Nil, nil, false
+	}
+	return claims.UserID, claims, true
+}
+
+// verifyMFAToken memverifikasi mfa_token dan menolak token yang sudah ditukar dengan sesi.
+func (h *MFAHandler) verifyMFAToken(r *http.Request, tokenStr, purpose string) (*service.MFAClaims, bool) {
+	claims, err := h.jwtService.VerifyMFAToken(tokenStr, purpose)
+	if err != nil {
+		return nil, false
+	}
+	used, err := h.revocation.IsTokenIDRevoked(r.Context(), claims.ID)
+	if err != nil || used {
+		return nil, false
+	}
+	return claims, true
+}
+
+// consumeMFAToken memasukkan jti mfa_token ke denylist sampai token kedaluwarsa (sekali pakai).
+func (h *MFAHandler) consumeMFAToken(r *http.Request, claims *service.MFAClaims) error {
+	return h.revocation.RevokeTokenID(r.Context(), claims.ID, claims.UserID, claims.ExpiresAt.Time)
+}
+
+// writeMFAError memetakan error MFAService ke status HTTP.
+func writeMFAError(w http.ResponseWriter, err error) {
+	var ve validator.ValidationErrors
+	var throttled *service.LoginThrottledError
+	switch {
+	case errors.As(err, &ve):
+		writeJSON(w, http.StatusBadRequest, utils.ValidationErrorsToMap(ve))
+	case errors.As(err, &throttled):
+		// Terlalu banyak kode 2FA salah: verifikasi dikunci sementara
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"general": err.Error()})
+	case errors.Is(err, service.ErrInvalidMFACode):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"code": err.Error()})
+	case errors.Is(err, service.ErrMFARequired), errors.Is(err, service.ErrSMSSecondFactor):
+		writeJSON(w, http.StatusForbidden, map[string]string{"general": err.Error()})
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		writeJSON(w, http.StatusConflict, map[string]string{"general": err.Error()})
+	case errors.Is(err, service.ErrMFANotEnabled), errors.Is(err, service.ErrMFANotEnrolling), errors.Is(err, service.ErrPhoneNotVerified):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"general": err.Error()})
+	case errors.Is(err, service.ErrOTPRateLimited):
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"general": err.Error()})
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
+	"github.com/itujun/project-ecommerce-go-next/internal/oauth"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"golang.org/x/oauth2"
+)
+
+const oauthStateCookie = "oauth_state"
+
+// OAuthHandler menangani login sosial (authorization code + PKCE) lewat redirect browser.
+type OAuthHandler struct {
+	oauthService *service.OAuthService
+	jwtService   *service.JWTService
+	loginFlow    *LoginFlow
+	cfg          *config.Config
+}
+
+// NewOAuthHandler membuat instance baru OAuthHandler.
+func NewOAuthHandler(oauthService *service.OAuthService, jwtService *service.JWTService, loginFlow *LoginFlow, cfg *config.Config) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: oauthService,
+		jwtService:   jwtService,
+		loginFlow:    loginFlow,
+		cfg:          cfg,
+	}
+}
+
+// Start menangani GET /auth/oauth/{provider}/start.
+// Flow:
+// 1) Buat state, nonce, dan PKCE verifier acak
+// 2) Simpan ketiganya di cookie oauth_state bertanda tangan (berlaku 10 menit)
+// 3) Redirect ke halaman login provider
+// Jika user sudah login (cookie access_token), identitas sosial ditautkan ke akunnya alih-alih login.
+func (h *OAuthHandler) Start(w http.ResponseWriter, r *http.Request) {
+	providerName := chi.URLParam(r, "provider")
+	claims := service.OAuthStateClaims{
+		Provider: providerName,
+		State:    randomURLToken(),
+		Verifier: oauth2.GenerateVerifier(),
+		Nonce:    randomURLToken(),
+	}
+	if principal, ok := middleware.PrincipalFromContext(r.Context()); ok {
+		claims.LinkUserID = principal.UserID
+	}
+
+	authURL, err := h.oauthService.AuthCodeURL(r.Context(), providerName, claims.State, claims.Verifier, claims.Nonce)
+	if err != nil {
+		if errors.Is(err, oauth.ErrProviderNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "provider tidak tersedia", http.StatusBadGateway)
+		return
+	}
+
+	stateToken, exp, err := h.jwtService.GenerateOAuthStateToken(claims, service.OAuthStateTTL)
+	if err != nil {
+		http.Error(w, "cannot issue oauth state", http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    stateToken,
+		Path:     "/auth/oauth",
+		Expires:  exp,
+		MaxAge:   int(service.OAuthStateTTL.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode, // Lax agar cookie ikut terkirim saat provider me-redirect kembali
+		Secure:   false,                // true di produksi (HTTPS)
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback menangani GET /auth/oauth/{provider}/callback.
+// Flow:
+// 1) Cocokkan parameter state dengan cookie oauth_state (proteksi CSRF), lalu hapus cookie
+// 2) Tukar code + PKCE verifier, verifikasi id_token dan nonce
+// 3) Login/tautkan/buat user, lalu terbitkan sesi seperti AuthHandler.Login (termasuk 2FA)
+// 4) Redirect ke <FRONTEND_URL>/oauth/callback; kegagalan dikirim sebagai ?error=<kode>
+func (h *OAuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	providerName := chi.URLParam(r, "provider")
+	target := h.cfg.FrontendURL + "/oauth/callback"
+
+	stateCookie, err := r.Cookie(oauthStateCookie)
+	http.SetCookie(w, &http.Cookie{Name: oauthStateCookie, Value: "", Path: "/auth/oauth", MaxAge: -1, HttpOnly: true})
+	if err != nil || stateCookie.Value == "" {
+		h.redirectError(w, r, target, "invalid_state")
+		return
+	}
+	claims, err := h.jwtService.VerifyOAuthStateToken(stateCookie.Value)
+	if err != nil || claims.Provider != providerName ||
+		subtle.ConstantTimeCompare([]byte(claims.State), []byte(r.URL.Query().Get("state"))) != 1 {
+		h.redirectError(w, r, target, "invalid_state")
+		return
+	}
+	// User menolak izin di halaman provider
+	if r.URL.Query().Get("error") != "" {
+		h.redirectError(w, r, target, "access_denied")
+		return
+	}
+
+	identity, err := h.oauthService.Exchange(r.Context(), providerName, r.URL.Query().Get("code"), claims.Verifier, claims.Nonce)
+	if err != nil {
+		h.redirectError(w, r, target, "exchange_failed")
+		return
+	}
+
+	// Mode tautkan akun: user sudah login saat memulai flow
+	if claims.LinkUserID != uuid.Nil {
+		if err := h.oauthService.Link(r.Context(), claims.LinkUserID, providerName, identity); err != nil {
+			h.redirectError(w, r, target, oauthErrorCode(err))
+			return
+		}
+		http.Redirect(w, r, target+"?linked="+url.QueryEscape(providerName), http.StatusFound)
+		return
+	}
+
+	user, err := h.oauthService.Login(r.Context(), providerName, identity)
+	if err != nil {
+		h.redirectError(w, r, target, oauthErrorCode(err))
+		return
+	}
+	h.loginFlow.CompleteRedirect(w, r, user, service.FirstFactorOAuth, target)
+}
+
+func (h *OAuthHandler) redirectError(w http.ResponseWriter, r *http.Request, target, code string) {
+	http.Redirect(w, r, target+"?error="+url.QueryEscape(code), http.StatusFound)
+}
+
+// oauthErrorCode memetakan error OAuthService ke kode error yang dibaca front-end.
+func oauthErrorCode(err error) string {
+	switch {
+	case errors.Is(err, service.ErrOAuthEmailConflict):
+		return "email_conflict"
+	case errors.Is(err, service.ErrOAuthIdentityLinked):
+		return "identity_linked"
+	case errors.Is(err, service.ErrOAuthEmailMissing):
+		return "email_missing"
+	case errors.Is(err, service.ErrEmailNotVerified):
+		return "email_not_verified"
+	case errors.Is(err, service.ErrAccountSuspended):
+		return "account_suspended"
+	default:
+		return "server_error"
+	}
+}
+
+// randomURLToken membuat string acak 256-bit (base64url) untuk state dan nonce.
+func randomURLToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
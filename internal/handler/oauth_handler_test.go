@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/oauth"
+	"github.com/itujun/project-ecommerce-go-next/internal/oauth/oauthtest"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"go.uber.org/zap"
+)
+
+const testFrontendURL = "http://frontend.test"
+
+// oauthFixture menjalankan provider OIDC tiruan dan API (start & callback login sosial) di httptest.
+type oauthFixture struct {
+	idp        *oauthtest.Provider
+	api        *httptest.Server
+	users      *memUsers
+	identities *memIdentities
+}
+
+func newOAuthFixture(t *testing.T) *oauthFixture {
+	t.Helper()
+	idp, err := oauthtest.NewProvider("ecommerce", "mock-secret", "budi@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	idpServer := httptest.NewServer(idp.Handler())
+	t.Cleanup(idpServer.Close)
+	idp.Issuer = idpServer.URL
+
+	router := chi.NewRouter()
+	f := &oauthFixture{idp: idp, api: httptest.NewServer(router), identities: &memIdentities{}}
+	t.Cleanup(f.api.Close)
+
+	cfg := testConfig(t)
+	cfg.FrontendURL = testFrontendURL
+	cfg.OAuthRedirectBaseURL = f.api.URL
+	cfg.OAuthProviders = []config.OAuthProviderConfig{{
+		Name:         "mock",
+		Issuer:       idp.Issuer,
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		Scopes:       []string{"openid", "email", "profile"},
+	}}
+	ts := newTestServices(t, cfg)
+	f.users = ts.users
+	oauthService := service.NewOAuthService(oauth.NewRegistry(cfg), ts.users, ts.roles, f.identities, cfg, zap.NewNop())
+	h := NewOAuthHandler(oauthService, ts.jwt, ts.loginFlow, cfg)
+	router.Get("/auth/oauth/{provider}/start", h.Start)
+	router.Get("/auth/oauth/{provider}/callback", h.Callback)
+	return f
+}
+
+// newBrowser membuat client dengan cookie jar yang berhenti saat diarahkan ke front-end.
+func newBrowser(t *testing.T) *http.Client {
+	t.Helper()
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, _ []*http.Request) error {
+			if req.URL.Host == "frontend.test" {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+}
+
+// login menjalankan start → authorize → callback dan mengembalikan URL front-end tujuan akhir.
+func (f *oauthFixture) login(t *testing.T, browser *http.Client) *url.URL {
+	t.Helper()
+	res, err := browser.Get(f.api.URL + "/auth/oauth/mock/start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	return frontendLocation(t, res)
+}
+
+// authorize menjalankan start lalu authorize tanpa mengikuti redirect ke callback,
+// dan mengembalikan URL callback berisi code & state.
+func (f *oauthFixture) authorize(t *testing.T, browser *http.Client) *url.URL {
+	t.Helper()
+	noFollow := *browser
+	noFollow.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	res, err := noFollow.Get(f.api.URL + "/auth/oauth/mock/start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	res, err = noFollow.Get(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	callback, err := url.Parse(res.Header.Get("Location"))
+	if err != nil || callback.Query().Get("code") == "" {
+		t.Fatalf("authorize tidak mengembalikan code: %q", res.Header.Get("Location"))
+	}
+	return callback
+}
+
+func (f *oauthFixture) callback(t *testing.T, browser *http.Client, callback *url.URL) *url.URL {
+	t.Helper()
+	res, err := browser.Get(callback.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	return frontendLocation(t, res)
+}
+
+func (f *oauthFixture) hasSession(t *testing.T, browser *http.Client) bool {
+	t.Helper()
+	apiURL, _ := url.Parse(f.api.URL)
+	for _, c := range browser.Jar.Cookies(apiURL) {
+		if c.Name == "access_token" && c.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func frontendLocation(t *testing.T, res *http.Response) *url.URL {
+	t.Helper()
+	loc, err := url.Parse(res.Header.Get("Location"))
+	if res.StatusCode != http.StatusFound || err != nil || loc.Host != "frontend.test" {
+		t.Fatalf("harus berakhir di front-end, got %d %q", res.StatusCode, res.Header.Get("Location"))
+	}
+	return loc
+}
+
+func TestOAuthCallbackRejectsStateMismatch(t *testing.T) {
+	f := newOAuthFixture(t)
+	browser := newBrowser(t)
+	callback := f.authorize(t, browser)
+
+	q := callback.Query()
+	q.Set("state", "state-palsu")
+	callback.RawQuery = q.Encode()
+	if got := f.callback(t, browser, callback).Query().Get("error"); got != "invalid_state" {
+		t.Fatalf("error = %q, want invalid_state", got)
+	}
+	if f.hasSession(t, browser) || len(f.users.all()) != 0 {
+		t.Fatal("state tidak cocok tidak boleh membuat user atau sesi")
+	}
+}
+
+func TestOAuthCallbackRejectsCodeFromAnotherFlow(t *testing.T) {
+	f := newOAuthFixture(t)
+	victim, attacker := newBrowser(t), newBrowser(t)
+
+	// Code milik flow penyerang disuntikkan ke flow korban (state korban valid, PKCE verifier berbeda)
+	victimCallback := f.authorize(t, victim)
+	attackerCallback := f.authorize(t, attacker)
+	q := victimCallback.Query()
+	q.Set("code", attackerCallback.Query().Get("code"))
+	victimCallback.RawQuery = q.Encode()
+
+	if got := f.callback(t, victim, victimCallback).Query().Get("error"); got != "exchange_failed" {
+		t.Fatalf("error = %q, want exchange_failed", got)
+	}
+	if f.hasSession(t, victim) || len(f.users.all()) != 0 {
+		t.Fatal("PKCE tidak cocok tidak boleh membuat user atau sesi")
+	}
+}
+
+func TestOAuthLoginAutoLinksVerifiedEmail(t *testing.T) {
+	f := newOAuthFixture(t)
+	verifiedAt := time.Now()
+	local := f.users.add(&domain.User{ID: uuid.New(), Name: "Budi", Email: f.idp.Email, EmailVerifiedAt: &verifiedAt})
+
+	browser := newBrowser(t)
+	if got := f.login(t, browser); got.Query().Get("error") != "" || got.Path != "/oauth/callback" {
+		t.Fatalf("login sosial gagal: %s", got)
+	}
+	if !f.hasSession(t, browser) {
+		t.Fatal("cookie sesi tidak diset")
+	}
+	identities := f.identities.all()
+	if len(identities) != 1 || identities[0].UserID != local.ID {
+		t.Fatalf("identitas harus tertaut ke user lokal, got %+v", identities)
+	}
+	if len(f.users.all()) != 1 {
+		t.Fatal("tidak boleh membuat user baru")
+	}
+}
+
+func TestOAuthLoginDoesNotLinkUnverifiedEmail(t *testing.T) {
+	tests := []struct {
+		name          string
+		localVerified bool
+		idpVerified   bool
+	}{
+		{name: "email lokal belum diverifikasi", localVerified: false, idpVerified: true},
+		{name: "email provider belum diverifikasi", localVerified: true, idpVerified: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOAuthFixture(t)
+			f.idp.EmailVerified = tt.idpVerified
+			local := &domain.User{ID: uuid.New(), Name: "Budi", Email: f.idp.Email}
+			if tt.localVerified {
+				now := time.Now()
+				local.EmailVerifiedAt = &now
+			}
+			f.users.add(local)
+
+			browser := newBrowser(t)
+			if got := f.login(t, browser).Query().Get("error"); got != "email_conflict" {
+				t.Fatalf("error = %q, want email_conflict", got)
+			}
+			if f.hasSession(t, browser) || len(f.identities.all()) != 0 {
+				t.Fatal("akun tidak boleh ditautkan atau login")
+			}
+		})
+	}
+}
+
+// memIdentities adalah UserIdentityRepository in-memory.
+type memIdentities struct {
+	mu         sync.Mutex
+	identities []domain.UserIdentity
+}
+
+func (m *memIdentities) all() []domain.UserIdentity {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.UserIdentity(nil), m.identities...)
+}
+
+func (m *memIdentities) FindBySubject(_ context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	for _, identity := range m.all() {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memIdentities) Create(_ context.Context, identity *domain.UserIdentity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.identities = append(m.identities, *identity)
+	return nil
+}
+
+func (m *memIdentities) ListByUser(_ context.Context, userID uuid.UUID) ([]domain.UserIdentity, error) {
+	var list []domain.UserIdentity
+	for _, identity := range m.all() {
+		if identity.UserID == userID {
+			list = append(list, identity)
+		}
+	}
+	return list, nil
+}
+
+func (m *memIdentities) Touch(context.Context, uuid.UUID, string, time.Time) error { return nil }
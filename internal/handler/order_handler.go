@@ -2,10 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
 	"github.com/itujun/project-ecommerce-go-next/internal/service"
 )
 
@@ -26,15 +29,18 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
 		return
 	}
-	// Ambil ID user dari context (setelah melewati JWT middleware)
-	buyerIDStr, _ := r.Context().Value("user_id").(string)
-	buyerID, err := uuid.Parse(buyerIDStr)
-	if err != nil {
+	// Ambil ID user dari principal (setelah melewati Authenticator)
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
 		http.Error(w, "invalid buyer ID", http.StatusUnauthorized)
 		return
 	}
-	res, err := h.orderService.CreateOrder(r.Context(), buyerID, req)
+	res, err := h.orderService.CreateOrder(r.Context(), principal.UserID, req)
 	if err != nil {
+		if errors.Is(err, service.ErrEmailNotVerified) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -43,18 +49,21 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(res)
 }
 
-// ListOrders menangani GET /orders untuk pembeli (menampilkan pesanan dirinya).
+// ListOrders menangani GET /orders. Jika policy hanya mengizinkan pesanan milik sendiri (mis. buyer),
+// hasil dibatasi ke pesanan principal; selain itu semua pesanan ditampilkan.
 func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
-	role, _ := r.Context().Value("role").(string)
-	userIDStr, _ := r.Context().Value("user_id").(string)
-	userID, _ := uuid.Parse(userIDStr)
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
 
 	var res []dto.OrderResponse
 	var err error
-	if role == "buyer" {
-		res, err = h.orderService.ListOrdersForBuyer(r.Context(), userID)
+	if middleware.OwnResourcesOnly(r.Context()) {
+		res, err = h.orderService.ListOrdersForBuyer(r.Context(), principal.UserID)
 	}else {
-		// admin atau seller melihat semua pesanan
+		// policy mengizinkan semua pesanan (mis. admin atau seller)
 		res, err = h.orderService.ListAllOrdersAdminSeller(r.Context())
 	}
 	if err != nil {
@@ -63,4 +72,20 @@ func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
 	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(res)
-}
\ No newline at end of file
+}
+
+// GetOrder menangani GET /orders/{id}. Kepemilikan pesanan sudah diperiksa middleware.AuthorizeResource.
+func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid order id", http.StatusBadRequest)
+		return
+	}
+	res, err := h.orderService.GetOrder(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"github.com/itujun/project-ecommerce-go-next/internal/utils"
+)
+
+// PasswordResetHandler menangani alur lupa password.
+type PasswordResetHandler struct {
+	resetService *service.PasswordResetService
+}
+
+// NewPasswordResetHandler membuat instance baru PasswordResetHandler.
+func NewPasswordResetHandler(resetService *service.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{resetService: resetService}
+}
+
+// Forgot menangani POST /auth/password/forgot.
+// Respons selalu 202 dengan pesan yang sama agar tidak membocorkan akun mana yang terdaftar.
+func (h *PasswordResetHandler) Forgot(w http.ResponseWriter, r *http.Request) {
+	var req dto.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.resetService.Forgot(r.Context(), req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			writeJSON(w, http.StatusBadRequest, utils.ValidationErrorsToMap(ve))
+			return
+		}
+		http.Error(w, "cannot process request", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"message": "jika email terdaftar, link reset password telah dikirim",
+	})
+}
+
+// Reset menangani POST /auth/password/reset.
+func (h *PasswordResetHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.resetService.Reset(r.Context(), req); err != nil {
+		if fieldErrors, ok := utils.FieldErrors(err); ok {
+			writeJSON(w, http.StatusBadRequest, fieldErrors)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"general": err.Error()})
+			return
+		}
+		http.Error(w, "cannot reset password", http.StatusInternalServerError)
+		return
+	}
+	// Semua sesi sudah dicabut; bersihkan juga cookie di browser ini
+	clearAuthCookies(w)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "password berhasil diubah, silakan login kembali"})
+}
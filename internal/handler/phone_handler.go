@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"github.com/itujun/project-ecommerce-go-next/internal/utils"
+)
+
+// PhoneHandler menangani nomor HP user (verifikasi via OTP SMS) dan login dengan kode SMS.
+type PhoneHandler struct {
+	phoneService *service.PhoneService
+	loginFlow    *LoginFlow
+}
+
+// NewPhoneHandler membuat instance baru PhoneHandler.
+func NewPhoneHandler(phoneService *service.PhoneService, loginFlow *LoginFlow) *PhoneHandler {
+	return &PhoneHandler{phoneService: phoneService, loginFlow: loginFlow}
+}
+
+// Get menangani GET /auth/me/phone.
+func (h *PhoneHandler) Get(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	res, err := h.phoneService.Phone(r.Context(), principal.UserID)
+	if err != nil {
+		writePhoneError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// RequestVerification menangani PUT /auth/me/phone dengan body {"phone": "..."}.
+// Kode OTP dikirim ke nomor baru; nomor tersimpan setelah POST /auth/me/phone/verify.
+func (h *PhoneHandler) RequestVerification(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.PhoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.phoneService.RequestVerification(r.Context(), principal.UserID, req); err != nil {
+		writePhoneError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"message": "kode verifikasi telah dikirim via SMS"})
+}
+
+// ConfirmVerification menangani POST /auth/me/phone/verify dengan body {"code": "..."}.
+func (h *PhoneHandler) ConfirmVerification(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.PhoneCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	res, err := h.phoneService.ConfirmVerification(r.Context(), principal.UserID, req)
+	if err != nil {
+		writePhoneError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// Remove menangani DELETE /auth/me/phone.
+func (h *PhoneHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.phoneService.RemovePhone(r.Context(), principal.UserID); err != nil {
+		writePhoneError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequestLoginCode menangani POST /auth/phone/login.
+// Respons selalu 202 dengan pesan yang sama agar tidak membocorkan nomor mana yang terdaftar.
+func (h *PhoneHandler) RequestLoginCode(w http.ResponseWriter, r *http.Request) {
+	var req dto.PhoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.phoneService.RequestLoginCode(r.Context(), req); err != nil {
+		writePhoneError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"message": "jika nomor terdaftar, kode login telah dikirim via SMS",
+	})
+}
+
+// Login menangani POST /auth/phone/login/verify dengan body {"phone": "...", "code": "..."}.
+// Kode SMS adalah faktor pertama sehingga sesi diterbitkan lewat LoginFlow (bisa meminta 2FA).
+func (h *PhoneHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req dto.PhoneLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	user, err := h.phoneService.Login(r.Context(), req)
+	if err != nil {
+		writePhoneError(w, err)
+		return
+	}
+	h.loginFlow.Complete(w, r, user, service.FirstFactorPhone)
+}
+
+// writePhoneError memetakan error PhoneService ke status HTTP.
+func writePhoneError(w http.ResponseWriter, err error) {
+	if fieldErrors, ok := utils.FieldErrors(err); ok {
+		writeJSON(w, http.StatusBadRequest, fieldErrors)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrInvalidPhone):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"phone": err.Error()})
+	case errors.Is(err, service.ErrPhoneTaken):
+		writeJSON(w, http.StatusConflict, map[string]string{"phone": err.Error()})
+	case errors.Is(err, service.ErrPhoneMFAEnabled):
+		writeJSON(w, http.StatusConflict, map[string]string{"general": err.Error()})
+	case errors.Is(err, service.ErrInvalidOTP):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"code": err.Error()})
+	case errors.Is(err, service.ErrOTPRateLimited):
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"general": err.Error()})
+	case errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"github.com/itujun/project-ecommerce-go-next/internal/utils"
+)
+
+// PolicyHandler menangani pengelolaan policy Casbin oleh admin.
+type PolicyHandler struct {
+	policyService *service.PolicyService
+}
+
+// NewPolicyHandler membuat instance baru PolicyHandler.
+func NewPolicyHandler(policyService *service.PolicyService) *PolicyHandler {
+	return &PolicyHandler{policyService: policyService}
+}
+
+// List menangani GET /admin/policies.
+func (h *PolicyHandler) List(w http.ResponseWriter, r *http.Request) {
+	res, err := h.policyService.List()
+	if err != nil {
+		writePolicyError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// AddPolicy menangani POST /admin/policies dengan body {"subject", "object", "action", "condition"}.
+// "condition" opsional; kosong berarti tanpa syarat.
+func (h *PolicyHandler) AddPolicy(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.PolicyRule
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.policyService.AddPolicy(r.Context(), principal.UserID, req, clientIP(r), r.UserAgent()); err != nil {
+		writePolicyError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, req)
+}
+
+// RemovePolicy menangani DELETE /admin/policies dengan body {"subject", "object", "action", "condition"}.
+func (h *PolicyHandler) RemovePolicy(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.PolicyRule
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.policyService.RemovePolicy(r.Context(), principal.UserID, req, clientIP(r), r.UserAgent()); err != nil {
+		writePolicyError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddGrouping menangani POST /admin/policies/groupings dengan body {"subject", "role"}.
+func (h *PolicyHandler) AddGrouping(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.GroupingRule
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.policyService.AddGrouping(r.Context(), principal.UserID, req, clientIP(r), r.UserAgent()); err != nil {
+		writePolicyError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, req)
+}
+
+// RemoveGrouping menangani DELETE /admin/policies/groupings dengan body {"subject", "role"}.
+func (h *PolicyHandler) RemoveGrouping(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.GroupingRule
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.policyService.RemoveGrouping(r.Context(), principal.UserID, req, clientIP(r), r.UserAgent()); err != nil {
+		writePolicyError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writePolicyError memetakan error PolicyService ke status HTTP.
+func writePolicyError(w http.ResponseWriter, err error) {
+	if fieldErrors, ok := utils.FieldErrors(err); ok {
+		writeJSON(w, http.StatusBadRequest, fieldErrors)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrPolicyExists), errors.Is(err, service.ErrPolicyProtected):
+		writeJSON(w, http.StatusConflict, map[string]string{"general": err.Error()})
+	case errors.Is(err, service.ErrInvalidCondition):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"condition": err.Error()})
+	case errors.Is(err, service.ErrPolicyNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+)
+
+// PrivacyHandler menangani ekspor data pribadi dan penghapusan akun oleh user yang login.
+type PrivacyHandler struct {
+	privacyService *service.PrivacyService
+}
+
+// NewPrivacyHandler membuat instance baru PrivacyHandler.
+func NewPrivacyHandler(privacyService *service.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{privacyService: privacyService}
+}
+
+// Export menangani POST /auth/me/export dan mengirim arsip ZIP berisi data pribadi user.
+func (h *PrivacyHandler) Export(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	archive, err := h.privacyService.Export(r.Context(), principal.UserID)
+	if err != nil {
+		writePrivacyError(w, err)
+		return
+	}
+	filename := "data-export-" + time.Now().Format("20060102") + ".zip"
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(archive)
+}
+
+// RequestDeletion menangani POST /auth/me/deletion.
+// Akun tidak langsung dihapus: anonimisasi dijadwalkan setelah masa tenggang dan bisa dibatalkan.
+func (h *PrivacyHandler) RequestDeletion(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	res, err := h.privacyService.RequestDeletion(r.Context(), principal.UserID, req)
+	if err != nil {
+		writePrivacyError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, res)
+}
+
+// CancelDeletion menangani DELETE /auth/me/deletion selama masa tenggang.
+func (h *PrivacyHandler) CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.privacyService.CancelDeletion(r.Context(), principal.UserID); err != nil {
+		writePrivacyError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writePrivacyError memetakan error PrivacyService ke status HTTP.
+func writePrivacyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCurrentPassword):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"password": err.Error()})
+	case errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
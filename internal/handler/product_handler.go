@@ -8,6 +8,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
 	"github.com/itujun/project-ecommerce-go-next/internal/service"
 )
 
@@ -56,10 +57,13 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
         http.Error(w, "invalid request body", http.StatusBadRequest)
         return
     }
-    // Dapatkan sellerID dari JWT (middleware menaruhnya di context)
-    sellerID := r.Context().Value("user_id").(string)
-    uid, _ := uuid.Parse(sellerID)
-    res, err := h.productService.CreateProduct(r.Context(), uid, req)
+    // Dapatkan sellerID dari principal (Authenticator menaruhnya di context)
+    principal, ok := middleware.PrincipalFromContext(r.Context())
+    if !ok {
+        http.Error(w, "unauthorized", http.StatusUnauthorized)
+        return
+    }
+    res, err := h.productService.CreateProduct(r.Context(), principal.UserID, req)
     if err != nil {
         http.Error(w, err.Error(), http.StatusBadRequest)
         return
@@ -70,6 +74,7 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 }
 
 // UpdateProduct menangani PUT /products/{id}
+// Kepemilikan produk sudah diperiksa middleware.AuthorizeResource.
 func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
     var req dto.UpdateProductRequest
     if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -82,9 +87,7 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
         http.Error(w, "invalid product id", http.StatusBadRequest)
         return
     }
-    sellerID := r.Context().Value("user_id").(string)
-    uid, _ := uuid.Parse(sellerID)
-    res, err := h.productService.UpdateProduct(r.Context(), uid, id, req)
+    res, err := h.productService.UpdateProduct(r.Context(), id, req)
     if err != nil {
         http.Error(w, err.Error(), http.StatusBadRequest)
         return
@@ -94,6 +97,7 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 }
 
 // DeleteProduct menangani DELETE /products/{id}
+// Kepemilikan produk sudah diperiksa middleware.AuthorizeResource.
 func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
     idParam := chi.URLParam(r, "id")
     id, err := uuid.Parse(idParam)
@@ -101,13 +105,11 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
         http.Error(w, "invalid product id", http.StatusBadRequest)
         return
     }
-    sellerID := r.Context().Value("user_id").(string)
-    uid, _ := uuid.Parse(sellerID)
-    if err := h.productService.DeleteProduct(r.Context(), uid, id); err != nil {
+    if err := h.productService.DeleteProduct(r.Context(), id); err != nil {
         http.Error(w, err.Error(), http.StatusBadRequest)
         return
     }
     w.WriteHeader(http.StatusNoContent)
 }
 
-// Endpoint yang memodifikasi produk (POST, PUT, DELETE) memerlukan ID pengguna yang diambil dari context (set oleh middleware JWT) dan memeriksa peran di service.
\ No newline at end of file
+// Endpoint yang memodifikasi produk (POST, PUT, DELETE) diotorisasi policy Casbin di middleware; PUT dan DELETE memakai AuthorizeResource sehingga seller hanya bisa mengubah produknya sendiri.
\ No newline at end of file
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"github.com/itujun/project-ecommerce-go-next/internal/utils"
+)
+
+// ProfileHandler menangani perubahan profil dan password oleh user yang login.
+type ProfileHandler struct {
+	profileService *service.ProfileService
+	jwtService     *service.JWTService
+}
+
+// NewProfileHandler membuat instance baru ProfileHandler.
+func NewProfileHandler(profileService *service.ProfileService, jwtService *service.JWTService) *ProfileHandler {
+	return &ProfileHandler{profileService: profileService, jwtService: jwtService}
+}
+
+// UpdateProfile menangani PATCH /auth/me.
+// Nama langsung diganti; email baru menunggu konfirmasi lewat link yang dikirim ke alamat tersebut.
+func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	res, err := h.profileService.UpdateProfile(r.Context(), principal.UserID, req)
+	if err != nil {
+		writeProfileError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// ConfirmEmailChange menangani POST /auth/me/email/confirm (token dari link di email baru).
+func (h *ProfileHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	var req dto.ConfirmEmailChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.profileService.ConfirmEmailChange(r.Context(), req); err != nil {
+		writeProfileError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "email berhasil diganti"})
+}
+
+// ChangePassword menangani POST /auth/me/password.
+// Flow:
+// 1) Periksa password saat ini, simpan hash password baru
+// 2) Cabut RT semua sesi lain dan semua AT (termasuk AT yang sedang dipakai)
+// 3) Terbitkan AT baru untuk sesi saat ini agar user tidak ikut keluar
+func (h *ProfileHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.profileService.ChangePassword(r.Context(), principal.UserID, principal.SessionID, req); err != nil {
+		writeProfileError(w, err)
+		return
+	}
+	atClaims, atStr, err := h.jwtService.GenerateAccessToken(principal.UserID, principal.RoleID, principal.SessionID)
+	if err != nil {
+		http.Error(w, "cannot issue access token", http.StatusInternalServerError)
+		return
+	}
+	if err := h.profileService.RecordAccessToken(r.Context(), principal.SessionID, atClaims.ID); err != nil {
+		http.Error(w, "cannot persist access token", http.StatusInternalServerError)
+		return
+	}
+	setAccessTokenCookie(w, atStr, atClaims.ExpiresAt.Time)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "password berhasil diganti"})
+}
+
+// writeProfileError memetakan error ProfileService ke status HTTP.
+func writeProfileError(w http.ResponseWriter, err error) {
+	if fieldErrors, ok := utils.FieldErrors(err); ok {
+		writeJSON(w, http.StatusBadRequest, fieldErrors)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrEmailTaken):
+		writeJSON(w, http.StatusConflict, map[string]string{"email": err.Error()})
+	case errors.Is(err, service.ErrEmailChangeLimit):
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"email": err.Error()})
+	case errors.Is(err, service.ErrInvalidEmailChangeToken):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"general": err.Error()})
+	case errors.Is(err, service.ErrInvalidCurrentPassword), errors.Is(err, service.ErrPasswordNotSet):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"current_password": err.Error()})
+	case errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"github.com/itujun/project-ecommerce-go-next/internal/utils"
+)
+
+// RoleHandler menangani daftar role dan pembuatan role kustom.
+type RoleHandler struct {
+	roleService *service.RoleService
+}
+
+// NewRoleHandler membuat instance baru RoleHandler.
+func NewRoleHandler(roleService *service.RoleService) *RoleHandler {
+	return &RoleHandler{roleService: roleService}
+}
+
+// List menangani GET /admin/roles.
+func (h *RoleHandler) List(w http.ResponseWriter, r *http.Request) {
+	res, err := h.roleService.List(r.Context())
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// Create menangani POST /admin/roles dengan body {"name", "description"}.
+func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.CreateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	res, err := h.roleService.Create(r.Context(), principal.UserID, req, clientIP(r), r.UserAgent())
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, res)
+}
+
+// writeRoleError memetakan error RoleService ke status HTTP.
+func writeRoleError(w http.ResponseWriter, err error) {
+	if fieldErrors, ok := utils.FieldErrors(err); ok {
+		writeJSON(w, http.StatusBadRequest, fieldErrors)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrInvalidRoleName):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"name": err.Error()})
+	case errors.Is(err, service.ErrRoleExists):
+		writeJSON(w, http.StatusConflict, map[string]string{"name": err.Error()})
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
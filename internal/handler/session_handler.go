@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+)
+
+// SessionHandler menangani daftar & pencabutan sesi perangkat milik user yang login.
+type SessionHandler struct {
+	userService *service.UserService
+	revocation  *service.TokenRevocationService
+}
+
+// NewSessionHandler membuat instance baru SessionHandler.
+func NewSessionHandler(userService *service.UserService, revocation *service.TokenRevocationService) *SessionHandler {
+	return &SessionHandler{userService: userService, revocation: revocation}
+}
+
+// ListSessions menangani GET /auth/sessions.
+func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	sessions, err := h.userService.ListSessions(r.Context(), principal.UserID, principal.SessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"sessions": sessions})
+}
+
+// RevokeSession menangani DELETE /auth/sessions/{id}.
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid session id", http.StatusBadRequest)
+		return
+	}
+	jtis, err := h.userService.RevokeSession(r.Context(), principal.UserID, sessionID)
+	if err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// AT terakhir sesi tersebut ikut dicabut; tanpa ini AT tetap valid sampai kedaluwarsa
+	if err := h.revocation.RevokeSessionTokens(r.Context(), principal.UserID, jtis); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Jika sesi saat ini yang dicabut, AT yang sedang dipakai ikut dicabut dan cookie dibersihkan
+	if sessionID == principal.SessionID {
+		_ = h.revocation.RevokeTokenID(r.Context(), principal.TokenID, principal.UserID, principal.ExpiresAt)
+		clearAuthCookies(w)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeOtherSessions menangani POST /auth/sessions/logout-others.
+// Semua sesi selain sesi saat ini dicabut.
+func (h *SessionHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	jtis, err := h.userService.RevokeOtherSessions(r.Context(), principal.UserID, principal.SessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.revocation.RevokeSessionTokens(r.Context(), principal.UserID, jtis); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"github.com/itujun/project-ecommerce-go-next/internal/utils"
+)
+
+const (
+	webauthnSessionCookie = "webauthn_session"
+	webauthnCookiePath    = "/auth/webauthn"
+)
+
+// WebAuthnHandler menangani pendaftaran passkey dan login dengan passkey.
+// Setiap ceremony terdiri dari begin (opsi untuk navigator.credentials.*) dan finish (respons authenticator);
+// challenge di antara keduanya disimpan di cookie webauthn_session bertanda tangan.
+type WebAuthnHandler struct {
+	webauthnService *service.WebAuthnService
+	loginFlow       *LoginFlow
+}
+
+// NewWebAuthnHandler membuat instance baru WebAuthnHandler.
+func NewWebAuthnHandler(webauthnService *service.WebAuthnService, loginFlow *LoginFlow) *WebAuthnHandler {
+	return &WebAuthnHandler{webauthnService: webauthnService, loginFlow: loginFlow}
+}
+
+// RegisterBegin menangani POST /auth/webauthn/register/begin.
+// Respons berisi {"publicKey": ...} untuk navigator.credentials.create().
+func (h *WebAuthnHandler) RegisterBegin(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	creation, token, exp, err := h.webauthnService.BeginRegistration(r.Context(), principal.UserID)
+	if err != nil {
+		writeWebAuthnError(w, err)
+		return
+	}
+	setWebAuthnSessionCookie(w, token, exp)
+	writeJSON(w, http.StatusOK, creation)
+}
+
+// RegisterFinish menangani POST /auth/webauthn/register/finish.
+// Body: {"name": "...", "credential": <PublicKeyCredential JSON>}.
+func (h *WebAuthnHandler) RegisterFinish(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req dto.FinishWebAuthnRegistrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	res, err := h.webauthnService.FinishRegistration(r.Context(), principal.UserID, webauthnSessionToken(w, r), req)
+	if err != nil {
+		writeWebAuthnError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, res)
+}
+
+// LoginBegin menangani POST /auth/webauthn/login/begin.
+// Respons berisi {"publicKey": ...} untuk navigator.credentials.get(); tidak butuh email karena
+// browser menawarkan passkey yang tersimpan untuk domain ini (discoverable credential).
+func (h *WebAuthnHandler) LoginBegin(w http.ResponseWriter, r *http.Request) {
+	assertion, token, exp, err := h.webauthnService.BeginLogin()
+	if err != nil {
+		writeWebAuthnError(w, err)
+		return
+	}
+	setWebAuthnSessionCookie(w, token, exp)
+	writeJSON(w, http.StatusOK, assertion)
+}
+
+// LoginFinish menangani POST /auth/webauthn/login/finish dengan body <PublicKeyCredential JSON>.
+// Passkey dengan user verification sudah dua faktor sehingga sesi langsung diterbitkan;
+// tanpa user verification login diteruskan ke LoginFlow biasa (bisa meminta kode 2FA).
+func (h *WebAuthnHandler) LoginFinish(w http.ResponseWriter, r *http.Request) {
+	user, userVerified, err := h.webauthnService.FinishLogin(r.Context(), webauthnSessionToken(w, r), r.Body)
+	if err != nil {
+		writeWebAuthnError(w, err)
+		return
+	}
+	if userVerified {
+		h.loginFlow.CompleteVerified(w, r, user)
+		return
+	}
+	h.loginFlow.Complete(w, r, user, service.FirstFactorPasskey)
+}
+
+// ListCredentials menangani GET /auth/webauthn/credentials.
+func (h *WebAuthnHandler) ListCredentials(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	credentials, err := h.webauthnService.ListCredentials(r.Context(), principal.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"credentials": credentials})
+}
+
+// DeleteCredential menangani DELETE /auth/webauthn/credentials/{id}.
+func (h *WebAuthnHandler) DeleteCredential(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid credential id", http.StatusBadRequest)
+		return
+	}
+	if err := h.webauthnService.DeleteCredential(r.Context(), principal.UserID, id); err != nil {
+		writeWebAuthnError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setWebAuthnSessionCookie menyimpan token sesi ceremony; cakupan hanya /auth/webauthn.
+func setWebAuthnSessionCookie(w http.ResponseWriter, token string, exp time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     webauthnSessionCookie,
+		Value:    token,
+		Path:     webauthnCookiePath,
+		Expires:  exp,
+		MaxAge:   int(service.WebAuthnSessionTTL.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+		Secure:   false, // true di produksi (HTTPS)
+	})
+}
+
+// webauthnSessionToken membaca lalu menghapus cookie sesi ceremony (challenge hanya dipakai sekali per browser).
+func webauthnSessionToken(w http.ResponseWriter, r *http.Request) string {
+	http.SetCookie(w, &http.Cookie{Name: webauthnSessionCookie, Value: "", Path: webauthnCookiePath, MaxAge: -1, HttpOnly: true})
+	c, err := r.Cookie(webauthnSessionCookie)
+	if err != nil {
+		return ""
+	}
+	return c.Value
+}
+
+// writeWebAuthnError memetakan error WebAuthnService ke status HTTP.
+func writeWebAuthnError(w http.ResponseWriter, err error) {
+	if fieldErrors, ok := utils.FieldErrors(err); ok {
+		writeJSON(w, http.StatusBadRequest, fieldErrors)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrInvalidWebAuthnSession), errors.Is(err, service.ErrInvalidPasskey):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"general": err.Error()})
+	case errors.Is(err, service.ErrWebAuthnCredentialLimit):
+		writeJSON(w, http.StatusConflict, map[string]string{"general": err.Error()})
+	case errors.Is(err, service.ErrWebAuthnCredentialNotFound), errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
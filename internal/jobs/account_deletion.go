@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"go.uber.org/zap"
+)
+
+// AccountDeletion menganonimkan akun yang masa tenggang penghapusannya sudah lewat.
+type AccountDeletion struct {
+	privacy   *service.PrivacyService
+	batchSize int
+	logger    *zap.Logger
+}
+
+// NewAccountDeletion membuat job anonimisasi akun.
+func NewAccountDeletion(privacy *service.PrivacyService, batchSize int, logger *zap.Logger) *AccountDeletion {
+	return &AccountDeletion{privacy: privacy, batchSize: batchSize, logger: logger}
+}
+
+// Name mengembalikan nama job.
+func (j *AccountDeletion) Name() string {
+	return "account_deletion"
+}
+
+// Run memproses akun per batch sampai tidak ada lagi yang jatuh tempo.
+func (j *AccountDeletion) Run(ctx context.Context) error {
+	total := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := j.privacy.AnonymizeDue(ctx, j.batchSize)
+		total += n
+		if err != nil {
+			return err
+		}
+		if n < j.batchSize {
+			break
+		}
+	}
+	if total > 0 {
+		j.logger.Info("akun terjadwal dianonimkan", zap.String("job", j.Name()), zap.Int("anonymized", total))
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+// RefreshTokenCleanup menghapus RT yang sudah kedaluwarsa atau dicabut lebih lama dari retention.
+// RT yang dicabut disimpan selama retention agar replay RT lama tetap terdeteksi sebagai reuse.
+type RefreshTokenCleanup struct {
+	repo      repository.RefreshTokenRepository
+	retention time.Duration
+	batchSize int
+	logger    *zap.Logger
+}
+
+// NewRefreshTokenCleanup membuat job pembersihan refresh_tokens.
+func NewRefreshTokenCleanup(repo repository.RefreshTokenRepository, retention time.Duration, batchSize int, logger *zap.Logger) *RefreshTokenCleanup {
+	return &RefreshTokenCleanup{repo: repo, retention: retention, batchSize: batchSize, logger: logger}
+}
+
+// Name mengembalikan nama job.
+func (j *RefreshTokenCleanup) Name() string {
+	return "refresh_token_cleanup"
+}
+
+// Run menghapus RT per batch sampai tidak ada lagi yang memenuhi syarat.
+func (j *RefreshTokenCleanup) Run(ctx context.Context) error {
+	before := time.Now().Add(-j.retention)
+	expired, err := deleteInBatches(ctx, j.batchSize, func(ctx context.Context, limit int) (int64, error) {
+		return j.repo.DeleteExpiredBefore(ctx, before, limit)
+	})
+	if err != nil {
+		return err
+	}
+	revoked, err := deleteInBatches(ctx, j.batchSize, func(ctx context.Context, limit int) (int64, error) {
+		return j.repo.DeleteRevokedBefore(ctx, before, limit)
+	})
+	if err != nil {
+		return err
+	}
+	j.logger.Info("refresh token lama dibersihkan",
+		zap.String("job", j.Name()),
+		zap.Int64("expired_deleted", expired),
+		zap.Int64("revoked_deleted", revoked),
+		zap.Time("before", before),
+	)
+	return nil
+}
+
+// deleteInBatches memanggil del berulang kali sampai batch terakhir tidak penuh,
+// sehingga setiap DELETE singkat dan tidak mengunci tabel lama.
+func deleteInBatches(ctx context.Context, batchSize int, del func(ctx context.Context, limit int) (int64, error)) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		n, err := del(ctx, batchSize)
+		total += n
+		if err != nil || n < int64(batchSize) {
+			return total, err
+		}
+	}
+}
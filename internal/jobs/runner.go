@@ -0,0 +1,98 @@
+// Package jobs menjalankan job pemeliharaan terjadwal di dalam proses server.
+// Setiap job dijaga lease di database (job_locks) sehingga aman dijalankan di beberapa replika:
+// dalam satu interval hanya satu replika yang menjalankan job tertentu.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Job adalah satu tugas pemeliharaan.
+type Job interface {
+	// Name dipakai sebagai kunci lease dan di log; harus unik.
+	Name() string
+	Run(ctx context.Context) error
+}
+
+// Runner menjalankan semua job terdaftar setiap interval.
+type Runner struct {
+	locks    repository.JobLockRepository
+	interval time.Duration
+	owner    string
+	jobs     []Job
+	logger   *zap.Logger
+}
+
+// NewRunner membuat Runner baru. ID owner lease diambil dari hostname ditambah sufiks acak.
+func NewRunner(locks repository.JobLockRepository, interval time.Duration, logger *zap.Logger) *Runner {
+	return &Runner{
+		locks:    locks,
+		interval: interval,
+		owner:    instanceID(),
+		logger:   logger,
+	}
+}
+
+// Register menambahkan job ke runner. Panggil sebelum Run.
+func (r *Runner) Register(jobs ...Job) {
+	r.jobs = append(r.jobs, jobs...)
+}
+
+// Run menjalankan job segera lalu setiap interval sampai ctx dibatalkan.
+func (r *Runner) Run(ctx context.Context) {
+	r.runAll(ctx)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.runAll(ctx)
+		}
+	}
+}
+
+// runAll menjalankan setiap job yang lease-nya berhasil diambil. Lease tidak dilepas setelah selesai
+// agar replika lain tidak menjalankan job yang sama lagi di interval yang sama.
+func (r *Runner) runAll(ctx context.Context) {
+	for _, job := range r.jobs {
+		now := time.Now()
+		acquired, err := r.locks.Acquire(ctx, job.Name(), r.owner, now, now.Add(r.interval))
+		if err != nil {
+			r.logger.Error("gagal mengambil lease job", zap.String("job", job.Name()), zap.Error(err))
+			continue
+		}
+		if !acquired {
+			r.logger.Debug("job dilewati, lease dipegang replika lain", zap.String("job", job.Name()))
+			continue
+		}
+		jobCtx, cancel := context.WithTimeout(ctx, r.interval)
+		start := time.Now()
+		err = job.Run(jobCtx)
+		cancel()
+		if err != nil {
+			r.logger.Error("job gagal", zap.String("job", job.Name()), zap.Duration("duration", time.Since(start)), zap.Error(err))
+			continue
+		}
+		r.logger.Debug("job selesai", zap.String("job", job.Name()), zap.Duration("duration", time.Since(start)))
+	}
+}
+
+// instanceID membuat ID unik per proses untuk pemilik lease.
+func instanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf)
+	return host + "-" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,85 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"go.uber.org/zap"
+)
+
+// Message adalah email teks sederhana yang dikirim aplikasi.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender adalah antarmuka pengirim email. Implementasi SMTP/penyedia pihak ketiga
+// cukup memenuhi interface ini tanpa mengubah service.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSender memilih implementasi Sender berdasarkan MAIL_DRIVER ("log" atau "file").
+func NewSender(cfg *config.Config, logger *zap.Logger) (Sender, error) {
+	switch cfg.MailDriver {
+	case "log", "":
+		return NewLogSender(cfg.MailFrom, logger), nil
+	case "file":
+		return NewFileSender(cfg.MailFrom, cfg.MailFileDir)
+	default:
+		return nil, fmt.Errorf("MAIL_DRIVER tidak dikenal: %s", cfg.MailDriver)
+	}
+}
+
+// LogSender menulis email ke logger; cocok untuk dev lokal.
+type LogSender struct {
+	from   string
+	logger *zap.Logger
+}
+
+// NewLogSender membuat LogSender baru.
+func NewLogSender(from string, logger *zap.Logger) *LogSender {
+	return &LogSender{from: from, logger: logger}
+}
+
+// Send mencatat isi email ke log.
+func (s *LogSender) Send(_ context.Context, msg Message) error {
+	s.logger.Info("email terkirim (log)",
+		zap.String("from", s.from),
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body),
+	)
+	return nil
+}
+
+// FileSender menyimpan setiap email sebagai file .eml di direktori;
+// test dan dev lokal bisa membaca link verifikasi dari file tersebut.
+type FileSender struct {
+	from string
+	dir  string
+}
+
+// NewFileSender membuat FileSender dan memastikan direktorinya ada.
+func NewFileSender(from, dir string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("gagal membuat direktori mail: %w", err)
+	}
+	return &FileSender{from: from, dir: dir}, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Send menulis email ke <dir>/<timestamp>-<penerima>.eml.
+func (s *FileSender) Send(_ context.Context, msg Message) error {
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	content := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n",
+		s.from, msg.To, msg.Subject, time.Now().Format(time.RFC1123Z), msg.Body)
+	return os.WriteFile(filepath.Join(s.dir, name), []byte(content), 0o644)
+}
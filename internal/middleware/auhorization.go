@@ -1,44 +1,140 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 
 	"github.com/casbin/casbin/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/authorization"
 )
 
+// ownScopeKey menandai request yang hanya diizinkan untuk resource milik principal sendiri.
+type ownScopeKey struct{}
+
 // Authorize menerima enforcer, nama resource, dan action.
 // Ia mengembalikan middleware yang memeriksa role user dari context.
 // kemudian memanggil enforcer untuk mengecek izin.
-func Authorize(enforcer *casbin.Enforcer, obj string, act string) func(http.Handler) http.Handler {
+// Untuk aksi tanpa resource tertentu (create/list), izin yang hanya berlaku bagi resource milik sendiri
+// tetap diterima dan ditandai di context; handler membaca tandanya lewat OwnResourcesOnly.
+func Authorize(enforcer *casbin.SyncedEnforcer, obj string, act string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Ambil role user dari context (di-set oleh JWT middleware)
-			roleVal := r.Context().Value("role")
-			role, ok := roleVal.(string)
-			if !ok || role == "" {
+			// Ambil principal dari context (di-set oleh Authenticator)
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok || principal.Role == "" {
 				http.Error(w, "Forbidden: role tidak ditemukan", http.StatusForbidden)
 				return
 			}
 
-			// Panggil enforcer untuk memeriksa apakah role boleh melakukan act pada obj
-			allowed, err := enforcer.Enforce(role, obj, act)
+			// Panggil enforcer untuk memeriksa apakah role boleh melakukan act pada obj (semua resource)
+			allowed, err := enforce(enforcer, principal, obj, act, authorization.Resource{})
 			if err != nil {
 				http.Error(w, "Internal Server Error: gagal memeriksa izin", http.StatusInternalServerError)
 				return
 			}
+			ownOnly := false
+			if !allowed {
+				// Izin terbatas: hanya resource milik principal sendiri
+				allowed, err = enforce(enforcer, principal, obj, act, authorization.Resource{Owner: principal.UserID.String()})
+				if err != nil {
+					http.Error(w, "Internal Server Error: gagal memeriksa izin", http.StatusInternalServerError)
+					return
+				}
+				ownOnly = allowed
+			}
 			if !allowed {
 				http.Error(w, "Forbidden: Anda tidak memiliki izin", http.StatusForbidden)
 				return
 			}
+			if !scopeAllows(w, principal, obj, act) {
+				return
+			}
 
 			// Jika diizinkan, lanjutkan ke handler selanjutnya
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ownScopeKey{}, ownOnly)))
+		})
+	}
+}
+
+// AuthorizeResource seperti Authorize, tetapi untuk rute dengan parameter {id}: pemilik resource dimuat
+// lewat owners[obj] lalu diteruskan ke enforcer sebagai r.res.Owner, sehingga aturan kepemilikan
+// (mis. seller hanya boleh mengubah produknya sendiri) ditentukan policy, bukan kode service.
+func AuthorizeResource(enforcer *casbin.SyncedEnforcer, owners authorization.Owners, obj string, act string) func(http.Handler) http.Handler {
+	ownerOf, ok := owners[obj]
+	if !ok {
+		panic(fmt.Sprintf("AuthorizeResource: OwnerFunc untuk resource %q belum didaftarkan", obj))
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok || principal.Role == "" {
+				http.Error(w, "Forbidden: role tidak ditemukan", http.StatusForbidden)
+				return
+			}
+			id, err := uuid.Parse(chi.URLParam(r, "id"))
+			if err != nil {
+				http.Error(w, "invalid "+obj+" id", http.StatusBadRequest)
+				return
+			}
+			owner, err := ownerOf(r.Context(), id)
+			if errors.Is(err, authorization.ErrResourceNotFound) {
+				http.Error(w, obj+" tidak ditemukan", http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				http.Error(w, "Internal Server Error: gagal memuat "+obj, http.StatusInternalServerError)
+				return
+			}
+
+			allowed, err := enforce(enforcer, principal, obj, act, authorization.Resource{Owner: owner.String()})
+			if err != nil {
+				http.Error(w, "Internal Server Error: gagal memeriksa izin", http.StatusInternalServerError)
+				return
+			}
+			if !allowed {
+				http.Error(w, "Forbidden: Anda tidak memiliki izin", http.StatusForbidden)
+				return
+			}
+			if !scopeAllows(w, principal, obj, act) {
+				return
+			}
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// OwnResourcesOnly mengembalikan true jika Authorize hanya mengizinkan resource milik principal sendiri,
+// mis. buyer pada GET /orders; handler harus membatasi hasil ke milik principal.
+func OwnResourcesOnly(ctx context.Context) bool {
+	ownOnly, _ := ctx.Value(ownScopeKey{}).(bool)
+	return ownOnly
+}
+
+// enforce menyusun atribut subject dari principal lalu memanggil enforcer.
+func enforce(enforcer *casbin.SyncedEnforcer, principal *Principal, obj, act string, res authorization.Resource) (bool, error) {
+	sub := authorization.Subject{ID: principal.UserID.String(), Role: principal.Role}
+	return enforcer.Enforce(sub, obj, act, res)
+}
+
+// scopeAllows menolak API key yang scope-nya tidak mencakup "<obj>:<act>" (selain izin role).
+func scopeAllows(w http.ResponseWriter, principal *Principal, obj, act string) bool {
+	if principal.Scopes != nil && !slices.Contains(principal.Scopes, obj+":"+act) {
+		http.Error(w, "Forbidden: scope api key tidak mencakup "+obj+":"+act, http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
 // Penjelasan kode
 // - Fungsi Authorize mengembalikan middleware dinamis berdasarkan obj (resource) dan act (action). Parameter pertama adalah enforcer yang sudah diinisialisasi.
-// - Middleware mengambil role dari context (di-set oleh JWT middleware).
-// - Fungsi enforcer.Enforce(subject, object, action) akan mengembalikan true jika izin ada di file policy.
-// - Jika tidak ada izin, middleware mengembalikan 403 Forbidden.
\ No newline at end of file
+// - Middleware mengambil role dan ID user dari Principal di context (di-set oleh Authenticator).
+// - Fungsi enforcer.Enforce(subject, object, action, resource) akan mengembalikan true jika izin ada di policy (tabel casbin_rule)
+//   dan kondisi rule (mis. r.res.Owner == r.sub.ID) terpenuhi.
+// - AuthorizeResource memuat pemilik resource dari {id} sebelum enforce; resource yang tidak ada menghasilkan 404.
+// - Jika tidak ada izin, middleware mengembalikan 403 Forbidden.
+// - Untuk Principal dari API key, scope "<obj>:<act>" juga wajib ada; scope tidak pernah menambah izin role.
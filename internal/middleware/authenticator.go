@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+)
+
+// Authenticator memverifikasi access token dari cookie maupun header Authorization.
+// Token divalidasi lewat JWTService sehingga hanya ada satu jalur autentikasi.
+// Request yang diautentikasi lewat cookie dengan method yang mengubah state wajib membawa token CSRF.
+// Mutasi dengan token impersonasi dicatat ke audit log.
+type Authenticator struct {
+	jwtService *service.JWTService
+	roleRepo   repository.RoleRepository
+	revocation *service.TokenRevocationService
+	apiKeys    *service.APIKeyService
+	csrf       *service.CSRFService
+	audit      *service.AuditService
+}
+
+// NewAuthenticator mengembalikan instance Authenticator baru.
+func NewAuthenticator(jwtService *service.JWTService, roleRepo repository.RoleRepository, revocation *service.TokenRevocationService, apiKeys *service.APIKeyService, csrf *service.CSRFService, audit *service.AuditService) *Authenticator {
+	return &Authenticator{jwtService: jwtService, roleRepo: roleRepo, revocation: revocation, apiKeys: apiKeys, csrf: csrf, audit: audit}
+}
+
+// Middleware adalah fungsi actual yang akan dipasang di router.
+// Ia mengambil token, memverifikasi, meresolusi nama role, kemudian menaruh Principal di context.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.authenticate(r)
+		if err != nil {
+			http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err := a.checkCSRF(r, principal); err != nil {
+			http.Error(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+			return
+		}
+		a.serve(next, w, r.WithContext(WithPrincipal(r.Context(), principal)), principal)
+	})
+}
+
+// Optional sama seperti Middleware, tetapi request tanpa token (atau token tidak valid)
+// tetap diteruskan tanpa Principal. Dipakai endpoint yang juga menerima kredensial lain,
+// contoh enrollment 2FA yang bisa memakai mfa_token sebelum sesi terbit.
+func (a *Authenticator) Optional(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.authenticate(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err := a.checkCSRF(r, principal); err != nil {
+			http.Error(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+			return
+		}
+		a.serve(next, w, r.WithContext(WithPrincipal(r.Context(), principal)), principal)
+	})
+}
+
+// AllowAPIKey sama seperti Middleware, tetapi juga menerima "Authorization: ApiKey <key>".
+// Dipasang hanya pada rute yang dijaga Authorize, karena izin API key dibatasi scope-nya.
+func (a *Authenticator) AllowAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawKey, isAPIKey := extractAPIKey(r)
+		if !isAPIKey {
+			a.Middleware(next).ServeHTTP(w, r)
+			return
+		}
+		key, user, err := a.apiKeys.Authenticate(r.Context(), rawKey)
+		if err != nil {
+			http.Error(w, "Unauthorized: api key tidak valid", http.StatusUnauthorized)
+			return
+		}
+		principal := &Principal{
+			UserID: user.ID,
+			RoleID: user.RoleID,
+			Role:   user.Role.Name,
+			Source: SourceAPIKey,
+			Scopes: key.ScopeList(),
+		}
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// serve meneruskan request ke next; mutasi dengan token impersonasi dicatat ke audit log
+// beserta status respons, termasuk yang ditolak Authorize atau DenyImpersonation.
+func (a *Authenticator) serve(next http.Handler, w http.ResponseWriter, r *http.Request, principal *Principal) {
+	if !principal.Impersonating() || isSafeMethod(r.Method) {
+		next.ServeHTTP(w, r)
+		return
+	}
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	next.ServeHTTP(rec, r)
+	_ = a.audit.Record(r.Context(), &domain.AuditLog{
+		ActorID:   principal.ActorID,
+		UserID:    &principal.UserID,
+		Action:    domain.AuditImpersonationRequest,
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Status:    rec.status,
+		IPAddress: remoteIP(r),
+		UserAgent: r.UserAgent(),
+	})
+}
+
+// CSRF menegakkan token CSRF pada rute di luar Middleware yang tetap bergantung pada cookie sesi
+// (refresh, logout, konfirmasi ganti email):
+// - AT cookie valid → token harus cocok dan terikat ke sid AT tersebut;
+// - AT tidak ada/kedaluwarsa tetapi cookie AT atau RT terkirim → minimal double-submit header = cookie;
+// - tanpa cookie sesi maupun dengan header Authorization → tidak ada kredensial yang bisa disalahgunakan, diteruskan.
+func (a *Authenticator) CSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := a.checkCookieCSRF(r); err != nil {
+			http.Error(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *Authenticator) checkCookieCSRF(r *http.Request) error {
+	if isSafeMethod(r.Method) || r.Header.Get("Authorization") != "" {
+		return nil
+	}
+	headerToken, cookieToken := r.Header.Get(service.CSRFHeaderName), ""
+	if c, err := r.Cookie(service.CSRFCookieName); err == nil {
+		cookieToken = c.Value
+	}
+	if c, err := r.Cookie("access_token"); err == nil && c.Value != "" {
+		if claims, err := a.jwtService.VerifyAccessToken(c.Value); err == nil {
+			return a.csrf.Verify(headerToken, cookieToken, claims.SessionID)
+		}
+		return a.csrf.VerifyDoubleSubmit(headerToken, cookieToken)
+	}
+	if c, err := r.Cookie("refresh_token"); err == nil && c.Value != "" {
+		return a.csrf.VerifyDoubleSubmit(headerToken, cookieToken)
+	}
+	return nil
+}
+
+// checkCSRF menegakkan token CSRF double-submit untuk request cookie yang mengubah state.
+// Request Bearer/ApiKey tidak dikirim otomatis oleh browser sehingga tidak rentan CSRF.
+func (a *Authenticator) checkCSRF(r *http.Request, principal *Principal) error {
+	if principal.Source != SourceCookie {
+		return nil
+	}
+	if isSafeMethod(r.Method) {
+		return nil
+	}
+	cookieToken := ""
+	if c, err := r.Cookie(service.CSRFCookieName); err == nil {
+		cookieToken = c.Value
+	}
+	return a.csrf.Verify(r.Header.Get(service.CSRFHeaderName), cookieToken, principal.SessionID)
+}
+
+// authenticate memverifikasi access token pada request lalu membangun Principal.
+func (a *Authenticator) authenticate(r *http.Request) (*Principal, error) {
+	tokenString, source, ok := extractAccessToken(r)
+	if !ok {
+		return nil, errors.New("format token salah")
+	}
+	if tokenString == "" {
+		return nil, errors.New("token tidak ditemukan")
+	}
+
+	// Verifikasi tanda tangan, masa berlaku, issuer, dan audience
+	claims, err := a.jwtService.VerifyAccessToken(tokenString)
+	if err != nil {
+		return nil, errors.New("token tidak valid")
+	}
+
+	// Tolak token yang dicabut sebelum kedaluwarsa (logout, ganti role, suspend)
+	revoked, err := a.revocation.IsRevoked(r.Context(), claims)
+	if err != nil {
+		return nil, errors.New("gagal memeriksa pencabutan token")
+	}
+	if revoked {
+		return nil, errors.New("token sudah dicabut")
+	}
+
+	// Resolusi nama role dari rid; Casbin bekerja dengan nama role
+	role, err := a.roleRepo.GetRoleByID(r.Context(), claims.RoleID)
+	if err != nil {
+		return nil, errors.New("role tidak ditemukan")
+	}
+
+	principal := &Principal{
+		UserID:    claims.UserID,
+		RoleID:    claims.RoleID,
+		Role:      role.Name,
+		TokenID:   claims.ID,
+		SessionID: claims.SessionID,
+		ExpiresAt: claims.ExpiresAt.Time,
+		Source:    source,
+	}
+	if claims.Actor != nil {
+		principal.ActorID = claims.Actor.UserID
+	}
+	return principal, nil
+}
+
+// extractAccessToken mengambil token dari header "Authorization: Bearer <token>",
+// lalu jatuh ke cookie access_token (dipakai front-end Next.js).
+// ok bernilai false jika header Authorization ada tetapi formatnya salah.
+func extractAccessToken(r *http.Request) (token, source string, ok bool) {
+	if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+			return "", "", false
+		}
+		return strings.TrimSpace(parts[1]), SourceBearer, true
+	}
+	if c, err := r.Cookie("access_token"); err == nil {
+		return c.Value, SourceCookie, true
+	}
+	return "", "", true
+}
+
+// isSafeMethod mengembalikan true untuk method yang tidak mengubah state.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// extractAPIKey mengambil key dari header "Authorization: ApiKey <key>".
+func extractAPIKey(r *http.Request) (string, bool) {
+	parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "ApiKey") {
+		return "", false
+	}
+	return strings.TrimSpace(parts[1]), true
+}
+
+// Penjelasan kode
+// - Authenticator menggantikan JWTMiddleware lama yang hanya menerima Bearer bertanda tangan JWT_SECRET.
+// - Token yang diterima adalah access token yang sama dengan cookie access_token hasil AuthHandler.Login.
+// - Role diresolusi dari RoleRepository berdasarkan klaim rid, lalu disimpan sebagai Principal bertipe.
+// - Setiap token juga dicek ke TokenRevocationService (denylist jti & cutoff per user) sehingga logout/suspend berlaku seketika.
+// - Untuk Principal dari cookie, POST/PUT/PATCH/DELETE wajib membawa header X-CSRF-Token yang sama dengan
+//   cookie csrf_token dan bertanda tangan untuk sid sesi tersebut; jika tidak, 403.
+// - AllowAPIKey menambah jalur "Authorization: ApiKey ek_<prefix>_<secret>" untuk integrasi server-ke-server;
+//   Principal dari API key membawa Scopes yang diperiksa Authorize selain policy role.
+// - Token impersonasi (klaim act) menghasilkan Principal dengan ActorID; setiap POST/PUT/PATCH/DELETE-nya
+//   dicatat ke audit log sebagai impersonation.request beserta status respons.
+// - Handler mengambil identitas dengan PrincipalFromContext(r.Context()), bukan string key seperti "role".
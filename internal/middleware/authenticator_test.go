@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/service"
+	"go.uber.org/zap"
+)
+
+func TestCSRFOnCookieRoutesOutsideMiddleware(t *testing.T) {
+	cfg := &config.Config{
+		JWTKeyDir:      t.TempDir(),
+		JWTSigningAlg:  service.AlgEdDSA,
+		JWTKeyRotation: time.Hour,
+		AccessTTL:      15 * time.Minute,
+		CSRFSecret:     "csrf-secret-untuk-test",
+	}
+	keys, err := service.NewKeyManager(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService := service.NewJWTService(cfg, keys)
+	csrf := service.NewCSRFService(cfg)
+	auth := NewAuthenticator(jwtService, nil, nil, nil, csrf, nil)
+
+	sessionID := uuid.New()
+	_, accessToken, err := jwtService.GenerateAccessToken(uuid.New(), uuid.New(), sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := csrf.Generate(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherToken, err := csrf.Generate(uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		cookies map[string]string
+		header  string
+		bearer  bool
+		want    int
+	}{
+		{name: "tanpa cookie sesi", method: http.MethodPost, want: http.StatusNoContent},
+		{name: "GET tidak dicek", method: http.MethodGet, cookies: map[string]string{"access_token": accessToken}, want: http.StatusNoContent},
+		{name: "bearer tidak dicek", method: http.MethodPost, cookies: map[string]string{"refresh_token": "rt"}, bearer: true, want: http.StatusNoContent},
+		{
+			name: "AT valid dengan token sesi", method: http.MethodPost,
+			cookies: map[string]string{"access_token": accessToken, service.CSRFCookieName: token}, header: token,
+			want: http.StatusNoContent,
+		},
+		{
+			name: "AT valid tanpa header", method: http.MethodPost,
+			cookies: map[string]string{"access_token": accessToken, service.CSRFCookieName: token},
+			want:    http.StatusForbidden,
+		},
+		{
+			name: "AT valid dengan token sesi lain", method: http.MethodPost,
+			cookies: map[string]string{"access_token": accessToken, service.CSRFCookieName: otherToken}, header: otherToken,
+			want: http.StatusForbidden,
+		},
+		{
+			name: "AT tidak valid, double-submit cocok", method: http.MethodPost,
+			cookies: map[string]string{"access_token": "kedaluwarsa", service.CSRFCookieName: token}, header: token,
+			want: http.StatusNoContent,
+		},
+		{
+			name: "hanya RT tanpa header", method: http.MethodPost,
+			cookies: map[string]string{"refresh_token": "rt", service.CSRFCookieName: token},
+			want:    http.StatusForbidden,
+		},
+		{
+			name: "hanya RT dengan header berbeda", method: http.MethodPost,
+			cookies: map[string]string{"refresh_token": "rt", service.CSRFCookieName: token}, header: otherToken,
+			want: http.StatusForbidden,
+		},
+		{
+			name: "hanya RT, double-submit cocok", method: http.MethodPost,
+			cookies: map[string]string{"refresh_token": "rt", service.CSRFCookieName: token}, header: token,
+			want: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := auth.CSRF(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			req := httptest.NewRequest(tt.method, "/auth/refresh", nil)
+			for name, value := range tt.cookies {
+				req.AddCookie(&http.Cookie{Name: name, Value: value})
+			}
+			if tt.header != "" {
+				req.Header.Set(service.CSRFHeaderName, tt.header)
+			}
+			if tt.bearer {
+				req.Header.Set("Authorization", "Bearer "+accessToken)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
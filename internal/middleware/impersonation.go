@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+)
+
+// DenyImpersonation menolak request dengan token impersonasi.
+// Dipasang pada aksi berbahaya yang tidak boleh dilakukan admin atas nama user:
+// ganti password/email, 2FA, passkey, API key, penghapusan akun, ekspor data, dan checkout.
+func DenyImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if principal, ok := PrincipalFromContext(r.Context()); ok && principal.Impersonating() {
+			http.Error(w, "Forbidden: aksi ini tidak diizinkan selama impersonasi", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder menyimpan status code yang ditulis handler (untuk audit log).
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+// remoteIP mengambil IP klien dari RemoteAddr (tanpa port).
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Asal kredensial sebuah Principal.
+const (
+	SourceCookie = "cookie"  // cookie access_token (front-end)
+	SourceBearer = "bearer"  // header Authorization: Bearer <AT>
+	SourceAPIKey = "api_key" // header Authorization: ApiKey <key>
+)
+
+// Principal merepresentasikan identitas pengguna yang sudah lolos autentikasi.
+// Nilai ini disimpan di context oleh Authenticator dan dibaca oleh Authorize serta handler.
+type Principal struct {
+	UserID    uuid.UUID // ID user (klaim uid)
+	RoleID    uuid.UUID // ID role (klaim rid)
+	Role      string    // nama role hasil resolusi dari RoleRepository, contoh "buyer"
+	TokenID   string    // jti access token yang dipakai
+	SessionID uuid.UUID // klaim sid: family RT (sesi perangkat) milik token
+	ExpiresAt time.Time // exp access token; dipakai saat mencabut token ini
+	Source    string    // SourceCookie, SourceBearer, atau SourceAPIKey
+	// Scopes membatasi izin API key ("<obj>:<act>"); nil untuk AT (izin penuh sesuai role).
+	Scopes []string
+	// ActorID adalah admin yang sedang meng-impersonate UserID (klaim act); uuid.Nil jika bukan impersonasi.
+	ActorID uuid.UUID
+}
+
+// Impersonating mengembalikan true jika request dilakukan admin atas nama user lain.
+func (p *Principal) Impersonating() bool {
+	return p.ActorID != uuid.Nil
+}
+
+// principalKey adalah tipe kunci context yang tidak diekspor agar tidak bentrok dengan package lain.
+type principalKey struct{}
+
+// WithPrincipal mengembalikan context baru yang membawa principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext mengambil principal dari context.
+// ok bernilai false jika request belum melewati Authenticator.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
@@ -0,0 +1,104 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"golang.org/x/oauth2"
+)
+
+// OIDCProvider adalah Provider generik berbasis OpenID Connect discovery.
+// Discovery dilakukan saat pertama dipakai sehingga server tetap bisa start walau provider sedang tidak terjangkau.
+type OIDCProvider struct {
+	cfg         config.OAuthProviderConfig
+	redirectURL string
+
+	mu       sync.Mutex
+	oauth2   *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDCProvider membuat provider OIDC dari konfigurasi.
+func NewOIDCProvider(cfg config.OAuthProviderConfig, redirectURL string) *OIDCProvider {
+	return &OIDCProvider{cfg: cfg, redirectURL: redirectURL}
+}
+
+// Name mengembalikan nama provider.
+func (p *OIDCProvider) Name() string { return p.cfg.Name }
+
+// AuthCodeURL membuat URL authorize dengan state, nonce, dan PKCE S256 challenge.
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, verifier, nonce string) (string, error) {
+	conf, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return conf.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Exchange menukar authorization code dengan token, lalu memverifikasi id_token
+// (tanda tangan via JWKS provider, issuer, audience = client ID, masa berlaku, dan nonce).
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	conf, idVerifier, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := conf.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("gagal menukar authorization code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("respons token tidak berisi id_token")
+	}
+	idToken, err := idVerifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("id_token tidak valid: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("nonce id_token tidak cocok")
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("gagal membaca klaim id_token: %w", err)
+	}
+	return &Identity{
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// discover memuat metadata provider sekali lalu menyimpannya; kegagalan akan dicoba ulang pada request berikutnya.
+func (p *OIDCProvider) discover(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.oauth2 != nil {
+		return p.oauth2, p.verifier, nil
+	}
+
+	// Context discovery dilepas dari request: keyset JWKS yang dibuat di sini dipakai ulang oleh request berikutnya.
+	provider, err := oidc.NewProvider(context.WithoutCancel(ctx), p.cfg.Issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("discovery OIDC %s gagal: %w", p.cfg.Name, err)
+	}
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		Endpoint:     provider.Endpoint(),
+		RedirectURL:  p.redirectURL,
+		Scopes:       p.cfg.Scopes,
+	}
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: p.cfg.ClientID})
+	return p.oauth2, p.verifier, nil
+}
@@ -0,0 +1,59 @@
+// Package oauth berisi abstraksi provider login sosial (OAuth2 authorization code + PKCE).
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+)
+
+// ErrProviderNotFound dikembalikan saat nama provider tidak terdaftar di OAUTH_PROVIDERS.
+var ErrProviderNotFound = errors.New("provider OAuth tidak dikenal")
+
+// Identity adalah identitas user yang sudah diverifikasi oleh provider.
+type Identity struct {
+	Subject       string // ID unik user di provider (klaim sub)
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider adalah satu penyedia login sosial.
+// verifier adalah PKCE code_verifier; nonce mengikat id_token ke request login ini.
+type Provider interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, state, verifier, nonce string) (string, error)
+	Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error)
+}
+
+// Registry menyimpan provider yang aktif berdasarkan nama.
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry membuat provider OIDC untuk setiap entri OAUTH_PROVIDERS.
+// redirect_uri setiap provider = <OAUTH_REDIRECT_BASE_URL>/auth/oauth/<nama>/callback.
+func NewRegistry(cfg *config.Config) *Registry {
+	r := &Registry{providers: make(map[string]Provider)}
+	for _, p := range cfg.OAuthProviders {
+		redirectURL := fmt.Sprintf("%s/auth/oauth/%s/callback", cfg.OAuthRedirectBaseURL, p.Name)
+		r.Register(NewOIDCProvider(p, redirectURL))
+	}
+	return r
+}
+
+// Register menambah atau mengganti provider.
+func (r *Registry) Register(p Provider) {
+	r.providers[p.Name()] = p
+}
+
+// Get mengambil provider berdasarkan nama.
+func (r *Registry) Get(name string) (Provider, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, ErrProviderNotFound
+	}
+	return p, nil
+}
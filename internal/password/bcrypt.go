@@ -0,0 +1,39 @@
+package password
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Bcrypt meng-hash password dengan bcrypt (format $2a$/$2b$/$2y$).
+type Bcrypt struct {
+	Cost int
+}
+
+// Hash membuat hash bcrypt dengan cost saat ini.
+func (b *Bcrypt) Hash(plain string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(plain), b.Cost)
+	return string(hashed), err
+}
+
+// Matches mengenali hash bcrypt.
+func (b *Bcrypt) Matches(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// Verify mencocokkan password dengan hash bcrypt.
+func (b *Bcrypt) Verify(plain, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(plain))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// NeedsRehash mengembalikan true jika cost hash berbeda dari cost saat ini.
+func (b *Bcrypt) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != b.Cost
+}
@@ -0,0 +1,104 @@
+package password
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// BreachedList memeriksa password terhadap file hash SHA-1 password bocor dalam format
+// Pwned Passwords ("<SHA1 HEX>:<jumlah>" per baris, terurut menurut hash), mis. hasil
+// haveibeenpwned-downloader yang mengunduh lewat API range k-anonymity.
+// File tidak dimuat ke memori: pencarian memakai binary search langsung di disk,
+// sehingga file berukuran puluhan GB tetap bisa dipakai.
+type BreachedList struct {
+	f    *os.File
+	size int64
+}
+
+// maxBreachedLine cukup untuk 40 hex + ":" + jumlah + CRLF.
+const maxBreachedLine = 128
+
+// OpenBreachedList membuka file daftar hash password bocor.
+func OpenBreachedList(path string) (*BreachedList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &BreachedList{f: f, size: info.Size()}, nil
+}
+
+// Contains mengembalikan true jika SHA-1 dari plain ada di daftar.
+func (b *BreachedList) Contains(plain string) (bool, error) {
+	sum := sha1.Sum([]byte(plain))
+	target := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	// Invariant: baris target (jika ada) dimulai di offset [lo, hi)
+	lo, hi := int64(0), b.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, line, err := b.lineFrom(mid)
+		if err != nil {
+			return false, err
+		}
+		if start >= hi {
+			hi = mid
+			continue
+		}
+		hash, _, _ := strings.Cut(line, ":")
+		switch cmp := strings.Compare(strings.ToUpper(strings.TrimSpace(hash)), target); {
+		case cmp == 0:
+			return true, nil
+		case cmp < 0:
+			lo = start + int64(len(line)) + 1
+		default:
+			hi = mid
+		}
+	}
+	return false, nil
+}
+
+// Close menutup file.
+func (b *BreachedList) Close() error {
+	return b.f.Close()
+}
+
+// lineFrom mengembalikan baris pertama yang dimulai di offset >= off beserta offset awalnya.
+// Jika tidak ada baris lagi, start bernilai b.size.
+func (b *BreachedList) lineFrom(off int64) (start int64, line string, err error) {
+	start = off
+	if off > 0 {
+		// Cari akhir baris yang memuat byte off-1
+		buf := make([]byte, maxBreachedLine)
+		n, err := b.f.ReadAt(buf, off-1)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, "", err
+		}
+		i := bytes.IndexByte(buf[:n], '\n')
+		if i < 0 {
+			return b.size, "", nil
+		}
+		start = off + int64(i)
+	}
+	if start >= b.size {
+		return b.size, "", nil
+	}
+	buf := make([]byte, maxBreachedLine)
+	n, err := b.f.ReadAt(buf, start)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, "", err
+	}
+	if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+		n = i
+	}
+	return start, strings.TrimRight(string(buf[:n]), "\r"), nil
+}
@@ -0,0 +1,126 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeBreachedList menulis file format Pwned Passwords (hash terurut) dari daftar password.
+func writeBreachedList(t *testing.T, passwords []string, newline string, trailingNewline bool) string {
+	t.Helper()
+	var lines []string
+	for i, p := range passwords {
+		sum := sha1.Sum([]byte(p))
+		lines = append(lines, fmt.Sprintf("%s:%d", strings.ToUpper(hex.EncodeToString(sum[:])), i+1))
+	}
+	slices.Sort(lines)
+	data := strings.Join(lines, newline)
+	if trailingNewline {
+		data += newline
+	}
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// sortedByHash mengurutkan password menurut hash SHA-1-nya (urutan baris di file).
+func sortedByHash(passwords []string) []string {
+	hash := func(p string) string {
+		sum := sha1.Sum([]byte(p))
+		return strings.ToUpper(hex.EncodeToString(sum[:]))
+	}
+	sorted := slices.Clone(passwords)
+	slices.SortFunc(sorted, func(a, b string) int { return strings.Compare(hash(a), hash(b)) })
+	return sorted
+}
+
+func TestBreachedListContains(t *testing.T) {
+	var breached []string
+	for i := range 200 {
+		breached = append(breached, fmt.Sprintf("bocor-%d", i))
+	}
+	sorted := sortedByHash(breached)
+
+	files := []struct {
+		name            string
+		newline         string
+		trailingNewline bool
+	}{
+		{name: "LF", newline: "\n", trailingNewline: true},
+		{name: "CRLF", newline: "\r\n", trailingNewline: true},
+		{name: "tanpa newline di akhir file", newline: "\n"},
+		{name: "CRLF tanpa newline di akhir file", newline: "\r\n"},
+	}
+	lookups := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{name: "baris pertama", password: sorted[0], want: true},
+		{name: "baris kedua", password: sorted[1], want: true},
+		{name: "baris tengah", password: sorted[len(sorted)/2], want: true},
+		{name: "baris terakhir", password: sorted[len(sorted)-1], want: true},
+		{name: "tidak ada", password: "bukan-bocor", want: false},
+		{name: "string kosong", password: "", want: false},
+	}
+	for _, file := range files {
+		t.Run(file.name, func(t *testing.T) {
+			list, err := OpenBreachedList(writeBreachedList(t, breached, file.newline, file.trailingNewline))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = list.Close() })
+			for _, tt := range lookups {
+				got, err := list.Contains(tt.password)
+				if err != nil {
+					t.Fatalf("%s: %v", tt.name, err)
+				}
+				if got != tt.want {
+					t.Errorf("%s: Contains(%q) = %v, want %v", tt.name, tt.password, got, tt.want)
+				}
+			}
+			// Setiap baris harus bisa ditemukan, bukan hanya yang kebetulan jatuh di titik tengah
+			for _, p := range breached {
+				if got, _ := list.Contains(p); !got {
+					t.Fatalf("Contains(%q) = false, want true", p)
+				}
+			}
+		})
+	}
+}
+
+func TestBreachedListSmallFiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		passwords []string
+	}{
+		{name: "file kosong"},
+		{name: "satu baris", passwords: []string{"rahasia"}},
+		{name: "dua baris", passwords: []string{"rahasia", "password1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := OpenBreachedList(writeBreachedList(t, tt.passwords, "\r\n", true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = list.Close() })
+			for _, p := range tt.passwords {
+				if got, err := list.Contains(p); err != nil || !got {
+					t.Errorf("Contains(%q) = %v, %v; want true", p, got, err)
+				}
+			}
+			if got, err := list.Contains("tidak-ada"); err != nil || got {
+				t.Errorf("Contains(tidak-ada) = %v, %v; want false", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Package password menyediakan hashing password dengan beberapa algoritma (argon2id, bcrypt).
+// Hash baru selalu memakai algoritma utama; hash lama tetap bisa diverifikasi dan
+// ditandai perlu di-hash ulang agar bisa di-upgrade saat user berhasil login.
+package password
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+)
+
+// Nama algoritma untuk PASSWORD_HASH_ALGORITHM.
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// ErrUnknownHash dikembalikan saat format hash tidak dikenali (mis. kosong untuk akun login sosial).
+var ErrUnknownHash = errors.New("format hash password tidak dikenal")
+
+// Algorithm adalah satu algoritma hashing password.
+type Algorithm interface {
+	// Hash membuat hash baru dalam format yang dikenali Matches.
+	Hash(plain string) (string, error)
+	// Matches mengembalikan true jika encoded dibuat oleh algoritma ini.
+	Matches(encoded string) bool
+	// Verify mencocokkan plain dengan encoded; password salah bukan error.
+	Verify(plain, encoded string) (bool, error)
+	// NeedsRehash mengembalikan true jika parameter encoded berbeda dari konfigurasi saat ini.
+	NeedsRehash(encoded string) bool
+}
+
+// Hasher membuat hash dengan algoritma utama dan memverifikasi hash dari semua algoritma yang didukung.
+type Hasher struct {
+	primary    Algorithm
+	algorithms []Algorithm
+}
+
+// New membuat Hasher dengan algoritma utama dan algoritma lama yang masih diterima saat verifikasi.
+func New(primary Algorithm, legacy ...Algorithm) *Hasher {
+	return &Hasher{primary: primary, algorithms: append([]Algorithm{primary}, legacy...)}
+}
+
+// NewFromConfig memilih algoritma utama berdasarkan PASSWORD_HASH_ALGORITHM; algoritma lainnya tetap diterima.
+func NewFromConfig(cfg *config.Config) (*Hasher, error) {
+	argon := &Argon2id{Params: Argon2Params{
+		Memory:      cfg.Argon2Memory,
+		Iterations:  cfg.Argon2Iterations,
+		Parallelism: cfg.Argon2Parallelism,
+		SaltLength:  16,
+		KeyLength:   32,
+	}}
+	bc := &Bcrypt{Cost: cfg.BcryptCost}
+	switch cfg.PasswordHashAlgorithm {
+	case AlgorithmArgon2id:
+		return New(argon, bc), nil
+	case AlgorithmBcrypt:
+		return New(bc, argon), nil
+	default:
+		return nil, fmt.Errorf("algoritma hash password tidak dikenal: %s", cfg.PasswordHashAlgorithm)
+	}
+}
+
+// Hash membuat hash password dengan algoritma utama.
+func (h *Hasher) Hash(plain string) (string, error) {
+	return h.primary.Hash(plain)
+}
+
+// Verify mencocokkan password dengan hash tersimpan. needsRehash bernilai true jika password cocok
+// tetapi hash dibuat dengan algoritma lain atau parameter lama, sehingga sebaiknya di-hash ulang.
+func (h *Hasher) Verify(plain, encoded string) (ok, needsRehash bool, err error) {
+	for _, alg := range h.algorithms {
+		if !alg.Matches(encoded) {
+			continue
+		}
+		ok, err = alg.Verify(plain, encoded)
+		if err != nil || !ok {
+			return false, false, err
+		}
+		return true, alg != h.primary || alg.NeedsRehash(encoded), nil
+	}
+	return false, false, ErrUnknownHash
+}
@@ -0,0 +1,198 @@
+package password
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"go.uber.org/zap"
+)
+
+// Kode pelanggaran kebijakan password.
+const (
+	ViolationTooShort     = "too_short"
+	ViolationTooLong      = "too_long"
+	ViolationCharClasses  = "char_classes"
+	ViolationCommon       = "common"
+	ViolationBreached     = "breached"
+	ViolationPersonalInfo = "personal_info"
+)
+
+// bcryptMaxBytes adalah batas panjang input bcrypt; byte setelahnya ditolak oleh x/crypto/bcrypt.
+const bcryptMaxBytes = 72
+
+//go:embed common_passwords.txt
+var commonPasswordsFile []byte
+
+// Violation adalah satu aturan kebijakan yang dilanggar.
+type Violation struct {
+	Code    string
+	Message string
+}
+
+// PolicyError dikembalikan Policy.Check saat password melanggar satu atau lebih aturan.
+// Field adalah nama field JSON tempat pesan ditampilkan (mis. "password" atau "new_password").
+type PolicyError struct {
+	Field      string
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Policy memeriksa kekuatan password: panjang, jenis karakter, daftar password umum/bocor,
+// dan larangan memuat nama atau email pemilik akun.
+type Policy struct {
+	MinLength      int // dalam karakter (rune)
+	MaxLength      int // dalam karakter (rune); 0 = tanpa batas
+	MinCharClasses int // jumlah minimal jenis karakter: huruf kecil, huruf besar, angka, simbol
+	BlockPersonal  bool
+	common         map[string]struct{}
+	breached       *BreachedList
+	maxBytes       int // batas byte tambahan untuk bcrypt; 0 = tanpa batas
+	logger         *zap.Logger
+}
+
+// NewPolicyFromConfig membuat Policy dari konfigurasi PASSWORD_*.
+// Daftar password bocor hanya dipakai jika PASSWORD_BREACHED_FILE diisi.
+func NewPolicyFromConfig(cfg *config.Config, logger *zap.Logger) (*Policy, error) {
+	p := &Policy{
+		MinLength:      cfg.PasswordMinLength,
+		MaxLength:      cfg.PasswordMaxLength,
+		MinCharClasses: cfg.PasswordMinCharClasses,
+		BlockPersonal:  cfg.PasswordBlockPersonalInfo,
+		common:         loadCommonPasswords(commonPasswordsFile),
+		logger:         logger,
+	}
+	if cfg.PasswordHashAlgorithm == AlgorithmBcrypt {
+		p.maxBytes = bcryptMaxBytes
+	}
+	if cfg.PasswordBreachedFile != "" {
+		list, err := OpenBreachedList(cfg.PasswordBreachedFile)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membuka daftar password bocor: %w", err)
+		}
+		p.breached = list
+	}
+	return p, nil
+}
+
+// Check memeriksa plain terhadap kebijakan. personal berisi data pemilik akun (nama, email)
+// yang tidak boleh dimuat password. Mengembalikan *PolicyError dengan Field = field.
+func (p *Policy) Check(field, plain string, personal ...string) error {
+	var violations []Violation
+	length := utf8.RuneCountInString(plain)
+	if length < p.MinLength {
+		violations = append(violations, Violation{ViolationTooShort, fmt.Sprintf("Kata sandi minimal %d karakter", p.MinLength)})
+	}
+	if (p.MaxLength > 0 && length > p.MaxLength) || (p.maxBytes > 0 && len(plain) > p.maxBytes) {
+		violations = append(violations, Violation{ViolationTooLong, fmt.Sprintf("Kata sandi maksimal %d karakter", p.maxLength())})
+	}
+	if charClasses(plain) < p.MinCharClasses {
+		violations = append(violations, Violation{ViolationCharClasses,
+			fmt.Sprintf("Kata sandi harus memuat minimal %d dari: huruf kecil, huruf besar, angka, simbol", p.MinCharClasses)})
+	}
+	if p.BlockPersonal && containsPersonalInfo(plain, personal) {
+		violations = append(violations, Violation{ViolationPersonalInfo, "Kata sandi tidak boleh memuat nama atau email Anda"})
+	}
+	if _, ok := p.common[strings.ToLower(plain)]; ok {
+		violations = append(violations, Violation{ViolationCommon, "Kata sandi terlalu umum dan mudah ditebak"})
+	} else if p.breached != nil {
+		found, err := p.breached.Contains(plain)
+		if err != nil {
+			// Gagal membaca file tidak boleh menghalangi registrasi/reset; cukup dicatat
+			p.logger.Warn("gagal memeriksa daftar password bocor", zap.Error(err))
+		} else if found {
+			violations = append(violations, Violation{ViolationBreached, "Kata sandi pernah bocor dalam insiden keamanan data, gunakan kata sandi lain"})
+		}
+	}
+	if len(violations) > 0 {
+		return &PolicyError{Field: field, Violations: violations}
+	}
+	return nil
+}
+
+// Close menutup file daftar password bocor (jika ada).
+func (p *Policy) Close() error {
+	if p.breached == nil {
+		return nil
+	}
+	return p.breached.Close()
+}
+
+// maxLength mengembalikan batas panjang efektif untuk pesan error.
+func (p *Policy) maxLength() int {
+	if p.maxBytes > 0 && (p.MaxLength == 0 || p.maxBytes < p.MaxLength) {
+		return p.maxBytes
+	}
+	return p.MaxLength
+}
+
+// charClasses menghitung jenis karakter yang dipakai: huruf kecil, huruf besar, angka, dan simbol.
+func charClasses(plain string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range plain {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	n := 0
+	for _, ok := range []bool{lower, upper, digit, symbol} {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+// containsPersonalInfo mengembalikan true jika password memuat bagian nama atau bagian lokal email
+// (minimal 3 karakter, tidak peka huruf besar/kecil).
+func containsPersonalInfo(plain string, personal []string) bool {
+	lower := strings.ToLower(plain)
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if local, _, ok := strings.Cut(value, "@"); ok {
+			value = local
+		}
+		parts := strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, part := range parts {
+			if utf8.RuneCountInString(part) >= 3 && strings.Contains(lower, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loadCommonPasswords membaca daftar password umum (satu per baris, huruf kecil, # untuk komentar).
+func loadCommonPasswords(data []byte) map[string]struct{} {
+	set := make(map[string]struct{})
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[strings.ToLower(line)] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+)
+
+// APIKeyRepository mendefinisikan operasi untuk API key milik user.
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	// FindByPrefix mengembalikan (nil, nil) jika prefix tidak dikenal.
+	FindByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+	// ListActiveByUser mengembalikan key yang belum dicabut (termasuk yang sudah kedaluwarsa).
+	ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error)
+	CountActiveByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	// Revoke mencabut key milik user; false jika key tidak ada, bukan milik user, atau sudah dicabut.
+	Revoke(ctx context.Context, userID, id uuid.UUID, at time.Time) (bool, error)
+	// TouchLastUsed memperbarui last_used_at hanya jika nilai lama lebih tua dari staleBefore (mengurangi write).
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at, staleBefore time.Time) error
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+)
+
+// AuditLogFilter membatasi hasil AuditLogRepository.List. Field kosong/nil berarti tanpa filter.
+type AuditLogFilter struct {
+	ActorID *uuid.UUID
+	UserID  *uuid.UUID
+	Action  string
+	Limit   int
+	Offset  int
+}
+
+// AuditLogRepository mendefinisikan operasi untuk jejak audit.
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *domain.AuditLog) error
+	// List mengembalikan entri sesuai filter (terbaru dulu) beserta total tanpa paginasi.
+	List(ctx context.Context, filter AuditLogFilter) ([]domain.AuditLog, int64, error)
+}
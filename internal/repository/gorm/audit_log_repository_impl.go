@@ -0,0 +1,52 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"gorm.io/gorm"
+)
+
+// auditLogRepository adalah implementasi AuditLogRepository menggunakan GORM.
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository membuat instance repository.
+func NewAuditLogRepository(db *gorm.DB) repository.AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+// Create menyimpan satu entri audit.
+func (r *auditLogRepository) Create(ctx context.Context, entry *domain.AuditLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// List mencari entri audit dengan paginasi, terbaru lebih dulu.
+func (r *auditLogRepository) List(ctx context.Context, filter repository.AuditLogFilter) ([]domain.AuditLog, int64, error) {
+	scope := func(db *gorm.DB) *gorm.DB {
+		if filter.ActorID != nil {
+			db = db.Where("actor_id = ?", *filter.ActorID)
+		}
+		if filter.UserID != nil {
+			db = db.Where("user_id = ?", *filter.UserID)
+		}
+		if filter.Action != "" {
+			db = db.Where("action = ?", filter.Action)
+		}
+		return db
+	}
+
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&domain.AuditLog{}).Scopes(scope).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var entries []domain.AuditLog
+	err := r.db.WithContext(ctx).Scopes(scope).
+		Order("created_at DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&entries).Error
+	return entries, total, err
+}
@@ -0,0 +1,45 @@
+package gorm
+
+import (
+	"context"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// jobLockRepository adalah implementasi JobLockRepository menggunakan GORM.
+type jobLockRepository struct {
+	db *gorm.DB
+}
+
+// NewJobLockRepository membuat instance JobLockRepository.
+func NewJobLockRepository(db *gorm.DB) repository.JobLockRepository {
+	return &jobLockRepository{db: db}
+}
+
+// Acquire mengambil lease lewat UPDATE bersyarat (atomik), atau INSERT jika baris job belum ada.
+func (r *jobLockRepository) Acquire(ctx context.Context, name, owner string, now, until time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&domain.JobLock{}).
+		Where("name = ? AND (locked_until <= ? OR owner = ?)", name, now, owner).
+		Updates(map[string]any{"owner": owner, "locked_until": until, "updated_at": now})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return true, nil
+	}
+	// Baris belum ada: replika pertama yang berhasil INSERT memegang lease
+	res = r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.JobLock{
+		Name:        name,
+		Owner:       owner,
+		LockedUntil: until,
+		UpdatedAt:   now,
+	})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
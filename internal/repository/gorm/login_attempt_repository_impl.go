@@ -0,0 +1,79 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// loginAttemptRepository adalah implementasi LoginAttemptRepository menggunakan GORM.
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginAttemptRepository membuat instance repository.
+func NewLoginAttemptRepository(db *gorm.DB) repository.LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+// Get mengambil catatan percobaan login; (nil, nil) jika belum ada.
+func (r *loginAttemptRepository) Get(ctx context.Context, key string) (*domain.LoginAttempt, error) {
+	var attempt domain.LoginAttempt
+	if err := r.db.WithContext(ctx).First(&attempt, "`key` = ?", key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// RecordFailure melakukan upsert atomik (INSERT ... ON DUPLICATE KEY UPDATE) sehingga
+// kegagalan dari banyak instance tetap terhitung benar.
+// Urutan assignment penting: failures dihitung dari last_failed_at sebelum kolom itu diperbarui.
+func (r *loginAttemptRepository) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*domain.LoginAttempt, error) {
+	attempt := &domain.LoginAttempt{Key: key, Failures: 1, LastFailedAt: at}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "failures"}, Value: gorm.Expr("IF(last_failed_at < ?, 1, failures + 1)", at.Add(-window))},
+			{Column: clause.Column{Name: "last_failed_at"}, Value: at},
+			{Column: clause.Column{Name: "updated_at"}, Value: at},
+		},
+	}).Create(attempt).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, key)
+}
+
+// ReserveAttempt memakai INSERT tanpa update saat konflik (catatan baru) atau UPDATE bersyarat pada nilai
+// failures/last_failed_at yang dibaca sebelumnya, sehingga hanya satu dari beberapa request paralel yang berhasil.
+func (r *loginAttemptRepository) ReserveAttempt(ctx context.Context, key string, prev *domain.LoginAttempt, at time.Time, failures int) (bool, error) {
+	if prev == nil {
+		attempt := &domain.LoginAttempt{Key: key, Failures: failures, LastFailedAt: at, UpdatedAt: at}
+		res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(attempt)
+		return res.RowsAffected == 1, res.Error
+	}
+	res := r.db.WithContext(ctx).Model(&domain.LoginAttempt{}).
+		Where("`key` = ? AND failures = ? AND last_failed_at = ?", key, prev.Failures, prev.LastFailedAt).
+		Where("locked_until IS NULL OR locked_until <= ?", at).
+		Updates(map[string]any{"failures": failures, "last_failed_at": at, "updated_at": at})
+	return res.RowsAffected == 1, res.Error
+}
+
+// Lock mengunci key sampai waktu tertentu.
+func (r *loginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.LoginAttempt{}).
+		Where("`key` = ?", key).
+		Update("locked_until", until).Error
+}
+
+// Reset menghapus catatan key.
+func (r *loginAttemptRepository) Reset(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Where("`key` = ?", key).Delete(&domain.LoginAttempt{}).Error
+}
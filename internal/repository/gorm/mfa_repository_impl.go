@@ -0,0 +1,104 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mfaRepository adalah implementasi MFARepository menggunakan GORM.
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+// NewMFARepository membuat instance repository.
+func NewMFARepository(db *gorm.DB) repository.MFARepository {
+	return &mfaRepository{db: db}
+}
+
+// GetByUserID mengambil konfigurasi MFA milik user; (nil, nil) jika belum ada.
+func (r *mfaRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*domain.UserMFA, error) {
+	var mfa domain.UserMFA
+	if err := r.db.WithContext(ctx).First(&mfa, "user_id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+// Save membuat atau mengganti konfigurasi MFA (upsert berdasarkan primary key user_id).
+func (r *mfaRepository) Save(ctx context.Context, mfa *domain.UserMFA) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret_encrypted", "enabled_at", "last_used_step", "updated_at"}),
+	}).Create(mfa).Error
+}
+
+// Enable menandai MFA aktif.
+func (r *mfaRepository) Enable(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.UserMFA{}).
+		Where("user_id = ?", userID).
+		Update("enabled_at", at).Error
+}
+
+// Delete menghapus konfigurasi MFA beserta recovery code-nya.
+func (r *mfaRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&domain.UserMFA{}).Error
+	})
+}
+
+// UseStep memperbarui last_used_step secara atomik.
+func (r *mfaRepository) UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&domain.UserMFA{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// ReplaceRecoveryCodes menghapus recovery code lama lalu menyimpan yang baru.
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []domain.MFARecoveryCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode menandai recovery code terpakai secara atomik.
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&domain.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// CountUnusedRecoveryCodes menghitung recovery code yang belum dipakai.
+func (r *mfaRepository) CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
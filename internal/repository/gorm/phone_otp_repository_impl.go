@@ -0,0 +1,82 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"gorm.io/gorm"
+)
+
+// phoneOTPRepository adalah implementasi PhoneOTPRepository menggunakan GORM.
+type phoneOTPRepository struct {
+	db *gorm.DB
+}
+
+// NewPhoneOTPRepository membuat instance repository.
+func NewPhoneOTPRepository(db *gorm.DB) repository.PhoneOTPRepository {
+	return &phoneOTPRepository{db: db}
+}
+
+// Create menyimpan kode OTP baru.
+func (r *phoneOTPRepository) Create(ctx context.Context, otp *domain.PhoneOTP) error {
+	return r.db.WithContext(ctx).Create(otp).Error
+}
+
+// FindActive mencari kode terbaru yang belum dipakai; (nil, nil) jika tidak ada.
+func (r *phoneOTPRepository) FindActive(ctx context.Context, userID uuid.UUID, purpose string) (*domain.PhoneOTP, error) {
+	var otp domain.PhoneOTP
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Order("created_at DESC").
+		First(&otp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &otp, nil
+}
+
+// ReserveAttempt memesan satu tebakan dengan satu UPDATE bersyarat sehingga tebakan paralel
+// tidak bisa melewati maxAttempts.
+func (r *phoneOTPRepository) ReserveAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&domain.PhoneOTP{}).
+		Where("id = ? AND attempts < ? AND used_at IS NULL", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// MarkUsed menandai kode terpakai secara atomik.
+func (r *phoneOTPRepository) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&domain.PhoneOTP{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// InvalidateAll menandai semua kode aktif user untuk tujuan tertentu sebagai terpakai.
+func (r *phoneOTPRepository) InvalidateAll(ctx context.Context, userID uuid.UUID, purpose string) error {
+	return r.db.WithContext(ctx).Model(&domain.PhoneOTP{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
+
+// CountSince menghitung kode yang dibuat sejak waktu tertentu.
+func (r *phoneOTPRepository) CountSince(ctx context.Context, userID uuid.UUID, purpose string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.PhoneOTP{}).
+		Where("user_id = ? AND purpose = ? AND created_at >= ?", userID, purpose, since).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,129 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// policyVersionID adalah id satu-satunya baris di casbin_policy_version.
+const policyVersionID = 1
+
+// policyRepository adalah implementasi PolicyRepository menggunakan GORM.
+type policyRepository struct {
+	db *gorm.DB
+}
+
+// NewPolicyRepository membuat instance repository.
+func NewPolicyRepository(db *gorm.DB) repository.PolicyRepository {
+	return &policyRepository{db: db}
+}
+
+// List mengembalikan semua rule berurutan sesuai waktu penambahan.
+func (r *policyRepository) List(ctx context.Context) ([]domain.CasbinRule, error) {
+	var rules []domain.CasbinRule
+	err := r.db.WithContext(ctx).Order("id").Find(&rules).Error
+	return rules, err
+}
+
+// Add menyimpan rule baru lalu menaikkan versi.
+func (r *policyRepository) Add(ctx context.Context, rules []domain.CasbinRule) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rules).Error; err != nil {
+			return err
+		}
+		return bumpPolicyVersion(tx)
+	})
+}
+
+// Remove menghapus rule yang semua kolomnya sama persis lalu menaikkan versi.
+func (r *policyRepository) Remove(ctx context.Context, rules []domain.CasbinRule) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, rule := range rules {
+			err := tx.Where("ptype = ? AND v0 = ? AND v1 = ? AND v2 = ? AND v3 = ? AND v4 = ? AND v5 = ?",
+				rule.Ptype, rule.V0, rule.V1, rule.V2, rule.V3, rule.V4, rule.V5).
+				Delete(&domain.CasbinRule{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return bumpPolicyVersion(tx)
+	})
+}
+
+// RemoveFiltered menghapus rule yang cocok dengan filter kolom lalu menaikkan versi.
+func (r *policyRepository) RemoveFiltered(ctx context.Context, ptype string, fieldIndex int, values []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("ptype = ?", ptype)
+		for i, v := range values {
+			if v == "" {
+				continue
+			}
+			query = query.Where(fmt.Sprintf("v%d = ?", fieldIndex+i), v)
+		}
+		if err := query.Delete(&domain.CasbinRule{}).Error; err != nil {
+			return err
+		}
+		return bumpPolicyVersion(tx)
+	})
+}
+
+// ReplaceAll mengganti seluruh rule dalam satu transaksi lalu menaikkan versi.
+func (r *policyRepository) ReplaceAll(ctx context.Context, rules []domain.CasbinRule) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&domain.CasbinRule{}).Error; err != nil {
+			return err
+		}
+		if len(rules) > 0 {
+			if err := tx.Create(&rules).Error; err != nil {
+				return err
+			}
+		}
+		return bumpPolicyVersion(tx)
+	})
+}
+
+// SeedIfEmpty mengisi tabel kosong. Baris versi dikunci (SELECT ... FOR UPDATE) agar
+// beberapa instance yang start bersamaan tidak mengisi dua kali.
+func (r *policyRepository) SeedIfEmpty(ctx context.Context, rules []domain.CasbinRule) (bool, error) {
+	seeded := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var version domain.CasbinPolicyVersion
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&version, policyVersionID).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&domain.CasbinRule{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 || len(rules) == 0 {
+			return nil
+		}
+		if err := tx.Create(&rules).Error; err != nil {
+			return err
+		}
+		seeded = true
+		return bumpPolicyVersion(tx)
+	})
+	return seeded, err
+}
+
+// Version membaca versi policy saat ini.
+func (r *policyRepository) Version(ctx context.Context) (int64, error) {
+	var version domain.CasbinPolicyVersion
+	if err := r.db.WithContext(ctx).First(&version, policyVersionID).Error; err != nil {
+		return 0, err
+	}
+	return version.Version, nil
+}
+
+func bumpPolicyVersion(tx *gorm.DB) error {
+	return tx.Model(&domain.CasbinPolicyVersion{}).
+		Where("id = ?", policyVersionID).
+		Updates(map[string]any{"version": gorm.Expr("version + 1"), "updated_at": time.Now()}).Error
+}
@@ -2,6 +2,7 @@ package gorm
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/itujun/project-ecommerce-go-next/internal/domain"
@@ -30,15 +31,74 @@ func (r *refreshTokenRepository) FindByID(ctx context.Context, id uuid.UUID) (*d
 func (r *refreshTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Model(&domain.RefreshToken{}).
 		Where("id = ?", id).
-		Update("revoked", true).Error
+		Updates(revokedColumns()).Error
+}
+
+func (r *refreshTokenRepository) RevokeIfActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	// UPDATE ... WHERE revoked = 0 bersifat atomik; hanya satu request yang mendapat RowsAffected = 1
+	res := r.db.WithContext(ctx).Model(&domain.RefreshToken{}).
+		Where("id = ? AND revoked = ?", id, false).
+		Updates(revokedColumns())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked = ?", familyID, false).
+		Updates(revokedColumns()).Error
+}
+
+func (r *refreshTokenRepository) SetAccessTokenID(ctx context.Context, familyID uuid.UUID, jti string) error {
+	return r.db.WithContext(ctx).Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked = ?", familyID, false).
+		Update("access_token_id", jti).Error
 }
 
 func (r *refreshTokenRepository) RevokeAllByUser(ctx context.Context, userID uuid.UUID) error {
 	return r.db.WithContext(ctx).Model(&domain.RefreshToken{}).
-		Where("user_id = ?", userID).
-		Update("revoked", true).Error
+		Where("user_id = ? AND revoked = ?", userID, false).
+		Updates(revokedColumns()).Error
+}
+
+func (r *refreshTokenRepository) RevokeAllByUserExceptFamily(ctx context.Context, userID, familyID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND family_id <> ? AND revoked = ?", userID, familyID, false).
+		Updates(revokedColumns()).Error
+}
+
+func (r *refreshTokenRepository) ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]domain.RefreshToken, error) {
+	var tokens []domain.RefreshToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked = ? AND expires_at > ?", userID, false, time.Now()).
+		Order("issued_at DESC").
+		Find(&tokens).Error
+	return tokens, err
 }
 
 func (r *refreshTokenRepository) Update(ctx context.Context, rt *domain.RefreshToken) error {
 	return r.db.WithContext(ctx).Save(rt).Error
 }
+
+func (r *refreshTokenRepository) DeleteExpiredBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Limit(limit).
+		Delete(&domain.RefreshToken{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *refreshTokenRepository) DeleteRevokedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Where("revoked = ? AND revoked_at < ?", true, before).
+		Limit(limit).
+		Delete(&domain.RefreshToken{})
+	return res.RowsAffected, res.Error
+}
+
+// revokedColumns mengembalikan kolom yang diubah saat RT dicabut.
+func revokedColumns() map[string]any {
+	return map[string]any{"revoked": true, "revoked_at": time.Now()}
+}
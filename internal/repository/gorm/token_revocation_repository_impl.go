@@ -0,0 +1,90 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tokenRevocationRepository adalah implementasi TokenRevocationRepository menggunakan GORM.
+type tokenRevocationRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenRevocationRepository membuat instance repository.
+func NewTokenRevocationRepository(db *gorm.DB) repository.TokenRevocationRepository {
+	return &tokenRevocationRepository{db: db}
+}
+
+// RevokeJTI memasukkan jti ke denylist; mencabut jti yang sama dua kali tidak error.
+func (r *tokenRevocationRepository) RevokeJTI(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.RevokedAccessToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+// IsJTIRevoked memeriksa apakah jti ada di denylist dan belum kedaluwarsa.
+func (r *tokenRevocationRepository) IsJTIRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.RevokedAccessToken{}).
+		Where("jti = ? AND expires_at > ?", jti, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RevokeUserBefore melakukan upsert cutoff; nilai yang lebih lama tidak menimpa yang lebih baru.
+func (r *tokenRevocationRepository) RevokeUserBefore(ctx context.Context, userID uuid.UUID, before, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "revoked_before"}, Value: gorm.Expr("GREATEST(revoked_before, VALUES(revoked_before))")},
+			{Column: clause.Column{Name: "expires_at"}, Value: gorm.Expr("GREATEST(expires_at, VALUES(expires_at))")},
+			{Column: clause.Column{Name: "updated_at"}, Value: time.Now()},
+		},
+	}).Create(&domain.AccessTokenCutoff{
+		UserID:        userID,
+		RevokedBefore: before,
+		ExpiresAt:     expiresAt,
+	}).Error
+}
+
+// UserRevokedBefore mengambil cutoff aktif user.
+func (r *tokenRevocationRepository) UserRevokedBefore(ctx context.Context, userID uuid.UUID) (time.Time, bool, error) {
+	var cutoff domain.AccessTokenCutoff
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		First(&cutoff).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, err
+	}
+	return cutoff.RevokedBefore, true, nil
+}
+
+// DeleteExpired menghapus entri denylist dan cutoff yang sudah kedaluwarsa.
+func (r *tokenRevocationRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("expires_at <= ?", now).Delete(&domain.RevokedAccessToken{})
+		if res.Error != nil {
+			return res.Error
+		}
+		total += res.RowsAffected
+		res = tx.Where("expires_at <= ?", now).Delete(&domain.AccessTokenCutoff{})
+		if res.Error != nil {
+			return res.Error
+		}
+		total += res.RowsAffected
+		return nil
+	})
+	return total, err
+}
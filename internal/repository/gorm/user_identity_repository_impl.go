@@ -0,0 +1,59 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"gorm.io/gorm"
+)
+
+// userIdentityRepository adalah implementasi UserIdentityRepository menggunakan GORM.
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewUserIdentityRepository membuat instance repository.
+func NewUserIdentityRepository(db *gorm.DB) repository.UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+// FindBySubject mencari identitas berdasarkan provider dan subject; (nil, nil) jika tidak ada.
+func (r *userIdentityRepository) FindBySubject(ctx context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// Create menyimpan identitas baru.
+func (r *userIdentityRepository) Create(ctx context.Context, identity *domain.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+// Touch memperbarui email dan waktu login terakhir.
+func (r *userIdentityRepository) Touch(ctx context.Context, id uuid.UUID, email string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.UserIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"email": email, "last_login_at": at}).Error
+}
+
+// ListByUser mengembalikan semua identitas sosial yang tertaut ke user.
+func (r *userIdentityRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.UserIdentity, error) {
+	var identities []domain.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&identities).Error
+	return identities, err
+}
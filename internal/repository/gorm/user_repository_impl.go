@@ -2,7 +2,11 @@ package gorm
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
 	"github.com/itujun/project-ecommerce-go-next/internal/domain"
 	"github.com/itujun/project-ecommerce-go-next/internal/repository"
@@ -20,8 +24,13 @@ func NewUserRepository(db *gorm.DB) repository.UserRepository {
 }
 
 // CreateUser menyimpan user baru ke database.
+// Pelanggaran unique index email (mis. dua pendaftaran bersamaan) dikembalikan sebagai ErrDuplicateEmail.
 func (r *userRepository) CreateUser(ctx context.Context, user *domain.User) error {
-    return r.db.WithContext(ctx).Create(user).Error
+    err := r.db.WithContext(ctx).Create(user).Error
+    if isDuplicateKey(err) {
+        return repository.ErrDuplicateEmail
+    }
+    return err
 }
 
 // GetUserByEmail mencari user berdasarkan email.
@@ -34,6 +43,16 @@ func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*dom
     return &user, nil
 }
 
+// GetUserByPhone mencari user berdasarkan nomor HP.
+func (r *userRepository) GetUserByPhone(ctx context.Context, phone string) (*domain.User, error) {
+    var user domain.User
+    err := r.db.WithContext(ctx).Preload("Role").Where("phone = ?", phone).First(&user).Error
+    if err != nil {
+        return nil, err
+    }
+    return &user, nil
+}
+
 // GetUserByID mencari user berdasarkan ID.
 func (r *userRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
     var user domain.User
@@ -49,4 +68,187 @@ func (r *userRepository) ListUsers(ctx context.Context) ([]domain.User, error) {
     var users []domain.User
     err := r.db.WithContext(ctx).Preload("Role").Find(&users).Error
     return users, err
-}
\ No newline at end of file
+}
+
+// MarkEmailVerified mengisi email_verified_at milik user.
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID, at time.Time) error {
+    return r.db.WithContext(ctx).Model(&domain.User{}).
+        Where("id = ?", id).
+        Update("email_verified_at", at).Error
+}
+
+// UpdatePassword mengganti hash password milik user.
+func (r *userRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+    return r.db.WithContext(ctx).Model(&domain.User{}).
+        Where("id = ?", id).
+        Update("password", passwordHash).Error
+}
+
+// UpdateName mengganti nama user.
+func (r *userRepository) UpdateName(ctx context.Context, id uuid.UUID, name string) error {
+    return r.db.WithContext(ctx).Model(&domain.User{}).
+        Where("id = ?", id).
+        Update("name", name).Error
+}
+
+// SetPendingEmail mengisi atau mengosongkan pending_email.
+func (r *userRepository) SetPendingEmail(ctx context.Context, id uuid.UUID, email *string) error {
+    return r.db.WithContext(ctx).Model(&domain.User{}).
+        Where("id = ?", id).
+        Update("pending_email", email).Error
+}
+
+// ChangeEmail mengganti email yang sudah dikonfirmasi lewat link.
+func (r *userRepository) ChangeEmail(ctx context.Context, id uuid.UUID, email string, verifiedAt time.Time) error {
+    return r.db.WithContext(ctx).Model(&domain.User{}).
+        Where("id = ?", id).
+        Updates(map[string]any{"email": email, "email_verified_at": verifiedAt, "pending_email": nil}).Error
+}
+
+// SetPhone mengganti atau menghapus nomor HP; menghapus nomor juga menonaktifkan 2FA SMS.
+// Pelanggaran unique index phone (dua akun memverifikasi nomor yang sama bersamaan) dikembalikan sebagai ErrDuplicatePhone.
+func (r *userRepository) SetPhone(ctx context.Context, id uuid.UUID, phone *string, verifiedAt *time.Time) error {
+    updates := map[string]any{"phone": phone, "phone_verified_at": verifiedAt}
+    if phone == nil {
+        updates["phone_mfa_enabled_at"] = nil
+    }
+    err := r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Updates(updates).Error
+    if isDuplicateKey(err) {
+        return repository.ErrDuplicatePhone
+    }
+    return err
+}
+
+// SetPhoneMFA mengisi atau mengosongkan phone_mfa_enabled_at.
+func (r *userRepository) SetPhoneMFA(ctx context.Context, id uuid.UUID, at *time.Time) error {
+    return r.db.WithContext(ctx).Model(&domain.User{}).
+        Where("id = ?", id).
+        Update("phone_mfa_enabled_at", at).Error
+}
+
+// ScheduleDeletion mengisi atau mengosongkan deletion_scheduled_at.
+func (r *userRepository) ScheduleDeletion(ctx context.Context, id uuid.UUID, at *time.Time) error {
+    return r.db.WithContext(ctx).Model(&domain.User{}).
+        Where("id = ? AND anonymized_at IS NULL", id).
+        Update("deletion_scheduled_at", at).Error
+}
+
+// ListDueForDeletion mengambil user yang sudah melewati masa tenggang penghapusan.
+func (r *userRepository) ListDueForDeletion(ctx context.Context, now time.Time, limit int) ([]domain.User, error) {
+    var users []domain.User
+    err := r.db.WithContext(ctx).
+        Where("deletion_scheduled_at <= ? AND anonymized_at IS NULL", now).
+        Order("deletion_scheduled_at").
+        Limit(limit).
+        Find(&users).Error
+    return users, err
+}
+
+// AnonymizeUser menghapus PII user dan seluruh kredensialnya dalam satu transaksi.
+func (r *userRepository) AnonymizeUser(ctx context.Context, id uuid.UUID, at time.Time) error {
+    return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        for _, model := range []any{
+            &domain.RefreshToken{},
+            &domain.MFARecoveryCode{},
+            &domain.UserMFA{},
+            &domain.UserIdentity{},
+            &domain.UserToken{},
+            &domain.PhoneOTP{},
+            &domain.APIKey{},
+            &domain.WebAuthnCredential{},
+        } {
+            if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+                return err
+            }
+        }
+        // Email unik diganti placeholder agar alamat asli bisa dipakai mendaftar lagi
+        return tx.Model(&domain.User{}).Where("id = ?", id).Updates(map[string]any{
+            "name":              "Pengguna terhapus",
+            "email":             deletedEmail(id),
+            "password":          "",
+            "pending_email":     nil,
+            "phone":             nil,
+            "phone_verified_at": nil,
+            "phone_mfa_enabled_at": nil,
+            "email_verified_at": nil,
+            "anonymized_at":     at,
+            "deleted_at":        at,
+        }).Error
+    })
+}
+
+// SearchUsers mencari user (nama/email, role, status) dengan paginasi, terbaru lebih dulu.
+func (r *userRepository) SearchUsers(ctx context.Context, filter repository.UserFilter) ([]domain.User, int64, error) {
+    scope := func(db *gorm.DB) *gorm.DB {
+        if filter.Query != "" {
+            like := "%" + escapeLike(filter.Query) + "%"
+            db = db.Where("users.name LIKE ? OR users.email LIKE ?", like, like)
+        }
+        if filter.RoleID != nil {
+            db = db.Where("users.role_id = ?", *filter.RoleID)
+        }
+        switch filter.Status {
+        case repository.UserStatusActive:
+            db = db.Where("users.suspended_at IS NULL")
+        case repository.UserStatusSuspended:
+            db = db.Where("users.suspended_at IS NOT NULL")
+        }
+        return db
+    }
+
+    var total int64
+    if err := r.db.WithContext(ctx).Model(&domain.User{}).Scopes(scope).Count(&total).Error; err != nil {
+        return nil, 0, err
+    }
+    var users []domain.User
+    err := r.db.WithContext(ctx).Preload("Role").Scopes(scope).
+        Order("users.created_at DESC").
+        Limit(filter.Limit).
+        Offset(filter.Offset).
+        Find(&users).Error
+    return users, total, err
+}
+
+// UpdateRole mengganti role user.
+func (r *userRepository) UpdateRole(ctx context.Context, id, roleID uuid.UUID) error {
+    return r.db.WithContext(ctx).Model(&domain.User{}).
+        Where("id = ?", id).
+        Update("role_id", roleID).Error
+}
+
+// SetSuspended mengisi atau mengosongkan suspended_at.
+func (r *userRepository) SetSuspended(ctx context.Context, id uuid.UUID, at *time.Time) error {
+    return r.db.WithContext(ctx).Model(&domain.User{}).
+        Where("id = ?", id).
+        Update("suspended_at", at).Error
+}
+
+// SoftDeleteUser mengisi deleted_at (gorm.Model). Baris tetap ada sehingga email diganti placeholder
+// dan nomor HP dikosongkan (sama seperti AnonymizeUser); tanpa itu unique index menolak pendaftaran ulang
+// atau verifikasi nomor yang sama oleh akun lain.
+func (r *userRepository) SoftDeleteUser(ctx context.Context, id uuid.UUID) error {
+    return r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Updates(map[string]any{
+        "email":                deletedEmail(id),
+        "pending_email":        nil,
+        "phone":                nil,
+        "phone_verified_at":    nil,
+        "phone_mfa_enabled_at": nil,
+        "deleted_at":           time.Now(),
+    }).Error
+}
+
+// deletedEmail adalah placeholder email unik untuk akun yang dihapus.
+func deletedEmail(id uuid.UUID) string {
+    return "deleted-" + id.String() + "@deleted.invalid"
+}
+
+// isDuplicateKey mengenali pelanggaran unique index MySQL (error 1062).
+func isDuplicateKey(err error) bool {
+    var mysqlErr *mysql.MySQLError
+    return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+// escapeLike meng-escape karakter wildcard LIKE agar input user dicocokkan apa adanya.
+func escapeLike(s string) string {
+    return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,65 @@
+package gorm
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"gorm.io/gorm"
+)
+
+// userTokenRepository adalah implementasi UserTokenRepository menggunakan GORM.
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewUserTokenRepository membuat instance repository.
+func NewUserTokenRepository(db *gorm.DB) repository.UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+// Create menyimpan token baru.
+func (r *userTokenRepository) Create(ctx context.Context, token *domain.UserToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// FindByHash mencari token berdasarkan tujuan dan hash.
+func (r *userTokenRepository) FindByHash(ctx context.Context, purpose, tokenHash string) (*domain.UserToken, error) {
+	var token domain.UserToken
+	err := r.db.WithContext(ctx).
+		Where("purpose = ? AND token_hash = ?", purpose, tokenHash).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed menandai token terpakai secara atomik.
+func (r *userTokenRepository) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&domain.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// InvalidateAll menandai semua token aktif user untuk tujuan tertentu sebagai terpakai.
+func (r *userTokenRepository) InvalidateAll(ctx context.Context, userID uuid.UUID, purpose string) error {
+	return r.db.WithContext(ctx).Model(&domain.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
+
+// CountSince menghitung token yang dibuat sejak waktu tertentu.
+func (r *userTokenRepository) CountSince(ctx context.Context, userID uuid.UUID, purpose string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND created_at >= ?", userID, purpose, since).
+		Count(&count).Error
+	return count, err
+}
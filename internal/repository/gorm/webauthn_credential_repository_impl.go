@@ -0,0 +1,71 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"gorm.io/gorm"
+)
+
+// webauthnCredentialRepository adalah implementasi WebAuthnCredentialRepository menggunakan GORM.
+type webauthnCredentialRepository struct {
+	db *gorm.DB
+}
+
+// NewWebAuthnCredentialRepository membuat instance repository.
+func NewWebAuthnCredentialRepository(db *gorm.DB) repository.WebAuthnCredentialRepository {
+	return &webauthnCredentialRepository{db: db}
+}
+
+// Create menyimpan credential baru.
+func (r *webauthnCredentialRepository) Create(ctx context.Context, credential *domain.WebAuthnCredential) error {
+	return r.db.WithContext(ctx).Create(credential).Error
+}
+
+// FindByCredentialID mencari credential berdasarkan rawId; (nil, nil) jika tidak ada.
+func (r *webauthnCredentialRepository) FindByCredentialID(ctx context.Context, credentialID []byte) (*domain.WebAuthnCredential, error) {
+	var credential domain.WebAuthnCredential
+	if err := r.db.WithContext(ctx).Where("credential_id = ?", credentialID).First(&credential).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &credential, nil
+}
+
+// ListByUser mengambil semua credential user, terbaru dulu.
+func (r *webauthnCredentialRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.WebAuthnCredential, error) {
+	var credentials []domain.WebAuthnCredential
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&credentials).Error
+	return credentials, err
+}
+
+// UpdateAfterLogin memperbarui status authenticator setelah assertion berhasil.
+func (r *webauthnCredentialRepository) UpdateAfterLogin(ctx context.Context, credential *domain.WebAuthnCredential) error {
+	return r.db.WithContext(ctx).Model(&domain.WebAuthnCredential{}).
+		Where("id = ?", credential.ID).
+		Updates(map[string]any{
+			"sign_count":    credential.SignCount,
+			"flags":         credential.Flags,
+			"clone_warning": credential.CloneWarning,
+			"last_used_at":  credential.LastUsedAt,
+		}).Error
+}
+
+// Delete menghapus credential milik user.
+func (r *webauthnCredentialRepository) Delete(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&domain.WebAuthnCredential{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// JobLockRepository menyimpan lease job pemeliharaan yang dibagi antar replika.
+type JobLockRepository interface {
+	// Acquire mengambil lease sampai until jika lease kosong, sudah habis, atau dipegang owner yang sama.
+	// false berarti replika lain sedang memegang lease.
+	Acquire(ctx context.Context, name, owner string, now, until time.Time) (bool, error)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+)
+
+// LoginAttemptRepository menyimpan counter gagal login per akun/IP.
+// Implementasi memory cocok untuk satu instance; implementasi database berbagi state antar instance.
+type LoginAttemptRepository interface {
+	// Get mengembalikan (nil, nil) jika key belum punya catatan kegagalan.
+	Get(ctx context.Context, key string) (*domain.LoginAttempt, error)
+	// RecordFailure menambah counter secara atomik lalu mengembalikan state terbaru.
+	// Counter dimulai ulang dari 1 jika kegagalan terakhir lebih lama dari window.
+	RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*domain.LoginAttempt, error)
+	// ReserveAttempt mencatat satu percobaan sebagai kegagalan (failures, last_failed_at = at) hanya jika
+	// catatan key masih sama dengan prev (nil = belum ada catatan) dan tidak sedang dikunci pada at.
+	// Mengembalikan false jika request lain sudah mengubah catatan lebih dulu (compare-and-swap).
+	ReserveAttempt(ctx context.Context, key string, prev *domain.LoginAttempt, at time.Time, failures int) (bool, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	// Reset menghapus catatan key (login sukses atau dibuka admin).
+	Reset(ctx context.Context, key string) error
+}
@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+)
+
+// jobLockRepository menyimpan lease job di memori; hanya untuk satu instance.
+type jobLockRepository struct {
+	mu    sync.Mutex
+	locks map[string]domain.JobLock
+}
+
+// NewJobLockRepository membuat JobLockRepository in-memory.
+func NewJobLockRepository() repository.JobLockRepository {
+	return &jobLockRepository{locks: make(map[string]domain.JobLock)}
+}
+
+func (r *jobLockRepository) Acquire(_ context.Context, name, owner string, now, until time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if lock, ok := r.locks[name]; ok && lock.Owner != owner && lock.LockedUntil.After(now) {
+		return false, nil
+	}
+	r.locks[name] = domain.JobLock{Name: name, Owner: owner, LockedUntil: until, UpdatedAt: now}
+	return true, nil
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+)
+
+// loginAttemptRepository menyimpan percobaan login di memori proses.
+// State tidak dibagi antar instance dan hilang saat restart.
+type loginAttemptRepository struct {
+	mu        sync.Mutex
+	attempts  map[string]*domain.LoginAttempt
+	retention time.Duration // catatan tanpa aktivitas lebih lama dari ini dibuang
+	lastPrune time.Time
+}
+
+// NewLoginAttemptRepository membuat instance repository.
+// retention sebaiknya >= window kegagalan dan durasi lockout.
+func NewLoginAttemptRepository(retention time.Duration) repository.LoginAttemptRepository {
+	return &loginAttemptRepository{
+		attempts:  make(map[string]*domain.LoginAttempt),
+		retention: retention,
+	}
+}
+
+// Get mengembalikan salinan catatan percobaan login; (nil, nil) jika belum ada.
+func (r *loginAttemptRepository) Get(_ context.Context, key string) (*domain.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	attempt, ok := r.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	cp := *attempt
+	return &cp, nil
+}
+
+// RecordFailure menambah counter gagal untuk key.
+func (r *loginAttemptRepository) RecordFailure(_ context.Context, key string, at time.Time, window time.Duration) (*domain.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prune(at)
+
+	attempt, ok := r.attempts[key]
+	if !ok {
+		attempt = &domain.LoginAttempt{Key: key}
+		r.attempts[key] = attempt
+	}
+	if attempt.LastFailedAt.Before(at.Add(-window)) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailedAt = at
+	attempt.UpdatedAt = at
+	cp := *attempt
+	return &cp, nil
+}
+
+// ReserveAttempt mencatat percobaan jika catatan key masih sama dengan prev.
+func (r *loginAttemptRepository) ReserveAttempt(_ context.Context, key string, prev *domain.LoginAttempt, at time.Time, failures int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prune(at)
+
+	attempt, ok := r.attempts[key]
+	switch {
+	case prev == nil && ok, prev != nil && !ok:
+		return false, nil
+	case ok && (attempt.Failures != prev.Failures || !attempt.LastFailedAt.Equal(prev.LastFailedAt)):
+		return false, nil
+	case ok && attempt.LockedUntil != nil && attempt.LockedUntil.After(at):
+		return false, nil
+	case !ok:
+		attempt = &domain.LoginAttempt{Key: key}
+		r.attempts[key] = attempt
+	}
+	attempt.Failures = failures
+	attempt.LastFailedAt = at
+	attempt.UpdatedAt = at
+	return true, nil
+}
+
+// Lock mengunci key sampai waktu tertentu.
+func (r *loginAttemptRepository) Lock(_ context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if attempt, ok := r.attempts[key]; ok {
+		attempt.LockedUntil = &until
+	}
+	return nil
+}
+
+// Reset menghapus catatan key.
+func (r *loginAttemptRepository) Reset(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.attempts, key)
+	return nil
+}
+
+// prune membuang catatan kedaluwarsa paling sering sekali per menit. Dipanggil dengan mu terkunci.
+func (r *loginAttemptRepository) prune(now time.Time) {
+	if now.Sub(r.lastPrune) < time.Minute {
+		return
+	}
+	r.lastPrune = now
+	for key, attempt := range r.attempts {
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			continue
+		}
+		if now.Sub(attempt.LastFailedAt) > r.retention {
+			delete(r.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+)
+
+func TestLoginAttemptReserveAttempt(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Second)
+
+	tests := []struct {
+		name     string
+		existing *domain.LoginAttempt // catatan yang sudah tersimpan; nil = belum ada
+		prev     *domain.LoginAttempt // catatan yang dibaca pemanggil sebelum memesan
+		want     bool
+		wantRec  int // Failures tersimpan setelah pemanggilan
+	}{
+		{
+			name: "catatan baru",
+			want: true, wantRec: 1,
+		},
+		{
+			name:     "catatan sudah dibuat request lain",
+			existing: &domain.LoginAttempt{Failures: 1, LastFailedAt: earlier},
+			want:     false, wantRec: 1,
+		},
+		{
+			name:     "catatan sama dengan prev",
+			existing: &domain.LoginAttempt{Failures: 2, LastFailedAt: earlier},
+			prev:     &domain.LoginAttempt{Failures: 2, LastFailedAt: earlier},
+			want:     true, wantRec: 3,
+		},
+		{
+			name:     "failures berubah sejak dibaca",
+			existing: &domain.LoginAttempt{Failures: 3, LastFailedAt: earlier},
+			prev:     &domain.LoginAttempt{Failures: 2, LastFailedAt: earlier},
+			want:     false, wantRec: 3,
+		},
+		{
+			name:     "waktu gagal berubah sejak dibaca",
+			existing: &domain.LoginAttempt{Failures: 1, LastFailedAt: earlier},
+			prev:     &domain.LoginAttempt{Failures: 1, LastFailedAt: earlier.Add(-time.Hour)},
+			want:     false, wantRec: 1,
+		},
+		{
+			name: "catatan dihapus sejak dibaca",
+			prev: &domain.LoginAttempt{Failures: 2, LastFailedAt: earlier},
+			want: false, wantRec: 0,
+		},
+		{
+			name:     "masih dikunci",
+			existing: &domain.LoginAttempt{Failures: 2, LastFailedAt: earlier, LockedUntil: &future},
+			prev:     &domain.LoginAttempt{Failures: 2, LastFailedAt: earlier, LockedUntil: &future},
+			want:     false, wantRec: 2,
+		},
+		{
+			name:     "kunci sudah lewat",
+			existing: &domain.LoginAttempt{Failures: 2, LastFailedAt: earlier, LockedUntil: &past},
+			prev:     &domain.LoginAttempt{Failures: 2, LastFailedAt: earlier, LockedUntil: &past},
+			want:     true, wantRec: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := NewLoginAttemptRepository(time.Hour).(*loginAttemptRepository)
+			repo.lastPrune = now
+			if tt.existing != nil {
+				rec := *tt.existing
+				rec.Key = "acct:budi@example.com"
+				repo.attempts[rec.Key] = &rec
+			}
+			failures := 1
+			if tt.prev != nil {
+				failures = tt.prev.Failures + 1
+			}
+
+			got, err := repo.ReserveAttempt(ctx, "acct:budi@example.com", tt.prev, now, failures)
+			if err != nil {
+				t.Fatalf("ReserveAttempt: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("ReserveAttempt = %v, want %v", got, tt.want)
+			}
+			rec, _ := repo.Get(ctx, "acct:budi@example.com")
+			gotRec := 0
+			if rec != nil {
+				gotRec = rec.Failures
+			}
+			if gotRec != tt.wantRec {
+				t.Fatalf("failures tersimpan = %d, want %d", gotRec, tt.wantRec)
+			}
+			if tt.want && !rec.LastFailedAt.Equal(now) {
+				t.Fatalf("LastFailedAt = %v, want %v", rec.LastFailedAt, now)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+)
+
+// tokenRevocationRepository menyimpan denylist AT di memori proses.
+// State tidak dibagi antar instance dan hilang saat restart.
+type tokenRevocationRepository struct {
+	mu      sync.RWMutex
+	jtis    map[string]domain.RevokedAccessToken
+	cutoffs map[uuid.UUID]domain.AccessTokenCutoff
+}
+
+// NewTokenRevocationRepository membuat instance repository.
+func NewTokenRevocationRepository() repository.TokenRevocationRepository {
+	return &tokenRevocationRepository{
+		jtis:    make(map[string]domain.RevokedAccessToken),
+		cutoffs: make(map[uuid.UUID]domain.AccessTokenCutoff),
+	}
+}
+
+// RevokeJTI memasukkan jti ke denylist.
+func (r *tokenRevocationRepository) RevokeJTI(_ context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jtis[jti] = domain.RevokedAccessToken{JTI: jti, UserID: userID, ExpiresAt: expiresAt, CreatedAt: time.Now()}
+	return nil
+}
+
+// IsJTIRevoked memeriksa apakah jti ada di denylist dan belum kedaluwarsa.
+func (r *tokenRevocationRepository) IsJTIRevoked(_ context.Context, jti string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.jtis[jti]
+	return ok && entry.ExpiresAt.After(time.Now()), nil
+}
+
+// RevokeUserBefore menyimpan cutoff; nilai yang lebih lama tidak menimpa yang lebih baru.
+func (r *tokenRevocationRepository) RevokeUserBefore(_ context.Context, userID uuid.UUID, before, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cutoff := r.cutoffs[userID]
+	cutoff.UserID = userID
+	if before.After(cutoff.RevokedBefore) {
+		cutoff.RevokedBefore = before
+	}
+	if expiresAt.After(cutoff.ExpiresAt) {
+		cutoff.ExpiresAt = expiresAt
+	}
+	cutoff.UpdatedAt = time.Now()
+	r.cutoffs[userID] = cutoff
+	return nil
+}
+
+// UserRevokedBefore mengambil cutoff aktif user.
+func (r *tokenRevocationRepository) UserRevokedBefore(_ context.Context, userID uuid.UUID) (time.Time, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cutoff, ok := r.cutoffs[userID]
+	if !ok || !cutoff.ExpiresAt.After(time.Now()) {
+		return time.Time{}, false, nil
+	}
+	return cutoff.RevokedBefore, true, nil
+}
+
+// DeleteExpired menghapus entri yang sudah kedaluwarsa.
+func (r *tokenRevocationRepository) DeleteExpired(_ context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var total int64
+	for jti, entry := range r.jtis {
+		if !entry.ExpiresAt.After(now) {
+			delete(r.jtis, jti)
+			total++
+		}
+	}
+	for userID, cutoff := range r.cutoffs {
+		if !cutoff.ExpiresAt.After(now) {
+			delete(r.cutoffs, userID)
+			total++
+		}
+	}
+	return total, nil
+}
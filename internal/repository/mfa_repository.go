@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+)
+
+// MFARepository mendefinisikan operasi untuk konfigurasi TOTP dan recovery code.
+type MFARepository interface {
+	// GetByUserID mengembalikan (nil, nil) jika user belum pernah memulai enrollment.
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*domain.UserMFA, error)
+	// Save membuat atau mengganti konfigurasi MFA user (dipakai saat enrollment dimulai ulang).
+	Save(ctx context.Context, mfa *domain.UserMFA) error
+	Enable(ctx context.Context, userID uuid.UUID, at time.Time) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+	// UseStep menyimpan time step terakhir hanya jika lebih baru; false berarti kode sudah pernah dipakai.
+	UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []domain.MFARecoveryCode) error
+	// UseRecoveryCode menandai recovery code terpakai; false jika tidak ada atau sudah dipakai.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+)
+
+// PhoneOTPRepository mendefinisikan operasi untuk kode OTP SMS.
+type PhoneOTPRepository interface {
+	Create(ctx context.Context, otp *domain.PhoneOTP) error
+	// FindActive mengembalikan kode terbaru yang belum dipakai untuk user dan tujuan tertentu; (nil, nil) jika tidak ada.
+	FindActive(ctx context.Context, userID uuid.UUID, purpose string) (*domain.PhoneOTP, error)
+	// ReserveAttempt menambah attempts hanya jika kode belum dipakai dan attempts < maxAttempts;
+	// false berarti jatah tebakan habis atau kode sudah dipakai.
+	ReserveAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) (bool, error)
+	// MarkUsed menandai kode terpakai hanya jika belum dipakai; false berarti sudah dipakai request lain.
+	MarkUsed(ctx context.Context, id uuid.UUID) (bool, error)
+	// InvalidateAll menandai semua kode aktif user untuk tujuan tertentu sebagai terpakai.
+	InvalidateAll(ctx context.Context, userID uuid.UUID, purpose string) error
+	// CountSince menghitung kode yang dibuat sejak waktu tertentu (untuk rate limit).
+	CountSince(ctx context.Context, userID uuid.UUID, purpose string, since time.Time) (int64, error)
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+)
+
+// PolicyRepository mendefinisikan penyimpanan policy Casbin di database.
+// Setiap operasi tulis menaikkan versi policy di transaksi yang sama agar instance lain tahu harus reload.
+type PolicyRepository interface {
+	// List mengembalikan semua rule.
+	List(ctx context.Context) ([]domain.CasbinRule, error)
+	// Add menyimpan rule baru.
+	Add(ctx context.Context, rules []domain.CasbinRule) error
+	// Remove menghapus rule yang sama persis.
+	Remove(ctx context.Context, rules []domain.CasbinRule) error
+	// RemoveFiltered menghapus rule ptype tertentu yang kolomnya (mulai fieldIndex) cocok dengan values; nilai kosong berarti bebas.
+	RemoveFiltered(ctx context.Context, ptype string, fieldIndex int, values []string) error
+	// ReplaceAll mengganti seluruh isi tabel dengan rules.
+	ReplaceAll(ctx context.Context, rules []domain.CasbinRule) error
+	// SeedIfEmpty mengisi tabel dengan rules hanya jika masih kosong; false jika tabel sudah berisi.
+	SeedIfEmpty(ctx context.Context, rules []domain.CasbinRule) (bool, error)
+	// Version mengembalikan versi policy saat ini.
+	Version(ctx context.Context) (int64, error)
+}
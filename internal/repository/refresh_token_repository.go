@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/itujun/project-ecommerce-go-next/internal/domain"
@@ -11,6 +12,20 @@ type RefreshTokenRepository interface {
 	Save(ctx context.Context, rt *domain.RefreshToken) error
 	FindByID(ctx context.Context, id uuid.UUID) (*domain.RefreshToken, error)
 	Revoke(ctx context.Context, id uuid.UUID) error
+	// RevokeIfActive mencabut RT hanya jika belum dicabut; false berarti request lain sudah mencabutnya.
+	RevokeIfActive(ctx context.Context, id uuid.UUID) (bool, error)
+	// RevokeFamily mencabut seluruh RT dalam satu family (rantai rotasi).
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	// SetAccessTokenID mencatat jti AT terakhir pada RT aktif di family (sesi).
+	SetAccessTokenID(ctx context.Context, familyID uuid.UUID, jti string) error
 	RevokeAllByUser(ctx context.Context, userID uuid.UUID) error
+	// RevokeAllByUserExceptFamily mencabut semua RT user kecuali milik family (sesi) tertentu.
+	RevokeAllByUserExceptFamily(ctx context.Context, userID, familyID uuid.UUID) error
+	// ListActiveByUser mengembalikan RT yang belum dicabut & belum kedaluwarsa (satu per sesi).
+	ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]domain.RefreshToken, error)
 	Update(ctx context.Context, rt *domain.RefreshToken) error
+	// DeleteExpiredBefore menghapus maksimal limit RT yang kedaluwarsa sebelum waktu tertentu.
+	DeleteExpiredBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+	// DeleteRevokedBefore menghapus maksimal limit RT yang dicabut sebelum waktu tertentu.
+	DeleteRevokedBefore(ctx context.Context, before time.Time, limit int) (int64, error)
 }
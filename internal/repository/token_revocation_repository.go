@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenRevocationRepository menyimpan denylist AT: per jti dan per "diterbitkan sebelum T untuk user U".
+// Implementasi memory cocok untuk satu instance; implementasi database berbagi state antar instance.
+type TokenRevocationRepository interface {
+	RevokeJTI(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error
+	IsJTIRevoked(ctx context.Context, jti string) (bool, error)
+	// RevokeUserBefore menyimpan cutoff user; cutoff yang lebih baru menggantikan yang lama.
+	RevokeUserBefore(ctx context.Context, userID uuid.UUID, before, expiresAt time.Time) error
+	// UserRevokedBefore mengembalikan cutoff aktif user; ok false jika tidak ada.
+	UserRevokedBefore(ctx context.Context, userID uuid.UUID) (before time.Time, ok bool, err error)
+	// DeleteExpired menghapus entri yang sudah melewati ExpiresAt.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
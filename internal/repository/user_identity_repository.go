@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+)
+
+// UserIdentityRepository mendefinisikan operasi untuk identitas login sosial.
+type UserIdentityRepository interface {
+	// FindBySubject mengembalikan (nil, nil) jika identitas belum tertaut ke user mana pun.
+	FindBySubject(ctx context.Context, provider, subject string) (*domain.UserIdentity, error)
+	Create(ctx context.Context, identity *domain.UserIdentity) error
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.UserIdentity, error)
+	// Touch memperbarui email terakhir dan waktu login dari provider.
+	Touch(ctx context.Context, id uuid.UUID, email string, at time.Time) error
+}
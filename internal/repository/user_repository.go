@@ -2,16 +2,70 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/itujun/project-ecommerce-go-next/internal/domain"
 )
 
+// ErrDuplicateEmail dikembalikan CreateUser jika email sudah dipakai user lain (unique index).
+var ErrDuplicateEmail = errors.New("email sudah dipakai")
+
+// ErrDuplicatePhone dikembalikan SetPhone jika nomor HP sudah dipakai user lain (unique index).
+var ErrDuplicatePhone = errors.New("nomor HP sudah dipakai")
+
+// Nilai UserFilter.Status.
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+)
+
+// UserFilter adalah kriteria pencarian user untuk halaman admin.
+type UserFilter struct {
+	Query  string     // dicocokkan sebagian dengan nama atau email
+	RoleID *uuid.UUID // nil berarti semua role
+	Status string     // "", UserStatusActive, atau UserStatusSuspended
+	Limit  int
+	Offset int
+}
+
 // UserRepository mendefinisikan operasi untuk entitas User.
 type UserRepository interface {
+	// CreateUser mengembalikan ErrDuplicateEmail jika email sudah terdaftar.
 	CreateUser(ctx context.Context, user *domain.User) error
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	// GetUserByPhone mencari user berdasarkan nomor HP terverifikasi (E.164).
+	GetUserByPhone(ctx context.Context, phone string) (*domain.User, error)
 	ListUsers(ctx context.Context) ([]domain.User, error)
+	MarkEmailVerified(ctx context.Context, id uuid.UUID, at time.Time) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
+	UpdateName(ctx context.Context, id uuid.UUID, name string) error
+	// SetPendingEmail menyimpan email baru yang menunggu konfirmasi; nil untuk membatalkan.
+	SetPendingEmail(ctx context.Context, id uuid.UUID, email *string) error
+	// ChangeEmail mengganti email, menandainya terverifikasi, dan mengosongkan pending_email.
+	ChangeEmail(ctx context.Context, id uuid.UUID, email string, verifiedAt time.Time) error
+	// SetPhone menyimpan nomor HP yang sudah diverifikasi; nil menghapus nomor sekaligus menonaktifkan 2FA SMS.
+	// Mengembalikan ErrDuplicatePhone jika nomor sudah dipakai user lain.
+	SetPhone(ctx context.Context, id uuid.UUID, phone *string, verifiedAt *time.Time) error
+	// SetPhoneMFA mengisi phone_mfa_enabled_at; nil berarti 2FA SMS dinonaktifkan.
+	SetPhoneMFA(ctx context.Context, id uuid.UUID, at *time.Time) error
+	// ScheduleDeletion mengisi deletion_scheduled_at; nil untuk membatalkan penghapusan.
+	ScheduleDeletion(ctx context.Context, id uuid.UUID, at *time.Time) error
+	// ListDueForDeletion mengembalikan user yang jadwal penghapusannya sudah lewat dan belum dianonimkan.
+	ListDueForDeletion(ctx context.Context, now time.Time, limit int) ([]domain.User, error)
+	// AnonymizeUser menghapus PII user secara permanen dalam satu transaksi: profil diganti placeholder,
+	// data autentikasi (RT, 2FA, identitas sosial, token, OTP, API key, passkey) dihapus, lalu user di-soft delete.
+	// Pesanan tetap tersimpan untuk keperluan akuntansi.
+	AnonymizeUser(ctx context.Context, id uuid.UUID, at time.Time) error
+	// SearchUsers mengembalikan satu halaman user sesuai filter beserta total seluruh hasil.
+	SearchUsers(ctx context.Context, filter UserFilter) ([]domain.User, int64, error)
+	UpdateRole(ctx context.Context, id, roleID uuid.UUID) error
+	// SetSuspended mengisi suspended_at; nil berarti mengaktifkan kembali.
+	SetSuspended(ctx context.Context, id uuid.UUID, at *time.Time) error
+	// SoftDeleteUser mengisi deleted_at; user tidak lagi ditemukan oleh query lain.
+	// Email diganti placeholder agar alamat aslinya bisa dipakai mendaftar lagi.
+	SoftDeleteUser(ctx context.Context, id uuid.UUID) error
 }
 
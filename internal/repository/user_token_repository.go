@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+)
+
+// UserTokenRepository mendefinisikan operasi untuk token sekali pakai milik user.
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *domain.UserToken) error
+	FindByHash(ctx context.Context, purpose, tokenHash string) (*domain.UserToken, error)
+	// MarkUsed menandai token terpakai hanya jika belum dipakai; false berarti sudah dipakai request lain.
+	MarkUsed(ctx context.Context, id uuid.UUID) (bool, error)
+	// InvalidateAll menandai semua token aktif user untuk tujuan tertentu sebagai terpakai.
+	InvalidateAll(ctx context.Context, userID uuid.UUID, purpose string) error
+	// CountSince menghitung token yang dibuat sejak waktu tertentu (untuk rate limit).
+	CountSince(ctx context.Context, userID uuid.UUID, purpose string, since time.Time) (int64, error)
+}
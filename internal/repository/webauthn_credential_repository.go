@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+)
+
+// WebAuthnCredentialRepository mendefinisikan operasi untuk passkey milik user.
+type WebAuthnCredentialRepository interface {
+	Create(ctx context.Context, credential *domain.WebAuthnCredential) error
+	// FindByCredentialID mengembalikan (nil, nil) jika credential tidak dikenal.
+	FindByCredentialID(ctx context.Context, credentialID []byte) (*domain.WebAuthnCredential, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.WebAuthnCredential, error)
+	// UpdateAfterLogin menyimpan sign count, flag, clone warning, dan waktu pemakaian terakhir.
+	UpdateAfterLogin(ctx context.Context, credential *domain.WebAuthnCredential) error
+	// Delete menghapus credential milik user; false jika tidak ada atau bukan milik user.
+	Delete(ctx context.Context, userID, id uuid.UUID) (bool, error)
+}
@@ -6,25 +6,44 @@ import (
 	"github.com/casbin/casbin/v2"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
+	"github.com/itujun/project-ecommerce-go-next/internal/authorization"
 	"github.com/itujun/project-ecommerce-go-next/internal/handler"
 	"github.com/itujun/project-ecommerce-go-next/internal/middleware"
 )
 
 // NewRouter menginisialisasi router Chi dan mendaftarkan rute dasar.
-// NewRouter menerima authHandler, productHandler, JWTMiddleware, dan enforcer Casbin.
+// NewRouter menerima authHandler, productHandler, Authenticator, enforcer Casbin,
+// dan owners (pemuat pemilik resource untuk aturan kepemilikan di policy).
 func NewRouter(
     authHandler *handler.AuthHandler, 
+    sessionHandler *handler.SessionHandler, 
+    verificationHandler *handler.EmailVerificationHandler, 
+    passwordResetHandler *handler.PasswordResetHandler, 
+    magicLinkHandler *handler.MagicLinkHandler, 
+    webauthnHandler *handler.WebAuthnHandler, 
+    phoneHandler *handler.PhoneHandler, 
+    profileHandler *handler.ProfileHandler, 
+    privacyHandler *handler.PrivacyHandler, 
+    jwksHandler *handler.JWKSHandler, 
+    mfaHandler *handler.MFAHandler, 
+    adminUserHandler *handler.AdminUserHandler, 
+    impersonationHandler *handler.ImpersonationHandler, 
+    oauthHandler *handler.OAuthHandler, 
+    apiKeyHandler *handler.APIKeyHandler, 
+    policyHandler *handler.PolicyHandler, 
+    roleHandler *handler.RoleHandler, 
     productHandler *handler.ProductHandler, 
     orderHandler *handler.OrderHandler, 
-    jwtMiddleware *middleware.JWTMiddleware, 
-    enforcer *casbin.Enforcer) *chi.Mux {
+    authenticator *middleware.Authenticator, 
+    enforcer *casbin.SyncedEnforcer, 
+    owners authorization.Owners) *chi.Mux {
     r := chi.NewRouter()
 
     // Konfigurasi CORS
     corsHandler := cors.New(cors.Options{
         AllowedOrigins:   []string{"http://localhost:3000"}, // domain front‑end
-        AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-        AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+        AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+        AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
         AllowCredentials: true, // supaya cookie ikut terkirim
     })
     r.Use(corsHandler.Handler)
@@ -34,51 +53,217 @@ func NewRouter(
         w.WriteHeader(http.StatusOK)
         _, _ = w.Write([]byte("OK"))
     })
+    // Kunci publik untuk verifikasi access token (RS256/EdDSA)
+    r.Get("/.well-known/jwks.json", jwksHandler.JWKS)
+
     // Authentication routes / Grup rute auth
     r.Route("/auth", func(r chi.Router) {
         r.Post("/register", authHandler.Register)
         r.Post("/login", authHandler.Login)
-        r.Post("/refresh", authHandler.Refresh)
-        r.Post("/logout", authHandler.Logout)
-        r.Get("/me", authHandler.Me)
+        // Refresh, logout, dan konfirmasi ganti email memakai cookie sesi tanpa Middleware → cek CSRF terpisah
+        r.With(authenticator.CSRF).Post("/refresh", authHandler.Refresh)
+        r.With(authenticator.CSRF).Post("/logout", authHandler.Logout)
+        r.Post("/verify-email", verificationHandler.VerifyEmail)
+        r.Post("/verify-email/resend", verificationHandler.ResendVerification)
+        r.Post("/password/forgot", passwordResetHandler.Forgot)
+        r.Post("/password/reset", passwordResetHandler.Reset)
+        // Login tanpa password: link sekali pakai via email. Callback (GET) hanya meneruskan token
+        // ke frontend; token baru dipakai dan cookie sesi diset lewat POST consume
+        r.Post("/magic-link", magicLinkHandler.Request)
+        r.Get("/magic-link/callback", magicLinkHandler.Callback)
+        r.Post("/magic-link/consume", magicLinkHandler.Consume)
+        // Login dengan passkey (WebAuthn discoverable credential)
+        r.Post("/webauthn/login/begin", webauthnHandler.LoginBegin)
+        r.Post("/webauthn/login/finish", webauthnHandler.LoginFinish)
+        // Login dengan nomor HP: kode OTP dikirim via SMS
+        r.Post("/phone/login", phoneHandler.RequestLoginCode)
+        r.Post("/phone/login/verify", phoneHandler.Login)
+        r.With(authenticator.CSRF).Post("/me/email/confirm", profileHandler.ConfirmEmailChange)
+        // Profil & password milik user yang login
+        r.Group(func(r chi.Router) {
+            r.Use(authenticator.Middleware)
+            r.Get("/me", authHandler.Me)
+            r.Get("/me/phone", phoneHandler.Get)
+            // Admin yang meng-impersonate tidak boleh mengubah kredensial/PII atau mengekspor data user
+            r.Group(func(r chi.Router) {
+                r.Use(middleware.DenyImpersonation)
+                r.Patch("/me", profileHandler.UpdateProfile)
+                r.Post("/me/password", profileHandler.ChangePassword)
+                // Nomor HP: kode OTP ke nomor baru, nomor tersimpan setelah kode dikonfirmasi
+                r.Put("/me/phone", phoneHandler.RequestVerification)
+                r.Post("/me/phone/verify", phoneHandler.ConfirmVerification)
+                r.Delete("/me/phone", phoneHandler.Remove)
+                // Ekspor data pribadi & penghapusan akun (dengan masa tenggang)
+                r.Post("/me/export", privacyHandler.Export)
+                r.Post("/me/deletion", privacyHandler.RequestDeletion)
+                r.Delete("/me/deletion", privacyHandler.CancelDeletion)
+            })
+            // Mengakhiri impersonasi: AT impersonasi yang dipakai langsung dicabut
+            r.Post("/impersonation/stop", impersonationHandler.Stop)
+        })
+        // Sesi perangkat milik user yang login
+        r.Group(func(r chi.Router) {
+            r.Use(authenticator.Middleware)
+            r.Get("/sessions", sessionHandler.ListSessions)
+            r.With(middleware.DenyImpersonation).Delete("/sessions/{id}", sessionHandler.RevokeSession)
+            r.With(middleware.DenyImpersonation).Post("/sessions/logout-others", sessionHandler.RevokeOtherSessions)
+        })
+        // Passkey milik user; pendaftaran butuh sesi login dan tidak boleh saat impersonasi
+        r.Group(func(r chi.Router) {
+            r.Use(authenticator.Middleware)
+            r.Get("/webauthn/credentials", webauthnHandler.ListCredentials)
+            r.Group(func(r chi.Router) {
+                r.Use(middleware.DenyImpersonation)
+                r.Post("/webauthn/register/begin", webauthnHandler.RegisterBegin)
+                r.Post("/webauthn/register/finish", webauthnHandler.RegisterFinish)
+                r.Delete("/webauthn/credentials/{id}", webauthnHandler.DeleteCredential)
+            })
+        })
+        // API key milik user; dikelola hanya lewat sesi (JWT), bukan dengan API key lain
+        r.Group(func(r chi.Router) {
+            r.Use(authenticator.Middleware)
+            r.Use(middleware.DenyImpersonation)
+            r.Use(middleware.Authorize(enforcer, "apikey", "manage"))
+            r.Get("/api-keys", apiKeyHandler.List)
+            r.Post("/api-keys", apiKeyHandler.Create)
+            r.Delete("/api-keys/{id}", apiKeyHandler.Revoke)
+        })
+        // Login sosial OIDC; start dengan sesi aktif berarti menautkan akun
+        r.With(authenticator.Optional).Get("/oauth/{provider}/start", oauthHandler.Start)
+        r.Get("/oauth/{provider}/callback", oauthHandler.Callback)
+        // 2FA (TOTP/SMS): verify adalah langkah kedua login; enroll dan kirim kode SMS bisa memakai sesi atau mfa_token
+        r.Post("/mfa/verify", mfaHandler.Verify)
+        r.Group(func(r chi.Router) {
+            r.Use(authenticator.Optional)
+            r.Use(middleware.DenyImpersonation)
+            r.Post("/mfa/enroll", mfaHandler.Enroll)
+            r.Post("/mfa/enroll/confirm", mfaHandler.ConfirmEnrollment)
+            r.Post("/mfa/sms/send", mfaHandler.SendSMSCode)
+        })
+        r.Group(func(r chi.Router) {
+            r.Use(authenticator.Middleware)
+            r.Get("/mfa", mfaHandler.Status)
+            r.With(middleware.DenyImpersonation).Delete("/mfa", mfaHandler.Disable)
+            r.With(middleware.DenyImpersonation).Post("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+            r.With(middleware.DenyImpersonation).Post("/mfa/sms", mfaHandler.EnableSMS)
+            r.With(middleware.DenyImpersonation).Delete("/mfa/sms", mfaHandler.DisableSMS)
+        })
+    })
+    // Admin routes / Grup rute administrasi user
+    r.Route("/admin/users", func(r chi.Router) {
+        r.Group(func(r chi.Router) {
+            r.Use(authenticator.Middleware)
+            r.Use(middleware.Authorize(enforcer, "user", "read"))
+            r.Get("/", adminUserHandler.List)
+            r.Get("/{id}", adminUserHandler.Get)
+        })
+        r.Group(func(r chi.Router) {
+            r.Use(authenticator.Middleware)
+            r.Use(middleware.Authorize(enforcer, "user", "update"))
+            r.Put("/{id}/role", adminUserHandler.ChangeRole)
+        })
+        r.Group(func(r chi.Router) {
+            r.Use(authenticator.Middleware)
+            r.Use(middleware.Authorize(enforcer, "user", "suspend"))
+            r.Post("/{id}/suspend", adminUserHandler.Suspend)
+            r.Post("/{id}/reactivate", adminUserHandler.Reactivate)
+        })
+        r.Group(func(r chi.Router) {
+            r.Use(authenticator.Middleware)
+            r.Use(middleware.Authorize(enforcer, "user", "delete"))
+            r.Delete("/{id}", adminUserHandler.Delete)
+        })
+        r.Group(func(r chi.Router) {
+            r.Use(authenticator.Middleware)
+            r.Use(middleware.Authorize(enforcer, "user", "unlock"))
+            r.Post("/{id}/unlock", adminUserHandler.Unlock)
+        })
+        // Impersonasi untuk support: AT Bearer atas nama user, dicatat di audit log
+        r.Group(func(r chi.Router) {
+            r.Use(authenticator.Middleware)
+            r.Use(middleware.DenyImpersonation)
+            r.Use(middleware.Authorize(enforcer, "user", "impersonate"))
+            r.Post("/{id}/impersonate", impersonationHandler.Start)
+        })
+    })
+    // Jejak audit (impersonasi dan mutasi selama impersonasi)
+    r.Route("/admin/audit-logs", func(r chi.Router) {
+        r.Use(authenticator.Middleware)
+        r.Use(middleware.Authorize(enforcer, "audit", "read"))
+        r.Get("/", impersonationHandler.ListAuditLogs)
+    })
+    // Policy Casbin (rule p & g); perubahan berlaku di semua instance setelah CASBIN_POLICY_POLL_INTERVAL
+    r.Route("/admin/policies", func(r chi.Router) {
+        r.Use(authenticator.Middleware)
+        r.Use(middleware.Authorize(enforcer, "policy", "manage"))
+        r.Get("/", policyHandler.List)
+        r.Group(func(r chi.Router) {
+            r.Use(middleware.DenyImpersonation)
+            r.Post("/", policyHandler.AddPolicy)
+            r.Delete("/", policyHandler.RemovePolicy)
+            r.Post("/groupings", policyHandler.AddGrouping)
+            r.Delete("/groupings", policyHandler.RemoveGrouping)
+        })
+    })
+    // Role: admin melihat daftar role, hanya super_admin yang membuat role kustom.
+    // Izin role baru diberikan lewat /admin/policies.
+    r.Route("/admin/roles", func(r chi.Router) {
+        r.Use(authenticator.Middleware)
+        r.Group(func(r chi.Router) {
+            r.Use(middleware.Authorize(enforcer, "role", "read"))
+            r.Get("/", roleHandler.List)
+        })
+        r.Group(func(r chi.Router) {
+            r.Use(middleware.DenyImpersonation)
+            r.Use(middleware.Authorize(enforcer, "role", "manage"))
+            r.Post("/", roleHandler.Create)
+        })
     })
     // Product routes / Grup rute product
     r.Route("/products", func(r chi.Router) {
         r.Get("/", productHandler.ListProducts)     // publik
         r.Get("/{id}", productHandler.GetProduct)   // publik
-        // Endpoints di bawah ini dilindungi JWT dan Casbin.
+        // Endpoints di bawah ini dilindungi Authenticator (cookie, Bearer, atau ApiKey) dan Casbin.
         r.Group(func(r chi.Router)  {
-            r.Use(jwtMiddleware.Middleware)                             // parse token
+            r.Use(authenticator.AllowAPIKey)                            // parse token atau API key
             r.Use(middleware.Authorize(enforcer, "product", "create"))  // role cek
             r.Post("/", productHandler.CreateProduct)
         })
         r.Group(func(r chi.Router)  {
-            r.Use(jwtMiddleware.Middleware)                             // parse token
-            r.Use(middleware.Authorize(enforcer, "product", "update"))  // role cek
+            r.Use(authenticator.AllowAPIKey)                            // parse token atau API key
+            r.Use(middleware.AuthorizeResource(enforcer, owners, "product", "update"))  // role + kepemilikan
             r.Put("/{id}", productHandler.UpdateProduct)
         })
         r.Group(func(r chi.Router)  {
-            r.Use(jwtMiddleware.Middleware)                             // parse token
-            r.Use(middleware.Authorize(enforcer, "product", "delete"))  // role cek
+            r.Use(authenticator.AllowAPIKey)                            // parse token atau API key
+            r.Use(middleware.AuthorizeResource(enforcer, owners, "product", "delete"))  // role + kepemilikan
             r.Delete("/{id}", productHandler.DeleteProduct)
         })
-        // Di sini, Authorize membutuhkan dua parameter: nama resource (product) dan action (create, update, delete). Peran (role) pengguna diambil dari token, kemudian dicek terhadap policy Casbin.
+        // Di sini, Authorize membutuhkan dua parameter: nama resource (product) dan action (create, update, delete). Peran (role) pengguna diambil dari Principal, kemudian dicek terhadap policy Casbin.
+        // AuthorizeResource juga memuat pemilik produk dari {id} sehingga syarat kepemilikan (r.res.Owner == r.sub.ID) di policy ikut dievaluasi.
     })
 
     // Order routes / Grup rute order
     r.Route("/orders", func(r chi.Router)  {
         // rute untuk create order: hanya pembeli (buyer) yang diizinkan
         r.Group(func(r chi.Router)  {
-            r.Use(jwtMiddleware.Middleware)    
+            r.Use(authenticator.AllowAPIKey)
+            r.Use(middleware.DenyImpersonation) // checkout/pembayaran tidak boleh atas nama user
             r.Use(middleware.Authorize(enforcer, "order", "create"))
             r.Post("/", orderHandler.CreateOrder)
         })
         // rute untuk list orders: buyer melihat pesanan sendiri, admin & seller semua
         r.Group(func(r chi.Router)  {
-            r.Use(jwtMiddleware.Middleware)
+            r.Use(authenticator.AllowAPIKey)
             r.Use(middleware.Authorize(enforcer, "order", "read"))
             r.Get("/", orderHandler.ListOrders)
         })
+        // rute detail order: kepemilikan dicek policy (buyer hanya pesanan sendiri)
+        r.Group(func(r chi.Router)  {
+            r.Use(authenticator.AllowAPIKey)
+            r.Use(middleware.AuthorizeResource(enforcer, owners, "order", "read"))
+            r.Get("/{id}", orderHandler.GetOrder)
+        })
     })
 
     return r
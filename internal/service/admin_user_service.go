@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrAccountSuspended dikembalikan saat akun yang ditangguhkan mencoba login atau refresh.
+	ErrAccountSuspended = errors.New("akun ditangguhkan, hubungi admin")
+	// ErrCannotModifySelf dikembalikan saat admin mencoba mengubah role, menangguhkan, mengaktifkan kembali, atau menghapus akunnya sendiri.
+	ErrCannotModifySelf = errors.New("tidak dapat mengubah akun sendiri")
+	// ErrRoleNotFound dikembalikan saat role yang dituju tidak ada.
+	ErrRoleNotFound = errors.New("role tidak ditemukan")
+	// ErrSuperAdminOnly dikembalikan saat selain super admin mencoba memberi role super_admin atau mengubah akun super admin.
+	ErrSuperAdminOnly = errors.New("hanya super admin yang dapat mengelola akun super admin")
+)
+
+// AdminUserService menyediakan operasi administrasi user: daftar, ganti role, suspend, dan hapus.
+type AdminUserService struct {
+	userRepo   repository.UserRepository
+	roleRepo   repository.RoleRepository
+	rtRepo     repository.RefreshTokenRepository
+	revocation *TokenRevocationService
+	enforcer   *casbin.SyncedEnforcer
+	validator  *validator.Validate
+	logger     *zap.Logger
+}
+
+// NewAdminUserService membuat instance AdminUserService baru.
+func NewAdminUserService(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	rtRepo repository.RefreshTokenRepository,
+	revocation *TokenRevocationService,
+	enforcer *casbin.SyncedEnforcer,
+	logger *zap.Logger,
+) *AdminUserService {
+	return &AdminUserService{
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+		rtRepo:     rtRepo,
+		revocation: revocation,
+		enforcer:   enforcer,
+		validator:  validator.New(),
+		logger:     logger,
+	}
+}
+
+// List mencari user dengan filter nama/email, role, dan status, dengan paginasi.
+func (s *AdminUserService) List(ctx context.Context, query dto.ListUsersQuery) (*dto.UserListResponse, error) {
+	if err := s.validator.Struct(query); err != nil {
+		return nil, err
+	}
+	filter := repository.UserFilter{
+		Query:  strings.TrimSpace(query.Query),
+		Status: query.Status,
+		Limit:  query.PageSize,
+		Offset: (query.Page - 1) * query.PageSize,
+	}
+	if query.Role != "" {
+		role, err := s.roleRepo.GetRoleByName(ctx, query.Role)
+		if err != nil {
+			return nil, ErrRoleNotFound
+		}
+		filter.RoleID = &role.ID
+	}
+
+	users, total, err := s.userRepo.SearchUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.UserListResponse{
+		Users:    make([]dto.AdminUserResponse, 0, len(users)),
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+	for i := range users {
+		res.Users = append(res.Users, adminUserResponse(&users[i]))
+	}
+	return res, nil
+}
+
+// Get mengembalikan detail satu user.
+func (s *AdminUserService) Get(ctx context.Context, id uuid.UUID) (*dto.AdminUserResponse, error) {
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	res := adminUserResponse(user)
+	return &res, nil
+}
+
+// ChangeRole mengganti role user. AT lama dicabut agar role baru berlaku segera;
+// sesi (RT) tetap hidup sehingga user cukup refresh untuk mendapat AT dengan role baru.
+func (s *AdminUserService) ChangeRole(ctx context.Context, actorID, id uuid.UUID, req dto.ChangeUserRoleRequest) (*dto.AdminUserResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	if actorID == id {
+		return nil, ErrCannotModifySelf
+	}
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	role, err := s.roleRepo.GetRoleByID(ctx, uuid.MustParse(req.RoleID))
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+	if err := s.requireSuperAdmin(ctx, actorID, user.Role.Name, role.Name); err != nil {
+		return nil, err
+	}
+	if user.RoleID != role.ID {
+		if err := s.userRepo.UpdateRole(ctx, id, role.ID); err != nil {
+			return nil, err
+		}
+		if err := s.revocation.RevokeUser(ctx, id); err != nil {
+			return nil, err
+		}
+		s.logger.Info("role user diganti oleh admin",
+			zap.String("user_id", id.String()),
+			zap.String("actor_id", actorID.String()),
+			zap.String("from", user.Role.Name),
+			zap.String("to", role.Name),
+		)
+	}
+	user.RoleID, user.Role = role.ID, *role
+	res := adminUserResponse(user)
+	return &res, nil
+}
+
+// Suspend menangguhkan akun: login ditolak, semua sesi (RT) dan AT dicabut, API key ikut ditolak.
+func (s *AdminUserService) Suspend(ctx context.Context, actorID, id uuid.UUID) (*dto.AdminUserResponse, error) {
+	if actorID == id {
+		return nil, ErrCannotModifySelf
+	}
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if err := s.requireSuperAdmin(ctx, actorID, user.Role.Name); err != nil {
+		return nil, err
+	}
+	if !user.Suspended() {
+		now := time.Now()
+		if err := s.userRepo.SetSuspended(ctx, id, &now); err != nil {
+			return nil, err
+		}
+		user.SuspendedAt = &now
+	}
+	if err := s.revokeAll(ctx, id); err != nil {
+		return nil, err
+	}
+	s.logger.Info("akun ditangguhkan oleh admin", zap.String("user_id", id.String()), zap.String("actor_id", actorID.String()))
+	res := adminUserResponse(user)
+	return &res, nil
+}
+
+// Reactivate mengaktifkan kembali akun yang ditangguhkan. User perlu login ulang.
+func (s *AdminUserService) Reactivate(ctx context.Context, actorID, id uuid.UUID) (*dto.AdminUserResponse, error) {
+	if actorID == id {
+		return nil, ErrCannotModifySelf
+	}
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if err := s.requireSuperAdmin(ctx, actorID, user.Role.Name); err != nil {
+		return nil, err
+	}
+	if user.Suspended() {
+		if err := s.userRepo.SetSuspended(ctx, id, nil); err != nil {
+			return nil, err
+		}
+		user.SuspendedAt = nil
+		s.logger.Info("akun diaktifkan kembali oleh admin", zap.String("user_id", id.String()), zap.String("actor_id", actorID.String()))
+	}
+	res := adminUserResponse(user)
+	return &res, nil
+}
+
+// Delete melakukan soft delete (deleted_at) lalu mencabut semua sesi dan AT user.
+func (s *AdminUserService) Delete(ctx context.Context, actorID, id uuid.UUID) error {
+	if actorID == id {
+		return ErrCannotModifySelf
+	}
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if err := s.requireSuperAdmin(ctx, actorID, user.Role.Name); err != nil {
+		return err
+	}
+	if err := s.userRepo.SoftDeleteUser(ctx, id); err != nil {
+		return err
+	}
+	if err := s.revokeAll(ctx, id); err != nil {
+		return err
+	}
+	s.logger.Info("akun dihapus (soft delete) oleh admin", zap.String("user_id", id.String()), zap.String("actor_id", actorID.String()))
+	return nil
+}
+
+// requireSuperAdmin menolak aksi yang menyentuh role super_admin (role lama/baru target) jika aktor bukan super admin,
+// sehingga admin tidak bisa menaikkan dirinya lewat akun lain atau menangguhkan/menghapus super admin.
+// Role kustom yang mewarisi super_admin lewat rule g diperlakukan sama dengan super_admin.
+func (s *AdminUserService) requireSuperAdmin(ctx context.Context, actorID uuid.UUID, roleNames ...string) error {
+	touchesSuperAdmin := false
+	for _, name := range roleNames {
+		ok, err := s.isSuperAdmin(name)
+		if err != nil {
+			return err
+		}
+		touchesSuperAdmin = touchesSuperAdmin || ok
+	}
+	if !touchesSuperAdmin {
+		return nil
+	}
+	actor, err := s.userRepo.GetUserByID(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	ok, err := s.isSuperAdmin(actor.Role.Name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSuperAdminOnly
+	}
+	return nil
+}
+
+// isSuperAdmin memeriksa apakah role adalah super_admin atau mewarisinya (langsung/tidak langsung) di policy Casbin.
+func (s *AdminUserService) isSuperAdmin(roleName string) (bool, error) {
+	if roleName == domain.RoleSuperAdmin {
+		return true, nil
+	}
+	roles, err := s.enforcer.GetImplicitRolesForUser(roleName)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(roles, domain.RoleSuperAdmin), nil
+}
+
+// revokeAll mencabut semua RT (sesi) dan AT user.
+func (s *AdminUserService) revokeAll(ctx context.Context, userID uuid.UUID) error {
+	if err := s.rtRepo.RevokeAllByUser(ctx, userID); err != nil {
+		return err
+	}
+	return s.revocation.RevokeUser(ctx, userID)
+}
+
+func adminUserResponse(user *domain.User) dto.AdminUserResponse {
+	res := dto.AdminUserResponse{
+		ID:        user.ID.String(),
+		Name:      user.Name,
+		Email:     user.Email,
+		RoleID:    user.RoleID.String(),
+		Role:      user.Role.Name,
+		CreatedAt: user.CreatedAt.Format(time.RFC3339),
+	}
+	if user.EmailVerifiedAt != nil {
+		v := user.EmailVerifiedAt.Format(time.RFC3339)
+		res.EmailVerifiedAt = &v
+	}
+	if user.SuspendedAt != nil {
+		v := user.SuspendedAt.Format(time.RFC3339)
+		res.SuspendedAt = &v
+	}
+	return res
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	apiKeyPrefix        = "ek"
+	maxAPIKeysPerUser   = 10
+	apiKeyTouchInterval = time.Minute // last_used_at diperbarui paling sering sekali per menit
+)
+
+var (
+	// ErrInvalidAPIKey dikembalikan untuk key yang tidak dikenal, salah, dicabut, atau kedaluwarsa.
+	ErrInvalidAPIKey = errors.New("api key tidak valid")
+	// ErrInvalidAPIKeyScope dikembalikan saat scope tidak termasuk domain.APIKeyScopes.
+	ErrInvalidAPIKeyScope = errors.New("scope tidak dikenal")
+	// ErrAPIKeyLimit dikembalikan saat user sudah memiliki terlalu banyak key aktif.
+	ErrAPIKeyLimit = fmt.Errorf("maksimal %d api key aktif per user", maxAPIKeysPerUser)
+	// ErrAPIKeyNotFound dikembalikan saat key tidak ada atau bukan milik user.
+	ErrAPIKeyNotFound = errors.New("api key tidak ditemukan")
+)
+
+// APIKeyService mengelola API key untuk integrasi machine-to-machine.
+type APIKeyService struct {
+	keyRepo   repository.APIKeyRepository
+	userRepo  repository.UserRepository
+	validator *validator.Validate
+	logger    *zap.Logger
+}
+
+// NewAPIKeyService membuat instance APIKeyService baru.
+func NewAPIKeyService(keyRepo repository.APIKeyRepository, userRepo repository.UserRepository, logger *zap.Logger) *APIKeyService {
+	return &APIKeyService{
+		keyRepo:   keyRepo,
+		userRepo:  userRepo,
+		validator: validator.New(),
+		logger:    logger,
+	}
+}
+
+// Create membuat API key baru. Key plaintext hanya dikembalikan sekali di sini.
+func (s *APIKeyService) Create(ctx context.Context, userID uuid.UUID, req dto.CreateAPIKeyRequest) (*dto.CreatedAPIKeyResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !slices.Contains(domain.APIKeyScopes, scope) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAPIKeyScope, scope)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	count, err := s.keyRepo.CountActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxAPIKeysPerUser {
+		return nil, ErrAPIKeyLimit
+	}
+
+	prefix, secret, err := newAPIKeyParts()
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat api key: %w", err)
+	}
+	plain := apiKeyPrefix + "_" + prefix + "_" + secret
+	key := &domain.APIKey{
+		ID:      uuid.New(),
+		UserID:  userID,
+		Name:    strings.TrimSpace(req.Name),
+		Prefix:  prefix,
+		KeyHash: hashOpaqueToken(plain),
+		Scopes:  strings.Join(scopes, ","),
+	}
+	if req.ExpiresInDays > 0 {
+		exp := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &exp
+	}
+	if err := s.keyRepo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+	s.logger.Info("api key dibuat",
+		zap.String("user_id", userID.String()),
+		zap.String("api_key_id", key.ID.String()),
+		zap.Strings("scopes", scopes),
+	)
+	return &dto.CreatedAPIKeyResponse{APIKeyResponse: apiKeyResponse(key), Key: plain}, nil
+}
+
+// List mengembalikan API key user yang belum dicabut.
+func (s *APIKeyService) List(ctx context.Context, userID uuid.UUID) ([]dto.APIKeyResponse, error) {
+	keys, err := s.keyRepo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		res = append(res, apiKeyResponse(&keys[i]))
+	}
+	return res, nil
+}
+
+// Revoke mencabut API key milik user.
+func (s *APIKeyService) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	ok, err := s.keyRepo.Revoke(ctx, userID, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrAPIKeyNotFound
+	}
+	s.logger.Info("api key dicabut", zap.String("user_id", userID.String()), zap.String("api_key_id", id.String()))
+	return nil
+}
+
+// Authenticate memverifikasi key plaintext lalu mengembalikan key dan pemiliknya (role ikut dimuat).
+func (s *APIKeyService) Authenticate(ctx context.Context, plain string) (*domain.APIKey, *domain.User, error) {
+	parts := strings.SplitN(plain, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	key, err := s.keyRepo.FindByPrefix(ctx, parts[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	if key == nil || subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashOpaqueToken(plain))) != 1 {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	now := time.Now()
+	if !key.Active(now) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	user, err := s.userRepo.GetUserByID(ctx, key.UserID)
+	if err != nil || user.Suspended() {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if err := s.keyRepo.TouchLastUsed(ctx, key.ID, now, now.Add(-apiKeyTouchInterval)); err != nil {
+		s.logger.Warn("gagal memperbarui last_used_at api key", zap.String("api_key_id", key.ID.String()), zap.Error(err))
+	}
+	return key, user, nil
+}
+
+// newAPIKeyParts membuat prefix (48-bit hex, untuk lookup) dan secret (256-bit).
+func newAPIKeyParts() (prefix, secret string, err error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	secret, _, err = newOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(buf), secret, nil
+}
+
+func apiKeyResponse(key *domain.APIKey) dto.APIKeyResponse {
+	res := dto.APIKeyResponse{
+		ID:        key.ID.String(),
+		Name:      key.Name,
+		Prefix:    apiKeyPrefix + "_" + key.Prefix,
+		Scopes:    key.ScopeList(),
+		CreatedAt: key.CreatedAt.Format(time.RFC3339),
+	}
+	if key.ExpiresAt != nil {
+		v := key.ExpiresAt.Format(time.RFC3339)
+		res.ExpiresAt = &v
+	}
+	if key.LastUsedAt != nil {
+		v := key.LastUsedAt.Format(time.RFC3339)
+		res.LastUsedAt = &v
+	}
+	return res
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/mail"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrEmailNotVerified dikembalikan saat aksi membutuhkan email yang sudah diverifikasi.
+	ErrEmailNotVerified = errors.New("email belum diverifikasi")
+	// ErrInvalidVerificationToken dikembalikan untuk token yang tidak ada, kedaluwarsa, atau sudah dipakai.
+	ErrInvalidVerificationToken = errors.New("token verifikasi tidak valid atau kedaluwarsa")
+)
+
+// EmailVerificationService mengelola token verifikasi email dan pengirimannya.
+type EmailVerificationService struct {
+	userRepo  repository.UserRepository
+	tokenRepo repository.UserTokenRepository
+	mailer    mail.Sender
+	validator *validator.Validate
+	cfg       *config.Config
+	logger    *zap.Logger
+}
+
+// NewEmailVerificationService membuat instance EmailVerificationService baru.
+func NewEmailVerificationService(
+	userRepo repository.UserRepository,
+	tokenRepo repository.UserTokenRepository,
+	mailer mail.Sender,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		mailer:    mailer,
+		validator: validator.New(),
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+// SendVerification membuat token baru (token lama dibatalkan) dan mengirim link verifikasi ke user.
+func (s *EmailVerificationService) SendVerification(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user tidak ditemukan")
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	return s.send(ctx, user)
+}
+
+// Resend mengirim ulang link verifikasi berdasarkan email.
+// Selalu mengembalikan nil untuk email yang tidak terdaftar, sudah terverifikasi,
+// atau melewati batas kirim agar respons tidak membocorkan keberadaan akun.
+func (s *EmailVerificationService) Resend(ctx context.Context, req dto.ResendVerificationRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetUserByEmail(ctx, req.Email)
+	if err != nil || user.EmailVerifiedAt != nil {
+		return nil
+	}
+	count, err := s.tokenRepo.CountSince(ctx, user.ID, domain.TokenPurposeEmailVerification, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if count >= int64(s.cfg.EmailVerificationPerHour) {
+		s.logger.Info("kirim ulang verifikasi email dibatasi", zap.String("user_id", user.ID.String()))
+		return nil
+	}
+	return s.send(ctx, user)
+}
+
+// Verify memvalidasi token lalu menandai email user sebagai terverifikasi.
+func (s *EmailVerificationService) Verify(ctx context.Context, req dto.VerifyEmailRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+	record, err := s.tokenRepo.FindByHash(ctx, domain.TokenPurposeEmailVerification, hashOpaqueToken(req.Token))
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+	if record.UsedAt != nil || time.Now().After(record.ExpiresAt) {
+		return ErrInvalidVerificationToken
+	}
+	// Tandai terpakai secara atomik agar token tidak bisa dipakai dua kali
+	used, err := s.tokenRepo.MarkUsed(ctx, record.ID)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidVerificationToken
+	}
+	return s.userRepo.MarkEmailVerified(ctx, record.UserID, time.Now())
+}
+
+// send membatalkan token lama, menyimpan hash token baru, dan mengirim email.
+func (s *EmailVerificationService) send(ctx context.Context, user *domain.User) error {
+	plain, hash, err := newOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("gagal membuat token verifikasi: %w", err)
+	}
+	if err := s.tokenRepo.InvalidateAll(ctx, user.ID, domain.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+	record := &domain.UserToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Purpose:   domain.TokenPurposeEmailVerification,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.cfg.EmailVerificationTTL),
+	}
+	if err := s.tokenRepo.Create(ctx, record); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.cfg.FrontendURL, url.QueryEscape(plain))
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email Anda",
+		Body: fmt.Sprintf("Halo %s,\n\nKlik link berikut untuk memverifikasi email Anda:\n%s\n\nLink berlaku selama %s.",
+			user.Name, link, s.cfg.EmailVerificationTTL),
+	})
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ErrCannotImpersonate dikembalikan saat target impersonasi adalah admin atau akun yang ditangguhkan.
+var ErrCannotImpersonate = errors.New("user ini tidak dapat di-impersonate")
+
+// ImpersonationService menerbitkan AT atas nama user lain untuk keperluan support.
+// Token membawa klaim act (admin pelaku); mulai, berhenti, dan setiap mutasi selama impersonasi dicatat di audit log.
+type ImpersonationService struct {
+	userRepo   repository.UserRepository
+	jwtService *JWTService
+	revocation *TokenRevocationService
+	audit      *AuditService
+	enforcer   *casbin.SyncedEnforcer
+	validator  *validator.Validate
+	cfg        *config.Config
+	logger     *zap.Logger
+}
+
+// NewImpersonationService membuat instance ImpersonationService baru.
+func NewImpersonationService(
+	userRepo repository.UserRepository,
+	jwtService *JWTService,
+	revocation *TokenRevocationService,
+	audit *AuditService,
+	enforcer *casbin.SyncedEnforcer,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *ImpersonationService {
+	return &ImpersonationService{
+		userRepo:   userRepo,
+		jwtService: jwtService,
+		revocation: revocation,
+		audit:      audit,
+		enforcer:   enforcer,
+		validator:  validator.New(),
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+// Start menerbitkan AT impersonasi untuk targetID. Admin, super admin (termasuk role yang mewarisi salah satunya
+// lewat hierarki casbin), dan akun yang ditangguhkan tidak bisa di-impersonate.
+// Audit gagal disimpan berarti impersonasi dibatalkan: tidak boleh ada impersonasi tanpa jejak.
+func (s *ImpersonationService) Start(ctx context.Context, actorID, targetID uuid.UUID, req dto.StartImpersonationRequest, ipAddress, userAgent string) (*dto.ImpersonationResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	if actorID == targetID {
+		return nil, ErrCannotModifySelf
+	}
+	target, err := s.userRepo.GetUserByID(ctx, targetID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if target.Suspended() {
+		return nil, ErrCannotImpersonate
+	}
+	privileged, err := s.isAdmin(target.Role.Name)
+	if err != nil {
+		return nil, err
+	}
+	if privileged {
+		return nil, ErrCannotImpersonate
+	}
+
+	claims, token, err := s.jwtService.GenerateImpersonationToken(target.ID, target.RoleID, actorID, s.cfg.ImpersonationTTL)
+	if err != nil {
+		return nil, err
+	}
+	metadata, _ := json.Marshal(map[string]string{
+		"reason":     req.Reason,
+		"token_id":   claims.ID,
+		"expires_at": claims.ExpiresAt.Time.Format(time.RFC3339),
+	})
+	if err := s.audit.Record(ctx, &domain.AuditLog{
+		ActorID:   actorID,
+		UserID:    &target.ID,
+		Action:    domain.AuditImpersonationStart,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Metadata:  string(metadata),
+	}); err != nil {
+		return nil, err
+	}
+	s.logger.Info("impersonasi dimulai",
+		zap.String("actor_id", actorID.String()),
+		zap.String("user_id", target.ID.String()),
+		zap.String("token_id", claims.ID),
+	)
+
+	return &dto.ImpersonationResponse{
+		AccessToken:    token,
+		ExpiresAt:      claims.ExpiresAt.Time.Format(time.RFC3339),
+		User:           userResponseDTO(target),
+		ImpersonatorID: actorID.String(),
+	}, nil
+}
+
+// isAdmin mengembalikan true jika role adalah admin/super admin atau mewarisinya secara implisit.
+func (s *ImpersonationService) isAdmin(roleName string) (bool, error) {
+	if roleName == domain.RoleAdmin || roleName == domain.RoleSuperAdmin {
+		return true, nil
+	}
+	roles, err := s.enforcer.GetImplicitRolesForUser(roleName)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(roles, domain.RoleAdmin) || slices.Contains(roles, domain.RoleSuperAdmin), nil
+}
+
+// Stop mencabut AT impersonasi yang sedang dipakai lalu mencatatnya di audit log.
+func (s *ImpersonationService) Stop(ctx context.Context, actorID, userID uuid.UUID, tokenID string, expiresAt time.Time, ipAddress, userAgent string) error {
+	if err := s.revocation.RevokeTokenID(ctx, tokenID, userID, expiresAt); err != nil {
+		return err
+	}
+	metadata, _ := json.Marshal(map[string]string{"token_id": tokenID})
+	_ = s.audit.Record(ctx, &domain.AuditLog{
+		ActorID:   actorID,
+		UserID:    &userID,
+		Action:    domain.AuditImpersonationStop,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Metadata:  string(metadata),
+	})
+	s.logger.Info("impersonasi dihentikan",
+		zap.String("actor_id", actorID.String()),
+		zap.String("user_id", userID.String()),
+		zap.String("token_id", tokenID),
+	)
+	return nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+// memAuditLogs adalah AuditLogRepository in-memory yang hanya menyimpan entri.
+type memAuditLogs struct {
+	repository.AuditLogRepository
+	entries []domain.AuditLog
+}
+
+func (m *memAuditLogs) Create(_ context.Context, entry *domain.AuditLog) error {
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func TestImpersonationRejectsRolesInheritingAdmin(t *testing.T) {
+	enforcer, err := casbin.NewSyncedEnforcer("../../config/rbac_model.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// support_lead mewarisi admin, ops_manager mewarisi support_lead (dua tingkat)
+	for _, rule := range [][]string{
+		{"support_lead", domain.RoleAdmin},
+		{"ops_manager", "support_lead"},
+		{"root_operator", domain.RoleSuperAdmin},
+		{"senior_seller", "seller"},
+	} {
+		if _, err := enforcer.AddGroupingPolicy(rule[0], rule[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{
+		JWTKeyDir:        t.TempDir(),
+		JWTSigningAlg:    AlgEdDSA,
+		JWTKeyRotation:   time.Hour,
+		AccessTTL:        15 * time.Minute,
+		ImpersonationTTL: 15 * time.Minute,
+	}
+	keys, err := NewKeyManager(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := make(map[uuid.UUID]*domain.User)
+	audit := &memAuditLogs{}
+	svc := NewImpersonationService(staticUsers{users: users}, NewJWTService(cfg, keys), nil,
+		NewAuditService(audit, zap.NewNop()), enforcer, cfg, zap.NewNop())
+
+	tests := []struct {
+		role    string
+		wantErr error
+	}{
+		{role: domain.RoleAdmin, wantErr: ErrCannotImpersonate},
+		{role: domain.RoleSuperAdmin, wantErr: ErrCannotImpersonate},
+		{role: "support_lead", wantErr: ErrCannotImpersonate},
+		{role: "ops_manager", wantErr: ErrCannotImpersonate},
+		{role: "root_operator", wantErr: ErrCannotImpersonate},
+		{role: "senior_seller"},
+		{role: "buyer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			target := &domain.User{ID: uuid.New(), RoleID: uuid.New(), Role: domain.Role{Name: tt.role}}
+			users[target.ID] = target
+			before := len(audit.entries)
+
+			res, err := svc.Start(context.Background(), uuid.New(), target.ID, dto.StartImpersonationRequest{Reason: "TICKET-1"}, "", "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Start: err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if res != nil || len(audit.entries) != before {
+					t.Fatal("impersonasi yang ditolak tidak boleh menerbitkan token atau mencatat audit")
+				}
+				return
+			}
+			if res.AccessToken == "" || len(audit.entries) != before+1 {
+				t.Fatal("impersonasi harus menerbitkan token dan mencatat audit")
+			}
+		})
+	}
+}
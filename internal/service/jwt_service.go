@@ -6,6 +6,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/itujun/project-ecommerce-go-next/internal/config"
@@ -13,30 +14,77 @@ import (
 
 // CustomClaims menyimpan data user minimal + jti
 type CustomClaims struct {
-	UserID uuid.UUID `json:"uid"`
-	RoleID uuid.UUID `json:"rid"`
+	UserID    uuid.UUID   `json:"uid"`
+	RoleID    uuid.UUID   `json:"rid"`
+	SessionID uuid.UUID   `json:"sid"`           // family RT (sesi perangkat) tempat AT ini diterbitkan
+	Actor     *ActorClaim `json:"act,omitempty"` // diisi hanya pada token impersonasi
+	// IssuedAtMicro adalah waktu terbit AT dalam mikrodetik Unix. iat standar hanya berpresisi detik,
+	// sedangkan cutoff TokenRevocationService.RevokeUser harus membedakan AT yang diterbitkan sesaat
+	// sebelum dan sesudah pencabutan di detik yang sama.
+	IssuedAtMicro int64 `json:"iat_us,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// ActorClaim adalah klaim "act" (RFC 8693): pihak yang bertindak atas nama subject token.
+type ActorClaim struct {
+	UserID uuid.UUID `json:"sub"`
+}
+
+// Tujuan token MFA sementara ("mfa pending") yang diterbitkan di antara dua langkah login.
+const (
+	MFAPurposeVerify = "verify" // user sudah punya 2FA, tinggal memasukkan kode
+	MFAPurposeEnroll = "enroll" // role user mewajibkan 2FA tetapi belum enrollment
+
+	mfaAudience = "ecommerce-mfa"
+)
+
+// MFAClaims adalah klaim token MFA sementara. Audience berbeda dari AT sehingga tidak bisa dipakai mengakses API.
+type MFAClaims struct {
+	UserID      uuid.UUID `json:"uid"`
+	Purpose     string    `json:"purpose"`
+	FirstFactor string    `json:"first_factor"` // FirstFactor* yang sudah dilalui; menentukan faktor kedua yang sah
+	jwt.RegisteredClaims
+}
+
+// oauthStateAudience membedakan token state OAuth dari AT dan token MFA.
+const oauthStateAudience = "ecommerce-oauth-state"
+
+// OAuthStateClaims disimpan di cookie oauth_state selama redirect ke provider login sosial.
+// Ditandatangani agar state, PKCE verifier, dan nonce tidak bisa diubah klien.
+type OAuthStateClaims struct {
+	Provider   string    `json:"provider"`
+	State      string    `json:"state"`
+	Verifier   string    `json:"verifier"` // PKCE code_verifier
+	Nonce      string    `json:"nonce"`
+	LinkUserID uuid.UUID `json:"link_uid,omitempty"` // terisi jika user yang sudah login menautkan akun sosial
 	jwt.RegisteredClaims
 }
 
 // JWTService menyediakan util untuk generate/verify token
+// AT ditandatangani secara asimetris (RS256/EdDSA) dengan kunci dari KeyManager sehingga
+// layanan lain cukup memakai JWKS untuk verifikasi. RT tetap HMAC karena hanya diverifikasi server ini.
 type JWTService struct {
-	cfg *config.Config
+	cfg  *config.Config
+	keys *KeyManager
 }
 
-func NewJWTService(cfg *config.Config) *JWTService {
-	return &JWTService{cfg: cfg}
+func NewJWTService(cfg *config.Config, keys *KeyManager) *JWTService {
+	return &JWTService{cfg: cfg, keys: keys}
 }
 
 // GenerateAccessToken membuat AT (durasi pendek) untuk akses API
 // semula: func (s *JWTService) GenerateAccessToken(u *domain.User) (string, time.Time, error)
-// ganti jadi menerima primitive:
-func (s *JWTService) GenerateAccessToken(userID uuid.UUID, roleID uuid.UUID) (string, time.Time, error) {
+// ganti jadi menerima primitive; sessionID = family RT agar AT bisa dikaitkan ke sesi perangkat.
+// Klaim dikembalikan agar jti bisa dicatat pada sesi (dicabut saat sesi dicabut).
+func (s *JWTService) GenerateAccessToken(userID uuid.UUID, roleID uuid.UUID, sessionID uuid.UUID) (*CustomClaims, string, error) {
 	now := time.Now()
 	exp := now.Add(s.cfg.AccessTTL)
 
-	claims := CustomClaims{
-		 UserID: userID,
-        RoleID: roleID,
+	claims := &CustomClaims{
+		UserID:        userID,
+		RoleID:        roleID,
+		SessionID:     sessionID,
+		IssuedAtMicro: now.UnixMicro(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    "ecommerce-go",
 			Subject:   userID.String(),
@@ -48,9 +96,32 @@ func (s *JWTService) GenerateAccessToken(userID uuid.UUID, roleID uuid.UUID) (st
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	str, err := token.SignedString([]byte(s.cfg.JWTAccessSecret))
-	return str, exp, err
+	str, err := s.signWithActiveKey(claims)
+	return claims, str, err
+}
+
+// GenerateImpersonationToken membuat AT atas nama targetID dengan klaim act berisi actorID.
+// Token tidak punya RT; sid baru dibuat agar tidak terkait sesi perangkat mana pun.
+func (s *JWTService) GenerateImpersonationToken(targetID, roleID, actorID uuid.UUID, ttl time.Duration) (*CustomClaims, string, error) {
+	now := time.Now()
+	claims := &CustomClaims{
+		UserID:        targetID,
+		RoleID:        roleID,
+		SessionID:     uuid.New(),
+		Actor:         &ActorClaim{UserID: actorID},
+		IssuedAtMicro: now.UnixMicro(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "ecommerce-go",
+			Subject:   targetID.String(),
+			Audience:  []string{"ecommerce-client"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+	}
+	str, err := s.signWithActiveKey(claims)
+	return claims, str, err
 }
 
 // GenerateRefreshToken membuat RT (durasi lebih panjang) untuk refresh AT
@@ -76,10 +147,15 @@ func (s *JWTService) GenerateRefreshToken(userID uuid.UUID) (string, time.Time,
 }
 
 // VerifyAccessToken memverifikasi & mengembalikan claims
+// Algoritma, issuer, dan audience ikut diperiksa agar token lain (mis. RT) tidak bisa dipakai sebagai AT.
 func (s *JWTService) VerifyAccessToken(tokenStr string) (*CustomClaims, error) {
-	tok, err := jwt.ParseWithClaims(tokenStr, &CustomClaims{}, func(t *jwt.Token) (any, error) {
-		return []byte(s.cfg.JWTAccessSecret), nil
-	})
+	tok, err := jwt.ParseWithClaims(tokenStr, &CustomClaims{}, s.verificationKey,
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithIssuer("ecommerce-go"),
+		jwt.WithAudience("ecommerce-client"),
+		jwt.WithExpirationRequired(), // exp dipakai sebagai masa berlaku entri denylist
+		jwt.WithIssuedAt(),
+	)
 	if err != nil || !tok.Valid {
 		return nil, errors.New("invalid access token")
 	}
@@ -105,6 +181,206 @@ func (s *JWTService) VerifyRefreshToken(tokenStr string) (*jwt.RegisteredClaims,
 	return claims, nil
 }
 
+// GenerateMFAToken membuat token "mfa pending" berumur pendek setelah faktor pertama terverifikasi.
+func (s *JWTService) GenerateMFAToken(userID uuid.UUID, purpose, firstFactor string) (string, time.Time, error) {
+	now := time.Now()
+	exp := now.Add(s.cfg.MFATokenTTL)
+	claims := MFAClaims{
+		UserID:      userID,
+		Purpose:     purpose,
+		FirstFactor: firstFactor,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "ecommerce-go",
+			Subject:   userID.String(),
+			Audience:  []string{mfaAudience},
+			ExpiresAt: jwt.NewNumericDate(exp),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+	}
+	str, err := s.signWithActiveKey(claims)
+	return str, exp, err
+}
+
+// VerifyMFAToken memverifikasi token "mfa pending" dan memastikan tujuannya sesuai.
+func (s *JWTService) VerifyMFAToken(tokenStr, purpose string) (*MFAClaims, error) {
+	tok, err := jwt.ParseWithClaims(tokenStr, &MFAClaims{}, s.verificationKey,
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithIssuer("ecommerce-go"),
+		jwt.WithAudience(mfaAudience),
+	)
+	if err != nil || !tok.Valid {
+		return nil, errors.New("invalid mfa token")
+	}
+	claims, ok := tok.Claims.(*MFAClaims)
+	if !ok || claims.Purpose != purpose {
+		return nil, errors.New("invalid mfa token")
+	}
+	return claims, nil
+}
+
+// magicLinkAudience membedakan token magic link dari AT dan token sementara lainnya.
+const magicLinkAudience = "ecommerce-magic-link"
+
+// MagicLinkClaims adalah klaim token login tanpa password yang dikirim lewat email.
+// jti sama dengan ID baris user_tokens sehingga token hanya bisa dipakai sekali.
+type MagicLinkClaims struct {
+	UserID uuid.UUID `json:"uid"`
+	jwt.RegisteredClaims
+}
+
+// GenerateMagicLinkToken menandatangani token magic link berumur ttl.
+func (s *JWTService) GenerateMagicLinkToken(userID, tokenID uuid.UUID, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	exp := now.Add(ttl)
+	claims := MagicLinkClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "ecommerce-go",
+			Subject:   userID.String(),
+			Audience:  []string{magicLinkAudience},
+			ExpiresAt: jwt.NewNumericDate(exp),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ID:        tokenID.String(),
+		},
+	}
+	str, err := s.signWithActiveKey(claims)
+	return str, exp, err
+}
+
+// VerifyMagicLinkToken memverifikasi tanda tangan, masa berlaku, dan audience token magic link.
+func (s *JWTService) VerifyMagicLinkToken(tokenStr string) (*MagicLinkClaims, error) {
+	tok, err := jwt.ParseWithClaims(tokenStr, &MagicLinkClaims{}, s.verificationKey,
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithIssuer("ecommerce-go"),
+		jwt.WithAudience(magicLinkAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || !tok.Valid {
+		return nil, errors.New("invalid magic link token")
+	}
+	claims, ok := tok.Claims.(*MagicLinkClaims)
+	if !ok {
+		return nil, errors.New("invalid magic link token")
+	}
+	return claims, nil
+}
+
+// GenerateOAuthStateToken menandatangani state login sosial dengan masa berlaku ttl.
+func (s *JWTService) GenerateOAuthStateToken(claims OAuthStateClaims, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	exp := now.Add(ttl)
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    "ecommerce-go",
+		Audience:  []string{oauthStateAudience},
+		ExpiresAt: jwt.NewNumericDate(exp),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ID:        uuid.NewString(),
+	}
+	str, err := s.signWithActiveKey(claims)
+	return str, exp, err
+}
+
+// VerifyOAuthStateToken memverifikasi token state login sosial.
+func (s *JWTService) VerifyOAuthStateToken(tokenStr string) (*OAuthStateClaims, error) {
+	tok, err := jwt.ParseWithClaims(tokenStr, &OAuthStateClaims{}, s.verificationKey,
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithIssuer("ecommerce-go"),
+		jwt.WithAudience(oauthStateAudience),
+	)
+	if err != nil || !tok.Valid {
+		return nil, errors.New("invalid oauth state")
+	}
+	claims, ok := tok.Claims.(*OAuthStateClaims)
+	if !ok {
+		return nil, errors.New("invalid oauth state")
+	}
+	return claims, nil
+}
+
+// webauthnSessionAudience membedakan token sesi ceremony WebAuthn dari AT dan token sementara lainnya.
+const webauthnSessionAudience = "ecommerce-webauthn"
+
+// WebAuthnSessionClaims menyimpan challenge ceremony WebAuthn di cookie antara langkah begin dan finish.
+// Ditandatangani agar challenge dan user yang diharapkan tidak bisa diubah klien.
+type WebAuthnSessionClaims struct {
+	Ceremony string               `json:"ceremony"` // "registration" atau "login"
+	Session  webauthn.SessionData `json:"session"`
+	jwt.RegisteredClaims
+}
+
+// GenerateWebAuthnSessionToken menandatangani data sesi ceremony WebAuthn dengan masa berlaku ttl.
+func (s *JWTService) GenerateWebAuthnSessionToken(ceremony string, session webauthn.SessionData, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	exp := now.Add(ttl)
+	claims := WebAuthnSessionClaims{
+		Ceremony: ceremony,
+		Session:  session,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "ecommerce-go",
+			Audience:  []string{webauthnSessionAudience},
+			ExpiresAt: jwt.NewNumericDate(exp),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+	}
+	str, err := s.signWithActiveKey(claims)
+	return str, exp, err
+}
+
+// VerifyWebAuthnSessionToken memverifikasi token sesi ceremony dan memastikan jenis ceremony sesuai.
+func (s *JWTService) VerifyWebAuthnSessionToken(tokenStr, ceremony string) (*webauthn.SessionData, error) {
+	tok, err := jwt.ParseWithClaims(tokenStr, &WebAuthnSessionClaims{}, s.verificationKey,
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithIssuer("ecommerce-go"),
+		jwt.WithAudience(webauthnSessionAudience),
+	)
+	if err != nil || !tok.Valid {
+		return nil, errors.New("invalid webauthn session")
+	}
+	claims, ok := tok.Claims.(*WebAuthnSessionClaims)
+	if !ok || claims.Ceremony != ceremony {
+		return nil, errors.New("invalid webauthn session")
+	}
+	return &claims.Session, nil
+}
+
+// signWithActiveKey menandatangani klaim dengan kunci aktif dan menaruh kid di header.
+func (s *JWTService) signWithActiveKey(claims jwt.Claims) (string, error) {
+	key, err := s.keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	var method jwt.SigningMethod = jwt.SigningMethodRS256
+	if key.Alg == AlgEdDSA {
+		method = jwt.SigningMethodEdDSA
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// verificationKey adalah jwt.Keyfunc yang memilih kunci publik berdasarkan header kid.
+func (s *JWTService) verificationKey(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("missing kid")
+	}
+	key, ok := s.keys.VerificationKey(kid)
+	if !ok {
+		return nil, errors.New("unknown kid")
+	}
+	// Cegah algorithm confusion: alg di header harus sama dengan tipe kunci
+	if t.Method.Alg() != key.Alg {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.Public(), nil
+}
+
 // HashRefreshToken meng‑hash RT untuk disimpan di DB (jangan simpan plaintext)
 func (s *JWTService) HashRefreshToken(rt string) string {
 	sum := sha256.Sum256([]byte(rt))
@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"go.uber.org/zap"
+)
+
+// Algoritma tanda tangan yang didukung untuk access token.
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// reloadCooldown membatasi reload direktori saat menerima kid yang belum dikenal,
+// agar kid acak dari penyerang tidak memicu baca disk terus-menerus.
+const reloadCooldown = 10 * time.Second
+
+// JWKSCacheMaxAge adalah max-age Cache-Control dokumen JWKS. Kunci baru baru dipakai menandatangani
+// setelah dipublikasikan lebih lama dari ini, sehingga verifier dengan JWKS di cache sudah mengenalnya.
+const JWKSCacheMaxAge = 5 * time.Minute
+
+// keyClockSkew adalah toleransi selisih jam antar server dan verifier.
+const keyClockSkew = time.Minute
+
+// SigningKey adalah satu pasangan kunci dari direktori kunci.
+type SigningKey struct {
+	ID        string        // kid, diambil dari nama file tanpa ekstensi .pem
+	Alg       string        // RS256 atau EdDSA, ditentukan dari tipe kunci
+	Private   crypto.Signer // kunci privat (PKCS#8)
+	CreatedAt time.Time     // waktu modifikasi file; kunci terbaru dipakai untuk menandatangani
+	path      string
+}
+
+// Public mengembalikan kunci publik pasangan kunci ini.
+func (k *SigningKey) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// JWK adalah representasi kunci publik sesuai RFC 7517 (RSA) dan RFC 8037 (OKP/Ed25519).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet adalah isi dokumen /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeyManager memuat kunci tanda tangan dari direktori, memilih kunci aktif,
+// dan merotasi kunci secara terjadwal. Kunci berikutnya dibuat lebih dulu dan baru dipakai
+// menandatangani setelah masa publikasi lewat; semua kunci yang masih dalam masa retensi
+// tetap dipakai untuk verifikasi dan dipublikasikan lewat JWKS.
+type KeyManager struct {
+	mu         sync.RWMutex
+	keys       []*SigningKey // urut CreatedAt naik; kunci aktif = kunci terbaru yang sudah dipublikasikan
+	lastReload time.Time
+
+	dir         string
+	alg         string
+	rotateEvery time.Duration
+	// publishDelay adalah umur minimal kunci sebelum dipakai menandatangani: max-age JWKS ditambah
+	// interval reload replika lain, agar semua verifier sudah melihat kuncinya.
+	publishDelay time.Duration
+	retain       time.Duration
+	logger       *zap.Logger
+}
+
+// NewKeyManager membuat KeyManager dan memuat kunci dari cfg.JWTKeyDir.
+// Jika direktori kosong, satu kunci baru dibuat agar server bisa langsung berjalan (dev lokal).
+func NewKeyManager(cfg *config.Config, logger *zap.Logger) (*KeyManager, error) {
+	if cfg.JWTSigningAlg != AlgRS256 && cfg.JWTSigningAlg != AlgEdDSA {
+		return nil, fmt.Errorf("algoritma JWT tidak didukung: %s", cfg.JWTSigningAlg)
+	}
+	m := &KeyManager{
+		dir:          cfg.JWTKeyDir,
+		alg:          cfg.JWTSigningAlg,
+		rotateEvery:  cfg.JWTKeyRotation,
+		publishDelay: JWKSCacheMaxAge + cfg.JWTKeyCheckInterval + keyClockSkew,
+		// kunci lama harus tetap bisa memverifikasi token yang ditandatangani tepat sebelum rotasi
+		retain: maxSignedTokenTTL(cfg) + keyClockSkew,
+		logger: logger,
+	}
+	if m.rotateEvery <= m.publishDelay {
+		return nil, fmt.Errorf("JWT_KEY_ROTATION harus lebih dari %s", m.publishDelay)
+	}
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("gagal membuat direktori kunci: %w", err)
+	}
+	if err := m.Load(); err != nil {
+		return nil, err
+	}
+	if len(m.keys) == 0 {
+		if _, err := m.generate(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Load membaca ulang seluruh file *.pem di direktori kunci.
+func (m *KeyManager) Load() error {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return fmt.Errorf("gagal membaca direktori kunci: %w", err)
+	}
+	var keys []*SigningKey
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".pem" {
+			continue
+		}
+		key, err := readKeyFile(filepath.Join(m.dir, e.Name()))
+		if err != nil {
+			// satu file rusak tidak boleh menjatuhkan seluruh verifikasi
+			m.logger.Warn("kunci JWT dilewati", zap.String("file", e.Name()), zap.Error(err))
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	m.mu.Lock()
+	m.keys = keys
+	m.lastReload = time.Now()
+	m.mu.Unlock()
+	return nil
+}
+
+// maxSignedTokenTTL adalah masa berlaku terpanjang token yang ditandatangani kunci ini
+// (AT, AT impersonasi, magic link, mfa_token, state OAuth, sesi WebAuthn).
+func maxSignedTokenTTL(cfg *config.Config) time.Duration {
+	return max(cfg.AccessTTL, cfg.ImpersonationTTL, cfg.MagicLinkTTL, cfg.MFATokenTTL, OAuthStateTTL, WebAuthnSessionTTL)
+}
+
+// SigningKey mengembalikan kunci aktif untuk menandatangani token, yaitu kunci terbaru yang sudah
+// dipublikasikan lebih lama dari publishDelay. Saat belum ada (direktori baru), kunci tertua dipakai.
+func (m *KeyManager) SigningKey() (*SigningKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.keys) == 0 {
+		return nil, errors.New("tidak ada kunci JWT aktif")
+	}
+	return m.keys[m.activeIndex()], nil
+}
+
+// activeIndex mengembalikan indeks kunci aktif; pemanggil memegang m.mu.
+func (m *KeyManager) activeIndex() int {
+	for i := len(m.keys) - 1; i >= 0; i-- {
+		if time.Since(m.keys[i].CreatedAt) >= m.publishDelay {
+			return i
+		}
+	}
+	return 0
+}
+
+// VerificationKey mencari kunci berdasarkan kid. Jika kid belum dikenal (mis. replika lain
+// baru saja merotasi kunci), direktori dibaca ulang paling sering sekali per reloadCooldown.
+func (m *KeyManager) VerificationKey(kid string) (*SigningKey, bool) {
+	if key, ok := m.find(kid); ok {
+		return key, true
+	}
+	m.mu.RLock()
+	recentlyReloaded := time.Since(m.lastReload) < reloadCooldown
+	m.mu.RUnlock()
+	if recentlyReloaded {
+		return nil, false
+	}
+	if err := m.Load(); err != nil {
+		m.logger.Warn("gagal memuat ulang kunci JWT", zap.Error(err))
+		return nil, false
+	}
+	return m.find(kid)
+}
+
+func (m *KeyManager) find(kid string) (*SigningKey, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, k := range m.keys {
+		if k.ID == kid {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+// JWKS mengembalikan kunci publik seluruh kunci yang masih berlaku.
+func (m *KeyManager) JWKS() JWKSet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	set := JWKSet{Keys: make([]JWK, 0, len(m.keys))}
+	for _, k := range m.keys {
+		jwk := JWK{Use: "sig", Alg: k.Alg, Kid: k.ID}
+		switch pub := k.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// Rotate membuat kunci berikutnya publishDelay sebelum kunci terbaru berumur JWT_KEY_ROTATION,
+// sehingga kunci itu sudah ada di JWKS (dan cache verifier) saat mulai dipakai menandatangani,
+// lalu menghapus kunci lama yang penggantinya sudah melewati masa retensi.
+// Aman dijalankan di beberapa replika yang berbagi direktori: paling buruk dua kunci dibuat
+// hampir bersamaan, dan keduanya tetap valid untuk verifikasi.
+func (m *KeyManager) Rotate() error {
+	// Baca ulang dulu agar kunci dari replika lain ikut diperhitungkan
+	if err := m.Load(); err != nil {
+		return err
+	}
+	m.mu.RLock()
+	var newest *SigningKey
+	if len(m.keys) > 0 {
+		newest = m.keys[len(m.keys)-1]
+	}
+	m.mu.RUnlock()
+	if newest == nil || time.Since(newest.CreatedAt) >= m.rotateEvery-m.publishDelay {
+		key, err := m.generate()
+		if err != nil {
+			return err
+		}
+		m.logger.Info("kunci JWT berikutnya dipublikasikan", zap.String("kid", key.ID), zap.String("alg", key.Alg),
+			zap.Time("active_from", key.CreatedAt.Add(m.publishDelay)))
+	}
+	return m.prune()
+}
+
+// Run menjalankan Rotate secara berkala sampai ctx selesai.
+func (m *KeyManager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Rotate(); err != nil {
+				m.logger.Error("gagal merotasi kunci JWT", zap.Error(err))
+			}
+		}
+	}
+}
+
+// prune menghapus kunci yang penggantinya sudah menandatangani lebih lama dari masa retensi.
+// Kunci aktif dan kunci yang belum aktif tidak pernah dihapus.
+func (m *KeyManager) prune() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	active := m.activeIndex()
+	kept := make([]*SigningKey, 0, len(m.keys))
+	for i, k := range m.keys {
+		retired := i < active && time.Since(m.keys[i+1].CreatedAt) > m.publishDelay+m.retain
+		if !retired {
+			kept = append(kept, k)
+			continue
+		}
+		if err := os.Remove(k.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("gagal menghapus kunci lama %s: %w", k.ID, err)
+		}
+		m.logger.Info("kunci JWT lama dihapus", zap.String("kid", k.ID))
+	}
+	m.keys = kept
+	return nil
+}
+
+// generate membuat kunci baru sesuai JWT_SIGNING_ALG dan menyimpannya ke direktori.
+func (m *KeyManager) generate() (*SigningKey, error) {
+	var priv crypto.Signer
+	var err error
+	switch m.alg {
+	case AlgEdDSA:
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		priv, err = rsa.GenerateKey(rand.Reader, 2048)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat kunci JWT: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("gagal encode kunci JWT: %w", err)
+	}
+
+	// kid berbasis waktu + suffix acak agar urut secara leksikal dan tidak bentrok antar replika
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	kid := time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+	path := filepath.Join(m.dir, kid+".pem")
+
+	// Tulis ke file sementara lalu rename agar replika lain tidak membaca file setengah jadi
+	tmp := path + ".tmp"
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(tmp, pemBytes, 0o600); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan kunci JWT: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan kunci JWT: %w", err)
+	}
+	if err := m.Load(); err != nil {
+		return nil, err
+	}
+	key, ok := m.find(kid)
+	if !ok {
+		return nil, fmt.Errorf("kunci JWT %s tidak ditemukan setelah dibuat", kid)
+	}
+	return key, nil
+}
+
+// readKeyFile membaca kunci privat PKCS#8 (RSA atau Ed25519) dari file PEM.
+func readKeyFile(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("file bukan PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key := &SigningKey{
+		ID:        strings.TrimSuffix(filepath.Base(path), ".pem"),
+		CreatedAt: info.ModTime(),
+		path:      path,
+	}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Alg, key.Private = AlgRS256, k
+	case ed25519.PrivateKey:
+		key.Alg, key.Private = AlgEdDSA, k
+	default:
+		return nil, fmt.Errorf("tipe kunci tidak didukung: %T", parsed)
+	}
+	return key, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"go.uber.org/zap"
+)
+
+// backdate memundurkan waktu pembuatan kunci (mtime file) lalu memuat ulang direktori.
+func backdate(t *testing.T, m *KeyManager, key *SigningKey, age time.Duration) {
+	t.Helper()
+	at := time.Now().Add(-age)
+	if err := os.Chtimes(key.path, at, at); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func jwksKids(m *KeyManager) []string {
+	var kids []string
+	for _, k := range m.JWKS().Keys {
+		kids = append(kids, k.Kid)
+	}
+	return kids
+}
+
+func TestKeyManagerPublishesNextKeyBeforeSigning(t *testing.T) {
+	cfg := &config.Config{
+		JWTKeyDir:      t.TempDir(),
+		JWTSigningAlg:  AlgEdDSA,
+		JWTKeyRotation: time.Hour,
+		AccessTTL:      15 * time.Minute,
+	}
+	m, err := NewKeyManager(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := m.SigningKey()
+
+	// Kunci aktif hampir habis umurnya: kunci berikutnya dibuat dan dipublikasikan, tapi belum dipakai
+	backdate(t, m, first, cfg.JWTKeyRotation-m.publishDelay)
+	if err := m.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if kids := jwksKids(m); len(kids) != 2 {
+		t.Fatalf("JWKS harus memuat kunci aktif dan kunci berikutnya, got %v", kids)
+	}
+	if active, _ := m.SigningKey(); active.ID != first.ID {
+		t.Fatalf("kunci baru dipakai menandatangani sebelum dipublikasikan %s", m.publishDelay)
+	}
+	next := m.keys[1]
+
+	// Setelah lebih lama dari max-age JWKS, kunci baru aktif dan kunci lama tetap bisa memverifikasi
+	backdate(t, m, next, m.publishDelay)
+	if active, _ := m.SigningKey(); active.ID != next.ID {
+		t.Fatalf("kunci aktif = %s, want %s", active.ID, next.ID)
+	}
+	if err := m.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.VerificationKey(first.ID); !ok {
+		t.Fatal("kunci lama dihapus sebelum token terakhirnya kedaluwarsa")
+	}
+
+	// Kunci lama baru dihapus setelah token terpanjang yang ditandatanganinya kedaluwarsa
+	backdate(t, m, next, m.publishDelay+m.retain+time.Minute)
+	if err := m.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if kids := jwksKids(m); len(kids) != 1 || kids[0] != next.ID {
+		t.Fatalf("JWKS = %v, want hanya %s", kids, next.ID)
+	}
+}
+
+func TestKeyManagerRetainsLongestTokenTTL(t *testing.T) {
+	cfg := &config.Config{
+		JWTKeyDir:        t.TempDir(),
+		JWTSigningAlg:    AlgEdDSA,
+		JWTKeyRotation:   24 * time.Hour,
+		AccessTTL:        15 * time.Minute,
+		ImpersonationTTL: time.Hour,
+		MagicLinkTTL:     30 * time.Minute,
+	}
+	m, err := NewKeyManager(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.retain < cfg.ImpersonationTTL {
+		t.Fatalf("retensi %s lebih pendek dari IMPERSONATION_TTL %s", m.retain, cfg.ImpersonationTTL)
+	}
+}
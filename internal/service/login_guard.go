@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidCredentials adalah pesan gagal login yang seragam;
+// email tidak terdaftar dan password salah tidak dibedakan agar akun tidak bisa dienumerasi.
+var ErrInvalidCredentials = errors.New("email atau password salah")
+
+// LoginThrottledError dikembalikan saat akun/IP sedang dikunci atau masih dalam jeda progresif.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return "terlalu banyak percobaan login, coba lagi nanti"
+}
+
+// LoginGuard melacak kegagalan login per akun dan per IP:
+// - setiap kegagalan akun memberi jeda progresif (LOGIN_DELAY_BASE × 2^(n-1)) sebelum percobaan berikutnya;
+// - LOGIN_MAX_FAILURES kegagalan akun atau LOGIN_IP_MAX_FAILURES kegagalan IP → dikunci LOGIN_LOCKOUT_DURATION.
+// Akun dilacak berdasarkan email yang dikirim, terdaftar atau tidak, sehingga respons tidak membocorkan keberadaan akun.
+// Percobaan akun dipesan (dihitung gagal) sebelum password diperiksa lalu dihapus saat sukses, sehingga
+// tebakan paralel tidak bisa lolos bersamaan sebelum kegagalan pertama tercatat.
+// Kode 2FA pada langkah kedua login dilacak per user dengan cara yang sama (MFA_MAX_FAILURES).
+type LoginGuard struct {
+	repo   repository.LoginAttemptRepository
+	cfg    *config.Config
+	logger *zap.Logger
+}
+
+// NewLoginGuard membuat instance LoginGuard baru.
+func NewLoginGuard(repo repository.LoginAttemptRepository, cfg *config.Config, logger *zap.Logger) *LoginGuard {
+	return &LoginGuard{repo: repo, cfg: cfg, logger: logger}
+}
+
+// reserveRetries adalah batas pengulangan compare-and-swap saat request lain mengubah catatan akun bersamaan.
+const reserveRetries = 3
+
+func accountKey(email string) string { return "acct:" + strings.ToLower(strings.TrimSpace(email)) }
+func ipKey(ip string) string         { return "ip:" + ip }
+func mfaKey(userID uuid.UUID) string { return "mfa:" + userID.String() }
+
+// Reserve dipanggil sebelum password diperiksa. Mengembalikan *LoginThrottledError jika akun atau IP
+// sedang dikunci, atau jeda progresif akun belum lewat. Jika diizinkan, percobaan ini langsung dicatat
+// sebagai kegagalan akun secara atomik; Succeed menghapusnya bila password benar.
+func (g *LoginGuard) Reserve(ctx context.Context, email, ip string) error {
+	now := time.Now()
+	if ip != "" {
+		byIP, err := g.repo.Get(ctx, ipKey(ip))
+		if err != nil {
+			return err
+		}
+		if byIP != nil {
+			if wait := lockRemaining(byIP.LockedUntil, now); wait > 0 {
+				return &LoginThrottledError{RetryAfter: wait}
+			}
+		}
+	}
+
+	return g.reserve(ctx, accountKey(email), g.cfg.LoginMaxFailures, now, zap.String("email", email))
+}
+
+// ReserveMFA dipanggil sebelum kode 2FA diperiksa, dengan aturan yang sama seperti Reserve untuk akun:
+// percobaan dicatat gagal lebih dulu, jeda progresif berlaku, dan MFA_MAX_FAILURES kegagalan mengunci
+// verifikasi 2FA user selama LOGIN_LOCKOUT_DURATION. SucceedMFA menghapusnya bila kode benar.
+func (g *LoginGuard) ReserveMFA(ctx context.Context, userID uuid.UUID) error {
+	return g.reserve(ctx, mfaKey(userID), g.cfg.MFAMaxFailures, time.Now(), zap.String("user_id", userID.String()))
+}
+
+// SucceedMFA membersihkan counter 2FA user setelah kode valid.
+func (g *LoginGuard) SucceedMFA(ctx context.Context, userID uuid.UUID) {
+	if err := g.repo.Reset(ctx, mfaKey(userID)); err != nil {
+		g.logger.Warn("gagal reset percobaan 2FA", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+}
+
+// reserve memesan satu percobaan untuk key lewat compare-and-swap lalu mengunci key jika mencapai limit.
+func (g *LoginGuard) reserve(ctx context.Context, key string, limit int, now time.Time, field zap.Field) error {
+	for range reserveRetries {
+		acct, err := g.repo.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		failures := 1
+		if acct != nil {
+			wait := max(lockRemaining(acct.LockedUntil, now), acct.LastFailedAt.Add(g.delay(acct.Failures)).Sub(now))
+			if wait > 0 {
+				return &LoginThrottledError{RetryAfter: wait}
+			}
+			if !acct.LastFailedAt.Before(now.Add(-g.cfg.LoginFailureWindow)) {
+				// Limit sudah tercapai tetapi Lock dari request pemesannya belum tersimpan: tolak
+				// agar request paralel tidak menambah percobaan melewati limit selama jeda itu.
+				if limit > 0 && acct.Failures >= limit && (acct.LockedUntil == nil || !acct.LockedUntil.After(acct.LastFailedAt)) {
+					return &LoginThrottledError{RetryAfter: g.cfg.LoginLockoutDuration}
+				}
+				failures = acct.Failures + 1
+			}
+		}
+		reserved, err := g.repo.ReserveAttempt(ctx, key, acct, now, failures)
+		if err != nil {
+			return err
+		}
+		if reserved {
+			g.lockIfExceeded(ctx, key, failures, limit, now, field)
+			return nil
+		}
+		// Request lain memesan percobaan lebih dulu; baca ulang agar jeda progresifnya ikut berlaku.
+	}
+	return &LoginThrottledError{RetryAfter: max(g.cfg.LoginDelayBase, time.Second)}
+}
+
+// Fail mencatat kegagalan login untuk IP lalu menguncinya jika melewati ambang batas.
+// Kegagalan akun sudah tercatat saat Reserve.
+func (g *LoginGuard) Fail(ctx context.Context, email, ip string) {
+	if ip != "" {
+		g.record(ctx, ipKey(ip), g.cfg.LoginIPMaxFailures, time.Now(), zap.String("ip", ip))
+	}
+}
+
+// Succeed membersihkan counter akun setelah login berhasil (termasuk percobaan yang dipesan Reserve).
+// Counter IP sengaja tidak dibersihkan agar satu akun valid tidak bisa dipakai me-reset percobaan ke akun lain.
+func (g *LoginGuard) Succeed(ctx context.Context, email string) {
+	if err := g.repo.Reset(ctx, accountKey(email)); err != nil {
+		g.logger.Warn("gagal reset percobaan login", zap.String("email", email), zap.Error(err))
+	}
+}
+
+// Unlock membuka kunci akun (dipakai admin).
+func (g *LoginGuard) Unlock(ctx context.Context, email string) error {
+	return g.repo.Reset(ctx, accountKey(email))
+}
+
+func (g *LoginGuard) record(ctx context.Context, key string, limit int, now time.Time, field zap.Field) {
+	attempt, err := g.repo.RecordFailure(ctx, key, now, g.cfg.LoginFailureWindow)
+	if err != nil {
+		g.logger.Error("gagal mencatat percobaan login", field, zap.Error(err))
+		return
+	}
+	g.lockIfExceeded(ctx, key, attempt.Failures, limit, now, field)
+}
+
+// lockIfExceeded mengunci key selama LOGIN_LOCKOUT_DURATION jika failures mencapai limit.
+func (g *LoginGuard) lockIfExceeded(ctx context.Context, key string, failures, limit int, now time.Time, field zap.Field) {
+	if limit <= 0 || failures < limit {
+		return
+	}
+	until := now.Add(g.cfg.LoginLockoutDuration)
+	if err := g.repo.Lock(ctx, key, until); err != nil {
+		g.logger.Error("gagal mengunci percobaan login", field, zap.Error(err))
+		return
+	}
+	g.logger.Warn("security: login dikunci sementara karena terlalu banyak kegagalan",
+		field,
+		zap.Int("failures", failures),
+		zap.Time("locked_until", until),
+	)
+}
+
+// delay menghitung jeda progresif setelah n kegagalan, dibatasi durasi lockout.
+func (g *LoginGuard) delay(failures int) time.Duration {
+	if failures <= 0 || g.cfg.LoginDelayBase <= 0 {
+		return 0
+	}
+	d := g.cfg.LoginDelayBase
+	for i := 1; i < failures && d < g.cfg.LoginLockoutDuration; i++ {
+		d *= 2
+	}
+	return min(d, g.cfg.LoginLockoutDuration)
+}
+
+func lockRemaining(lockedUntil *time.Time, now time.Time) time.Duration {
+	if lockedUntil == nil {
+		return 0
+	}
+	return lockedUntil.Sub(now)
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository/memory"
+	"go.uber.org/zap"
+)
+
+func TestLoginGuardConcurrentReservations(t *testing.T) {
+	tests := []struct {
+		name      string
+		delayBase time.Duration
+		want      int
+	}{
+		// Tanpa jeda progresif, tepat LOGIN_MAX_FAILURES percobaan lolos sebelum akun dikunci
+		{name: "tanpa jeda", want: 5},
+		// Dengan jeda progresif, hanya satu percobaan yang lolos sebelum jeda berikutnya berlaku
+		{name: "jeda progresif", delayBase: time.Second, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				LoginMaxFailures:     5,
+				LoginIPMaxFailures:   100,
+				LoginFailureWindow:   15 * time.Minute,
+				LoginLockoutDuration: 15 * time.Minute,
+				LoginDelayBase:       tt.delayBase,
+			}
+			guard := NewLoginGuard(memory.NewLoginAttemptRepository(time.Hour), cfg, zap.NewNop())
+
+			const parallel = 50
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			allowed, throttled := 0, 0
+			start := make(chan struct{})
+			for range parallel {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					err := guard.Reserve(context.Background(), "budi@example.com", "203.0.113.1")
+					mu.Lock()
+					defer mu.Unlock()
+					var te *LoginThrottledError
+					switch {
+					case err == nil:
+						allowed++
+					case errors.As(err, &te):
+						throttled++
+					default:
+						t.Errorf("Reserve: %v", err)
+					}
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			if allowed != tt.want || allowed+throttled != parallel {
+				t.Fatalf("lolos %d, ditolak %d; want lolos tepat %d", allowed, throttled, tt.want)
+			}
+		})
+	}
+}
+
+// pendingLock menahan Lock seperti request pemesan yang belum sempat menyimpan kuncinya.
+type pendingLock struct {
+	repository.LoginAttemptRepository
+}
+
+func (pendingLock) Lock(context.Context, string, time.Time) error { return nil }
+
+func TestLoginGuardThrottlesWhileLockPending(t *testing.T) {
+	cfg := &config.Config{
+		LoginMaxFailures:     3,
+		LoginFailureWindow:   15 * time.Minute,
+		LoginLockoutDuration: 15 * time.Minute,
+	}
+	guard := NewLoginGuard(pendingLock{memory.NewLoginAttemptRepository(time.Hour)}, cfg, zap.NewNop())
+	ctx := context.Background()
+
+	for i := range cfg.LoginMaxFailures {
+		if err := guard.Reserve(ctx, "budi@example.com", ""); err != nil {
+			t.Fatalf("percobaan %d: %v", i+1, err)
+		}
+	}
+	var te *LoginThrottledError
+	if err := guard.Reserve(ctx, "budi@example.com", ""); !errors.As(err, &te) {
+		t.Fatalf("percobaan setelah limit: err = %v, want *LoginThrottledError", err)
+	}
+	if te.RetryAfter != cfg.LoginLockoutDuration {
+		t.Fatalf("RetryAfter = %v, want %v", te.RetryAfter, cfg.LoginLockoutDuration)
+	}
+}
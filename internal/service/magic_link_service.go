@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/mail"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidMagicLink dikembalikan untuk link yang tanda tangannya salah, kedaluwarsa, atau sudah dipakai.
+var ErrInvalidMagicLink = errors.New("link login tidak valid atau kedaluwarsa")
+
+// MagicLinkService mengelola login tanpa password: link bertanda tangan (JWT) dikirim via email,
+// dan jti-nya dicatat di user_tokens agar link hanya bisa dipakai sekali.
+type MagicLinkService struct {
+	userRepo   repository.UserRepository
+	tokenRepo  repository.UserTokenRepository
+	jwtService *JWTService
+	mailer     mail.Sender
+	validator  *validator.Validate
+	cfg        *config.Config
+	logger     *zap.Logger
+}
+
+// NewMagicLinkService membuat instance MagicLinkService baru.
+func NewMagicLinkService(
+	userRepo repository.UserRepository,
+	tokenRepo repository.UserTokenRepository,
+	jwtService *JWTService,
+	mailer mail.Sender,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *MagicLinkService {
+	return &MagicLinkService{
+		userRepo:   userRepo,
+		tokenRepo:  tokenRepo,
+		jwtService: jwtService,
+		mailer:     mailer,
+		validator:  validator.New(),
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+// Request memproses permintaan magic link. Seperti lupa password, hasilnya selalu nil (selain error validasi)
+// dan pengiriman dijalankan di background agar tidak membocorkan apakah email terdaftar.
+func (s *MagicLinkService) Request(ctx context.Context, req dto.MagicLinkRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+	go func() {
+		bgCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), resetMailTimeout)
+		defer cancel()
+		if err := s.sendLink(bgCtx, req.Email); err != nil {
+			s.logger.Error("gagal mengirim magic link", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Consume memverifikasi link lalu menandainya terpakai dan mengembalikan user pemiliknya.
+// Link terkirim ke inbox user sehingga email yang belum terverifikasi ikut ditandai terverifikasi.
+func (s *MagicLinkService) Consume(ctx context.Context, token string) (*domain.User, error) {
+	claims, err := s.jwtService.VerifyMagicLinkToken(token)
+	if err != nil {
+		return nil, ErrInvalidMagicLink
+	}
+	record, err := s.tokenRepo.FindByHash(ctx, domain.TokenPurposeMagicLink, hashOpaqueToken(token))
+	if err != nil || record.ID.String() != claims.ID || record.UserID != claims.UserID {
+		return nil, ErrInvalidMagicLink
+	}
+	if record.UsedAt != nil || time.Now().After(record.ExpiresAt) {
+		return nil, ErrInvalidMagicLink
+	}
+	used, err := s.tokenRepo.MarkUsed(ctx, record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrInvalidMagicLink
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, record.UserID)
+	if err != nil {
+		return nil, ErrInvalidMagicLink
+	}
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		if err := s.userRepo.MarkEmailVerified(ctx, user.ID, now); err != nil {
+			return nil, err
+		}
+		user.EmailVerifiedAt = &now
+	}
+	s.logger.Info("login magic link", zap.String("user_id", user.ID.String()))
+	return user, nil
+}
+
+// sendLink membuat token dan mengirim link jika akun ada, aktif, dan belum melewati batas per jam.
+func (s *MagicLinkService) sendLink(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil || user.Suspended() {
+		return nil // email tidak terdaftar / akun ditangguhkan: diam saja
+	}
+	count, err := s.tokenRepo.CountSince(ctx, user.ID, domain.TokenPurposeMagicLink, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if count >= int64(s.cfg.MagicLinkPerHour) {
+		s.logger.Info("permintaan magic link dibatasi", zap.String("user_id", user.ID.String()))
+		return nil
+	}
+
+	tokenID := uuid.New()
+	token, exp, err := s.jwtService.GenerateMagicLinkToken(user.ID, tokenID, s.cfg.MagicLinkTTL)
+	if err != nil {
+		return fmt.Errorf("gagal membuat magic link: %w", err)
+	}
+	record := &domain.UserToken{
+		ID:        tokenID,
+		UserID:    user.ID,
+		Purpose:   domain.TokenPurposeMagicLink,
+		TokenHash: hashOpaqueToken(token),
+		ExpiresAt: exp,
+	}
+	if err := s.tokenRepo.Create(ctx, record); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/auth/magic-link/callback?token=%s", s.cfg.APIBaseURL, url.QueryEscape(token))
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Link masuk ke akun Anda",
+		Body: fmt.Sprintf("Halo %s,\n\nKlik link berikut untuk masuk tanpa password:\n%s\n\nLink berlaku selama %s dan hanya bisa dipakai sekali. Abaikan email ini jika Anda tidak memintanya.",
+			user.Name, link, s.cfg.MagicLinkTTL),
+	})
+}
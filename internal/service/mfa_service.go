@@ -0,0 +1,463 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"github.com/itujun/project-ecommerce-go-next/internal/totp"
+	"go.uber.org/zap"
+)
+
+// Jenis tantangan MFA setelah password terverifikasi.
+const (
+	MFAChallengeNone   = ""               // langsung terbitkan sesi
+	MFAChallengeVerify = MFAPurposeVerify // minta kode TOTP / recovery code
+	MFAChallengeEnroll = MFAPurposeEnroll // role mewajibkan 2FA, user harus enrollment dulu
+)
+
+// Faktor pertama login yang dicatat pada mfa_token (klaim first_factor).
+const (
+	FirstFactorPassword  = "password"
+	FirstFactorMagicLink = "magic_link"
+	FirstFactorPhone     = "phone" // kode OTP SMS
+	FirstFactorPasskey   = "passkey"
+	FirstFactorOAuth     = "oauth"
+)
+
+const (
+	recoveryCodeCount = 10
+	totpSkew          = 1 // toleransi ±1 time step (30 detik)
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("2FA sudah aktif")
+	ErrMFANotEnabled     = errors.New("2FA belum aktif")
+	ErrMFANotEnrolling   = errors.New("enrollment 2FA belum dimulai")
+	ErrMFARequired       = errors.New("2FA wajib untuk role ini dan tidak bisa dinonaktifkan")
+	ErrInvalidMFACode    = errors.New("kode 2FA tidak valid")
+	// ErrSMSSecondFactor dikembalikan saat kode SMS dipakai sebagai faktor kedua setelah login dengan kode SMS:
+	// keduanya membuktikan hal yang sama (akses ke nomor HP), jadi harus TOTP atau recovery code.
+	ErrSMSSecondFactor = errors.New("login dengan nomor HP membutuhkan kode authenticator atau recovery code sebagai faktor kedua")
+)
+
+// MFAService mengelola 2FA: TOTP (RFC 6238) beserta recovery code, dan kode SMS ke nomor HP terverifikasi.
+// User dianggap ber-2FA jika salah satu metode aktif.
+type MFAService struct {
+	mfaRepo   repository.MFARepository
+	userRepo  repository.UserRepository
+	phone     *PhoneService
+	guard     *LoginGuard
+	validator *validator.Validate
+	cfg       *config.Config
+	logger    *zap.Logger
+}
+
+// NewMFAService membuat instance MFAService baru.
+func NewMFAService(mfaRepo repository.MFARepository, userRepo repository.UserRepository, phone *PhoneService, guard *LoginGuard, cfg *config.Config, logger *zap.Logger) *MFAService {
+	return &MFAService{
+		mfaRepo:   mfaRepo,
+		userRepo:  userRepo,
+		phone:     phone,
+		guard:     guard,
+		validator: validator.New(),
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+// IsRequired mengembalikan true jika role user termasuk MFA_REQUIRED_ROLES.
+func (s *MFAService) IsRequired(user *domain.User) bool {
+	return slices.Contains(s.cfg.MFARequiredRoles, user.Role.Name)
+}
+
+// Challenge menentukan langkah yang dibutuhkan setelah faktor pertama (firstFactor) user terverifikasi.
+// Login dengan kode SMS ditolak dengan ErrSMSSecondFactor jika 2FA satu-satunya adalah SMS.
+func (s *MFAService) Challenge(ctx context.Context, user *domain.User, firstFactor string) (string, error) {
+	enabled, err := s.isEnabled(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case firstFactor == FirstFactorPhone && !enabled && user.PhoneMFAEnabledAt != nil:
+		return "", ErrSMSSecondFactor
+	case enabled, user.PhoneMFAEnabledAt != nil:
+		return MFAChallengeVerify, nil
+	case s.IsRequired(user):
+		return MFAChallengeEnroll, nil
+	default:
+		return MFAChallengeNone, nil
+	}
+}
+
+// Status mengembalikan status 2FA milik user.
+func (s *MFAService) Status(ctx context.Context, userID uuid.UUID) (*dto.MFAStatusResponse, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user tidak ditemukan")
+	}
+	enabled, err := s.isEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.MFAStatusResponse{Enabled: enabled, Required: s.IsRequired(user), SMSEnabled: user.PhoneMFAEnabledAt != nil}
+	if enabled {
+		if res.RecoveryCodesRemaining, err = s.mfaRepo.CountUnusedRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// BeginEnrollment membuat secret baru (belum aktif) dan URI provisioning untuk QR code.
+// Memanggil ulang sebelum konfirmasi akan mengganti secret sebelumnya.
+func (s *MFAService) BeginEnrollment(ctx context.Context, userID uuid.UUID) (*dto.MFAEnrollmentResponse, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user tidak ditemukan")
+	}
+	if enabled, err := s.isEnabled(ctx, userID); err != nil {
+		return nil, err
+	} else if enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat secret 2FA: %w", err)
+	}
+	encrypted, err := s.encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Save(ctx, &domain.UserMFA{UserID: userID, SecretEncrypted: encrypted}); err != nil {
+		return nil, err
+	}
+	return &dto.MFAEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(secret, s.cfg.MFAIssuer, user.Email),
+	}, nil
+}
+
+// ConfirmEnrollment mengaktifkan 2FA setelah kode pertama valid, lalu membuat recovery code.
+func (s *MFAService) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, req dto.MFAConfirmRequest) (*dto.MFARecoveryCodesResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	mfa, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, ErrMFANotEnrolling
+	}
+	if mfa.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if err := s.guarded(ctx, userID, func() error { return s.checkTOTP(ctx, mfa, req.Code) }); err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Enable(ctx, userID, time.Now()); err != nil {
+		return nil, err
+	}
+	s.logger.Info("2FA diaktifkan", zap.String("user_id", userID.String()))
+	return s.replaceRecoveryCodes(ctx, userID)
+}
+
+// Verify memeriksa kode TOTP, recovery code, atau kode SMS pada langkah kedua login.
+func (s *MFAService) Verify(ctx context.Context, userID uuid.UUID, firstFactor string, req dto.MFAVerifyRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+	if req.SMSCode != "" && firstFactor == FirstFactorPhone {
+		return ErrSMSSecondFactor
+	}
+	return s.guarded(ctx, userID, func() error { return s.verifyCode(ctx, userID, req) })
+}
+
+// guarded menjalankan pemeriksaan kode 2FA (TOTP, recovery code, atau SMS) milik user.
+// Setiap percobaan dipesan lewat LoginGuard sebelum kode diperiksa, dengan kuota yang sama untuk
+// login, enrollment, dan pengelolaan 2FA; setelah MFA_MAX_FAILURES kegagalan dikembalikan
+// *LoginThrottledError sampai kunci berakhir.
+func (s *MFAService) guarded(ctx context.Context, userID uuid.UUID, check func() error) error {
+	if err := s.guard.ReserveMFA(ctx, userID); err != nil {
+		return err
+	}
+	if err := check(); err != nil {
+		return err
+	}
+	s.guard.SucceedMFA(ctx, userID)
+	return nil
+}
+
+func (s *MFAService) verifyCode(ctx context.Context, userID uuid.UUID, req dto.MFAVerifyRequest) error {
+	if req.SMSCode != "" {
+		user, err := s.smsUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return s.checkSMS(ctx, user, req.SMSCode)
+	}
+	mfa, err := s.enabledMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if req.Code != "" {
+		return s.checkTOTP(ctx, mfa, req.Code)
+	}
+	used, err := s.mfaRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(req.RecoveryCode))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+	s.logger.Info("recovery code 2FA dipakai", zap.String("user_id", userID.String()))
+	return nil
+}
+
+// Disable menonaktifkan TOTP 2FA setelah kode valid. Ditolak jika role user mewajibkan 2FA
+// dan 2FA SMS tidak aktif sebagai gantinya.
+func (s *MFAService) Disable(ctx context.Context, userID uuid.UUID, req dto.MFACodeRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user tidak ditemukan")
+	}
+	if s.IsRequired(user) && user.PhoneMFAEnabledAt == nil {
+		return ErrMFARequired
+	}
+	mfa, err := s.enabledMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.guarded(ctx, userID, func() error { return s.checkTOTP(ctx, mfa, req.Code) }); err != nil {
+		return err
+	}
+	s.logger.Info("2FA dinonaktifkan", zap.String("user_id", userID.String()))
+	return s.mfaRepo.Delete(ctx, userID)
+}
+
+// RegenerateRecoveryCodes mengganti seluruh recovery code setelah kode TOTP valid.
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, req dto.MFACodeRequest) (*dto.MFARecoveryCodesResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	mfa, err := s.enabledMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.guarded(ctx, userID, func() error { return s.checkTOTP(ctx, mfa, req.Code) }); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(ctx, userID)
+}
+
+// SendSMSCode mengirim kode 2FA SMS ke nomor user; hanya untuk user yang mengaktifkan 2FA SMS.
+func (s *MFAService) SendSMSCode(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.smsUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.phone.SendMFACode(ctx, user)
+}
+
+// EnableSMS mengaktifkan 2FA SMS. Nomor HP harus sudah diverifikasi lewat OTP sehingga
+// kepemilikan nomor sudah terbukti dan tidak perlu kode tambahan.
+func (s *MFAService) EnableSMS(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user tidak ditemukan")
+	}
+	if user.Phone == nil || user.PhoneVerifiedAt == nil {
+		return ErrPhoneNotVerified
+	}
+	if user.PhoneMFAEnabledAt != nil {
+		return ErrMFAAlreadyEnabled
+	}
+	now := time.Now()
+	if err := s.userRepo.SetPhoneMFA(ctx, userID, &now); err != nil {
+		return err
+	}
+	s.logger.Info("2FA SMS diaktifkan", zap.String("user_id", userID.String()))
+	return nil
+}
+
+// DisableSMS menonaktifkan 2FA SMS setelah kode SMS valid. Ditolak jika role user mewajibkan 2FA
+// dan TOTP tidak aktif sebagai gantinya.
+func (s *MFAService) DisableSMS(ctx context.Context, userID uuid.UUID, req dto.MFASMSCodeRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+	user, err := s.smsUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.IsRequired(user) {
+		if enabled, err := s.isEnabled(ctx, userID); err != nil {
+			return err
+		} else if !enabled {
+			return ErrMFARequired
+		}
+	}
+	if err := s.guarded(ctx, userID, func() error { return s.checkSMS(ctx, user, req.SMSCode) }); err != nil {
+		return err
+	}
+	s.logger.Info("2FA SMS dinonaktifkan", zap.String("user_id", userID.String()))
+	return s.userRepo.SetPhoneMFA(ctx, userID, nil)
+}
+
+// smsUser memuat user yang 2FA SMS-nya aktif.
+func (s *MFAService) smsUser(ctx context.Context, userID uuid.UUID) (*domain.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user tidak ditemukan")
+	}
+	if user.PhoneMFAEnabledAt == nil {
+		return nil, ErrMFANotEnabled
+	}
+	return user, nil
+}
+
+// checkSMS memvalidasi kode 2FA SMS; kode salah/kedaluwarsa dipetakan ke ErrInvalidMFACode.
+func (s *MFAService) checkSMS(ctx context.Context, user *domain.User, code string) error {
+	err := s.phone.VerifyMFACode(ctx, user, code)
+	if errors.Is(err, ErrInvalidOTP) || errors.Is(err, ErrPhoneNotVerified) {
+		return ErrInvalidMFACode
+	}
+	return err
+}
+
+// isEnabled mengembalikan true jika user punya TOTP 2FA yang sudah dikonfirmasi.
+func (s *MFAService) isEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	mfa, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return mfa != nil && mfa.EnabledAt != nil, nil
+}
+
+func (s *MFAService) enabledMFA(ctx context.Context, userID uuid.UUID) (*domain.UserMFA, error) {
+	mfa, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil || mfa.EnabledAt == nil {
+		return nil, ErrMFANotEnabled
+	}
+	return mfa, nil
+}
+
+// checkTOTP memvalidasi kode dan menolak kode (time step) yang sudah pernah dipakai.
+func (s *MFAService) checkTOTP(ctx context.Context, mfa *domain.UserMFA, code string) error {
+	secret, err := s.decrypt(mfa.SecretEncrypted)
+	if err != nil {
+		return err
+	}
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	fresh, err := s.mfaRepo.UseStep(ctx, mfa.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// replaceRecoveryCodes membuat recovery code baru; plaintext hanya dikembalikan sekali.
+func (s *MFAService) replaceRecoveryCodes(ctx context.Context, userID uuid.UUID) (*dto.MFARecoveryCodesResponse, error) {
+	plain := make([]string, 0, recoveryCodeCount)
+	records := make([]domain.MFARecoveryCode, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		plain = append(plain, code)
+		records = append(records, domain.MFARecoveryCode{
+			ID:       uuid.New(),
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		})
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, records); err != nil {
+		return nil, err
+	}
+	return &dto.MFARecoveryCodesResponse{RecoveryCodes: plain}, nil
+}
+
+// encrypt mengenkripsi secret TOTP dengan AES-256-GCM; hasil = base64(nonce || ciphertext).
+func (s *MFAService) encrypt(plain string) (string, error) {
+	gcm, err := s.aead()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plain), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *MFAService) decrypt(encoded string) (string, error) {
+	gcm, err := s.aead()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", errors.New("secret 2FA rusak")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("secret 2FA tidak bisa didekripsi")
+	}
+	return string(plain), nil
+}
+
+func (s *MFAService) aead() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(s.cfg.MFAEncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newRecoveryCode membuat kode berformat xxxxx-xxxxx (base32 huruf kecil, 50-bit).
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	enc := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))[:10]
+	return enc[:5] + "-" + enc[5:], nil
+}
+
+// hashRecoveryCode menormalkan (huruf kecil, tanpa spasi/tanda hubung) lalu meng-hash recovery code.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return hashOpaqueToken(normalized)
+}
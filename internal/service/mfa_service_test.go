@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository/memory"
+	"github.com/itujun/project-ecommerce-go-next/internal/totp"
+	"go.uber.org/zap"
+)
+
+const testMFAMaxFailures = 3
+
+// newTOTPUser membuat MFAService dengan satu user yang TOTP-nya sedang enrollment (enabled=false)
+// atau sudah aktif, dan mengembalikan secret-nya.
+func newTOTPUser(t *testing.T, enabled bool) (*MFAService, uuid.UUID, string) {
+	t.Helper()
+	cfg := &config.Config{
+		MFAEncryptionKey:     "test-mfa-key",
+		MFAMaxFailures:       testMFAMaxFailures,
+		LoginFailureWindow:   15 * time.Minute,
+		LoginLockoutDuration: 15 * time.Minute,
+	}
+	user := &domain.User{ID: uuid.New(), Email: "budi@example.com"}
+	mfaRepo := &memMFA{}
+	guard := NewLoginGuard(memory.NewLoginAttemptRepository(time.Hour), cfg, zap.NewNop())
+	svc := NewMFAService(mfaRepo, staticUsers{users: map[uuid.UUID]*domain.User{user.ID: user}}, nil, guard, cfg, zap.NewNop())
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := svc.encrypt(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfaRepo.mfa = &domain.UserMFA{UserID: user.ID, SecretEncrypted: encrypted}
+	if enabled {
+		now := time.Now()
+		mfaRepo.mfa.EnabledAt = &now
+	}
+	return svc, user.ID, secret
+}
+
+func currentCode(t *testing.T, secret string) string {
+	t.Helper()
+	code, err := totp.CodeAt(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// wrongCode mengembalikan kode 6 digit yang tidak valid pada time step sekarang maupun tetangganya.
+func wrongCode(t *testing.T, secret string) string {
+	t.Helper()
+	for _, code := range []string{"000000", "111111", "222222", "333333"} {
+		if _, ok := totp.Validate(secret, code, time.Now(), totpSkew); !ok {
+			return code
+		}
+	}
+	t.Fatal("tidak menemukan kode salah")
+	return ""
+}
+
+func TestMFACodeChecksShareAttemptLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		check   func(svc *MFAService, userID uuid.UUID, code string) error
+	}{
+		{
+			name: "konfirmasi enrollment",
+			check: func(svc *MFAService, userID uuid.UUID, code string) error {
+				_, err := svc.ConfirmEnrollment(context.Background(), userID, dto.MFAConfirmRequest{Code: code})
+				return err
+			},
+		},
+		{
+			name:    "verifikasi login",
+			enabled: true,
+			check: func(svc *MFAService, userID uuid.UUID, code string) error {
+				return svc.Verify(context.Background(), userID, FirstFactorPassword, dto.MFAVerifyRequest{MFAToken: "x", Code: code})
+			},
+		},
+		{
+			name:    "nonaktifkan 2FA",
+			enabled: true,
+			check: func(svc *MFAService, userID uuid.UUID, code string) error {
+				return svc.Disable(context.Background(), userID, dto.MFACodeRequest{Code: code})
+			},
+		},
+		{
+			name:    "ganti recovery code",
+			enabled: true,
+			check: func(svc *MFAService, userID uuid.UUID, code string) error {
+				_, err := svc.RegenerateRecoveryCodes(context.Background(), userID, dto.MFACodeRequest{Code: code})
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, userID, secret := newTOTPUser(t, tt.enabled)
+			for range testMFAMaxFailures {
+				if err := tt.check(svc, userID, wrongCode(t, secret)); !errors.Is(err, ErrInvalidMFACode) {
+					t.Fatalf("kode salah: err = %v, want ErrInvalidMFACode", err)
+				}
+			}
+			// Setelah batas tercapai, kode benar pun ditolak tanpa diperiksa
+			var throttled *LoginThrottledError
+			if err := tt.check(svc, userID, currentCode(t, secret)); !errors.As(err, &throttled) {
+				t.Fatalf("setelah %d kegagalan: err = %v, want *LoginThrottledError", testMFAMaxFailures, err)
+			}
+			// Kuota dipakai bersama dengan langkah kedua login
+			if err := svc.Verify(context.Background(), userID, FirstFactorPassword, dto.MFAVerifyRequest{MFAToken: "x", Code: currentCode(t, secret)}); !errors.As(err, &throttled) {
+				t.Fatalf("verifikasi login setelah dikunci: err = %v, want *LoginThrottledError", err)
+			}
+		})
+	}
+}
+
+func TestSMSIsNotSecondFactorAfterPhoneLogin(t *testing.T) {
+	tests := []struct {
+		name        string
+		totp        bool
+		firstFactor string
+		want        string
+		wantErr     error
+	}{
+		{name: "login SMS, hanya 2FA SMS", firstFactor: FirstFactorPhone, wantErr: ErrSMSSecondFactor},
+		{name: "login SMS, TOTP aktif", totp: true, firstFactor: FirstFactorPhone, want: MFAChallengeVerify},
+		{name: "login password, hanya 2FA SMS", firstFactor: FirstFactorPassword, want: MFAChallengeVerify},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, userID, _ := newTOTPUser(t, tt.totp)
+			now := time.Now()
+			user := &domain.User{ID: userID, PhoneMFAEnabledAt: &now}
+
+			got, err := svc.Challenge(context.Background(), user, tt.firstFactor)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Fatalf("Challenge = %q, %v; want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	svc, userID, _ := newTOTPUser(t, true)
+	req := dto.MFAVerifyRequest{MFAToken: "x", SMSCode: "123456"}
+	if err := svc.Verify(context.Background(), userID, FirstFactorPhone, req); !errors.Is(err, ErrSMSSecondFactor) {
+		t.Fatalf("Verify kode SMS setelah login SMS: err = %v, want ErrSMSSecondFactor", err)
+	}
+}
+
+// memMFA adalah MFARepository in-memory untuk satu user.
+type memMFA struct {
+	repository.MFARepository
+
+	mu  sync.Mutex
+	mfa *domain.UserMFA
+}
+
+func (m *memMFA) GetByUserID(_ context.Context, userID uuid.UUID) (*domain.UserMFA, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.mfa == nil || m.mfa.UserID != userID {
+		return nil, nil
+	}
+	clone := *m.mfa
+	return &clone, nil
+}
+
+func (m *memMFA) Enable(_ context.Context, _ uuid.UUID, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mfa.EnabledAt = &at
+	return nil
+}
+
+func (m *memMFA) Delete(context.Context, uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mfa = nil
+	return nil
+}
+
+func (m *memMFA) UseStep(_ context.Context, _ uuid.UUID, step int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if step <= m.mfa.LastUsedStep {
+		return false, nil
+	}
+	m.mfa.LastUsedStep = step
+	return true, nil
+}
+
+func (m *memMFA) ReplaceRecoveryCodes(context.Context, uuid.UUID, []domain.MFARecoveryCode) error {
+	return nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/oauth"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+// OAuthStateTTL adalah masa berlaku cookie state login sosial (start sampai callback).
+const OAuthStateTTL = 10 * time.Minute
+
+var (
+	// ErrOAuthExchangeFailed dikembalikan saat code tidak bisa ditukar atau id_token tidak valid.
+	ErrOAuthExchangeFailed = errors.New("login sosial gagal diverifikasi")
+	// ErrOAuthEmailMissing dikembalikan saat provider tidak mengirim email (scope email tidak diberikan).
+	ErrOAuthEmailMissing = errors.New("provider tidak mengirim alamat email")
+	// ErrOAuthEmailConflict dikembalikan saat email sudah terdaftar tetapi belum bisa ditautkan otomatis.
+	ErrOAuthEmailConflict = errors.New("email sudah terdaftar; login dengan password lalu tautkan akun sosial")
+	// ErrOAuthIdentityLinked dikembalikan saat akun sosial sudah tertaut ke user lain.
+	ErrOAuthIdentityLinked = errors.New("akun sosial sudah tertaut ke user lain")
+)
+
+// OAuthService menangani login sosial: menukar code di provider, lalu mencari, menautkan, atau membuat user.
+type OAuthService struct {
+	providers    *oauth.Registry
+	userRepo     repository.UserRepository
+	roleRepo     repository.RoleRepository
+	identityRepo repository.UserIdentityRepository
+	cfg          *config.Config
+	logger       *zap.Logger
+}
+
+// NewOAuthService membuat instance OAuthService baru.
+func NewOAuthService(
+	providers *oauth.Registry,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	identityRepo repository.UserIdentityRepository,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *OAuthService {
+	return &OAuthService{
+		providers:    providers,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		identityRepo: identityRepo,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+// AuthCodeURL membuat URL authorize provider.
+func (s *OAuthService) AuthCodeURL(ctx context.Context, providerName, state, verifier, nonce string) (string, error) {
+	provider, err := s.providers.Get(providerName)
+	if err != nil {
+		return "", err
+	}
+	url, err := provider.AuthCodeURL(ctx, state, verifier, nonce)
+	if err != nil {
+		s.logger.Error("gagal membuat URL login sosial", zap.String("provider", providerName), zap.Error(err))
+		return "", err
+	}
+	return url, nil
+}
+
+// Exchange menukar authorization code dan mengembalikan identitas terverifikasi dari provider.
+func (s *OAuthService) Exchange(ctx context.Context, providerName, code, verifier, nonce string) (*oauth.Identity, error) {
+	provider, err := s.providers.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := provider.Exchange(ctx, code, verifier, nonce)
+	if err != nil {
+		s.logger.Warn("login sosial gagal", zap.String("provider", providerName), zap.Error(err))
+		return nil, ErrOAuthExchangeFailed
+	}
+	return identity, nil
+}
+
+// Login mengembalikan user untuk identitas sosial:
+//  1. identitas sudah tertaut → user pemiliknya;
+//  2. email sudah terdaftar → ditautkan otomatis hanya jika email terverifikasi di provider DAN di akun lokal
+//     (mencegah pre-hijacking lewat akun lokal yang didaftarkan dengan email korban);
+//  3. selain itu user baru dibuat dengan role buyer tanpa password.
+func (s *OAuthService) Login(ctx context.Context, providerName string, identity *oauth.Identity) (*domain.User, error) {
+	now := time.Now()
+	linked, err := s.identityRepo.FindBySubject(ctx, providerName, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if linked != nil {
+		if err := s.identityRepo.Touch(ctx, linked.ID, identity.Email, now); err != nil {
+			s.logger.Warn("gagal memperbarui identitas sosial", zap.String("identity_id", linked.ID.String()), zap.Error(err))
+		}
+		user, err := s.userRepo.GetUserByID(ctx, linked.UserID)
+		if err != nil {
+			return nil, ErrUserNotFound
+		}
+		return user, s.checkLoginAllowed(user)
+	}
+
+	if identity.Email == "" {
+		return nil, ErrOAuthEmailMissing
+	}
+
+	if existing, _ := s.userRepo.GetUserByEmail(ctx, identity.Email); existing != nil {
+		if !identity.EmailVerified || existing.EmailVerifiedAt == nil {
+			return nil, ErrOAuthEmailConflict
+		}
+		if err := s.createIdentity(ctx, existing.ID, providerName, identity, now); err != nil {
+			return nil, err
+		}
+		s.logger.Info("akun sosial ditautkan otomatis berdasarkan email",
+			zap.String("user_id", existing.ID.String()), zap.String("provider", providerName))
+		return existing, s.checkLoginAllowed(existing)
+	}
+
+	user, err := s.createUser(ctx, identity, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.createIdentity(ctx, user.ID, providerName, identity, now); err != nil {
+		return nil, err
+	}
+	s.logger.Info("user baru dari login sosial", zap.String("user_id", user.ID.String()), zap.String("provider", providerName))
+	return user, s.checkLoginAllowed(user)
+}
+
+// Link menautkan identitas sosial ke user yang sedang login.
+func (s *OAuthService) Link(ctx context.Context, userID uuid.UUID, providerName string, identity *oauth.Identity) error {
+	linked, err := s.identityRepo.FindBySubject(ctx, providerName, identity.Subject)
+	if err != nil {
+		return err
+	}
+	if linked != nil {
+		if linked.UserID != userID {
+			return ErrOAuthIdentityLinked
+		}
+		return s.identityRepo.Touch(ctx, linked.ID, identity.Email, time.Now())
+	}
+	if err := s.createIdentity(ctx, userID, providerName, identity, time.Now()); err != nil {
+		return err
+	}
+	s.logger.Info("akun sosial ditautkan", zap.String("user_id", userID.String()), zap.String("provider", providerName))
+	return nil
+}
+
+// checkLoginAllowed menerapkan aturan login yang sama dengan login password.
+func (s *OAuthService) checkLoginAllowed(user *domain.User) error {
+	if user.Suspended() {
+		return ErrAccountSuspended
+	}
+	if s.cfg.EmailVerificationMode == config.EmailVerificationLogin && user.EmailVerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// createUser membuat user baru dari identitas sosial. Password dikosongkan sehingga
+// login password tidak mungkin sampai user mengatur password lewat reset password.
+func (s *OAuthService) createUser(ctx context.Context, identity *oauth.Identity, now time.Time) (*domain.User, error) {
+	role, err := s.roleRepo.GetRoleByName(ctx, "buyer")
+	if err != nil {
+		return nil, errors.New("role default tidak ditemukan")
+	}
+	name := identity.Name
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+	user := &domain.User{
+		ID:     uuid.New(),
+		Name:   name,
+		Email:  identity.Email,
+		RoleID: role.ID,
+	}
+	if identity.EmailVerified {
+		user.EmailVerifiedAt = &now
+	}
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	user.Role = *role // dipakai LoginFlow (nama role untuk kebijakan 2FA)
+	return user, nil
+}
+
+func (s *OAuthService) createIdentity(ctx context.Context, userID uuid.UUID, providerName string, identity *oauth.Identity, now time.Time) error {
+	return s.identityRepo.Create(ctx, &domain.UserIdentity{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Provider:    providerName,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: &now,
+	})
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// newOpaqueToken membuat token acak 256-bit untuk dikirim ke user
+// beserta hash sha256-nya untuk disimpan di database.
+func newOpaqueToken() (plain string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	plain = base64.RawURLEncoding.EncodeToString(buf)
+	return plain, hashOpaqueToken(plain), nil
+}
+
+// hashOpaqueToken meng-hash token plaintext (jangan simpan plaintext di DB).
+func hashOpaqueToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/authorization"
 	"github.com/itujun/project-ecommerce-go-next/internal/domain"
 	"github.com/itujun/project-ecommerce-go-next/internal/dto"
 	"github.com/itujun/project-ecommerce-go-next/internal/repository"
@@ -19,32 +20,35 @@ type OrderService struct {
 	productRepo		repository.ProductRepository
 	userRepo		repository.UserRepository
 	validator		*validator.Validate
+	requireVerifiedEmail	bool // true jika pemesanan membutuhkan email terverifikasi
 }
 
 // NewOrderService mengembalikan instance baru OrderService.
-func NewOrderService(orderRepo repository.OrderRepository, orderItemRepo repository.OrderItemRepository, productRepo repository.ProductRepository, userRepo repository.UserRepository) *OrderService {
+func NewOrderService(orderRepo repository.OrderRepository, orderItemRepo repository.OrderItemRepository, productRepo repository.ProductRepository, userRepo repository.UserRepository, requireVerifiedEmail bool) *OrderService {
 	return &OrderService{
 		orderRepo: orderRepo,
 		orderItemRepo: orderItemRepo,
 		productRepo: productRepo,
 		userRepo: userRepo,
 		validator: validator.New(),
+		requireVerifiedEmail: requireVerifiedEmail,
 	}
 }
 
 // CreateOrder membuat pesanan baru untuk pembeli.
+// Izin membuat pesanan diperiksa policy Casbin (order:create) di middleware.
 func (s *OrderService) CreateOrder(ctx context.Context, buyerID uuid.UUID, req dto.CreateOrderRequest) (*dto.OrderResponse, error) {
 	// validasi request
 	if err := s.validator.Struct(req); err != nil {
 		return nil, err
 	}
-	// Ambil data pembeli untuk verifikasi role(buyer)
+	// Ambil data pembeli untuk cek verifikasi email
 	buyer, err := s.userRepo.GetUserByID(ctx, buyerID)
 	if err != nil {
 		return nil, fmt.Errorf("pembeli tidak ditemukan")
 	}
-	if buyer.Role.Name != "buyer" {
-		return nil, fmt.Errorf("hanya pembeli yang dapat membuat pesanan")
+	if s.requireVerifiedEmail && buyer.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
 	}
 
 	// Hitung total harga dan siapkan items
@@ -116,6 +120,28 @@ func (s *OrderService) CreateOrder(ctx context.Context, buyerID uuid.UUID, req d
 	}, nil
 }
 
+// OrderOwner mengembalikan ID pembeli pemilik pesanan; dipakai middleware.AuthorizeResource.
+func (s *OrderService) OrderOwner(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	order, err := s.orderRepo.GetOrderByID(ctx, id)
+	if err != nil {
+		return uuid.Nil, authorization.ErrResourceNotFound
+	}
+	return order.BuyerID, nil
+}
+
+// GetOrder mengembalikan detail satu pesanan. Kepemilikan diperiksa policy Casbin sebelum service dipanggil.
+func (s *OrderService) GetOrder(ctx context.Context, id uuid.UUID) (*dto.OrderResponse, error) {
+	order, err := s.orderRepo.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("pesanan tidak ditemukan")
+	}
+	responses, err := s.convertOrdersToResponses(ctx, []domain.Order{*order})
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
 // ListOrdersForBuyer mengembalikan semua pesanan untuk pembeli tertentu.
 func (s *OrderService) ListOrdersForBuyer(ctx context.Context, buyerID uuid.UUID) ([]dto.OrderResponse, error) {
     orders, err := s.orderRepo.ListOrdersByBuyer(ctx, buyerID)
@@ -168,7 +194,8 @@ func (s *OrderService) convertOrdersToResponses(ctx context.Context, orders []do
 }
 
 // Keterangan penting:
-// - CreateOrder memvalidasi input, memeriksa role pembeli, menghitung total, mengurangi stok produk, lalu menyimpan order dan item ke database.
+// - CreateOrder memvalidasi input, menghitung total, mengurangi stok produk, lalu menyimpan order dan item ke database.
 // - ListOrdersForBuyer mengembalikan pesanan milik pembeli tertentu.
-// - ListAllOrdersAdminSeller mengembalikan semua pesanan; hanya dipanggil oleh admin/seller.
+// - ListAllOrdersAdminSeller mengembalikan semua pesanan; dipanggil jika policy mengizinkan order:read tanpa syarat kepemilikan.
+// - Izin (role & kepemilikan) ditentukan policy Casbin di middleware, bukan di service.
 // - Anda dapat menambahkan metode untuk memperbarui status pesanan (dikirim, selesai, dll.) jika diperlukan.
\ No newline at end of file
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/mail"
+	"github.com/itujun/project-ecommerce-go-next/internal/password"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidResetToken dikembalikan untuk token reset yang tidak ada, kedaluwarsa, atau sudah dipakai.
+var ErrInvalidResetToken = errors.New("token reset password tidak valid atau kedaluwarsa")
+
+// resetMailTimeout membatasi durasi pembuatan token & pengiriman email di background.
+const resetMailTimeout = 30 * time.Second
+
+// PasswordResetService mengelola alur lupa password lewat token sekali pakai yang dikirim via email.
+type PasswordResetService struct {
+	userRepo   repository.UserRepository
+	tokenRepo  repository.UserTokenRepository
+	rtRepo     repository.RefreshTokenRepository
+	revocation *TokenRevocationService
+	mailer     mail.Sender
+	hasher     *password.Hasher
+	policy     *password.Policy
+	validator  *validator.Validate
+	cfg        *config.Config
+	logger     *zap.Logger
+}
+
+// NewPasswordResetService membuat instance PasswordResetService baru.
+func NewPasswordResetService(
+	userRepo repository.UserRepository,
+	tokenRepo repository.UserTokenRepository,
+	rtRepo repository.RefreshTokenRepository,
+	revocation *TokenRevocationService,
+	mailer mail.Sender,
+	hasher *password.Hasher,
+	policy *password.Policy,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *PasswordResetService {
+	return &PasswordResetService{
+		userRepo:   userRepo,
+		tokenRepo:  tokenRepo,
+		rtRepo:     rtRepo,
+		revocation: revocation,
+		mailer:     mailer,
+		hasher:     hasher,
+		policy:     policy,
+		validator:  validator.New(),
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+// Forgot memproses permintaan lupa password.
+// Selain error validasi, hasilnya selalu nil dan pekerjaan berat (token + email) dijalankan
+// di background, sehingga respons dan waktu respons tidak membocorkan apakah email terdaftar.
+func (s *PasswordResetService) Forgot(ctx context.Context, req dto.ForgotPasswordRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+	go func() {
+		bgCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), resetMailTimeout)
+		defer cancel()
+		if err := s.sendResetLink(bgCtx, req.Email); err != nil {
+			s.logger.Error("gagal mengirim link reset password", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Reset mengganti password memakai token reset, lalu mencabut seluruh refresh token user.
+func (s *PasswordResetService) Reset(ctx context.Context, req dto.ResetPasswordRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+	record, err := s.tokenRepo.FindByHash(ctx, domain.TokenPurposePasswordReset, hashOpaqueToken(req.Token))
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	if record.UsedAt != nil || time.Now().After(record.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+	// Periksa kebijakan sebelum token dipakai agar user bisa mencoba password lain dengan link yang sama
+	user, err := s.userRepo.GetUserByID(ctx, record.UserID)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	if err := s.policy.Check("password", req.Password, user.Name, user.Email); err != nil {
+		return err
+	}
+	// Tandai terpakai secara atomik agar token tidak bisa dipakai dua kali
+	used, err := s.tokenRepo.MarkUsed(ctx, record.ID)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidResetToken
+	}
+
+	hashed, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return fmt.Errorf("gagal membuat hash password: %w", err)
+	}
+	if err := s.userRepo.UpdatePassword(ctx, record.UserID, hashed); err != nil {
+		return err
+	}
+	// Token reset lain yang masih aktif tidak boleh dipakai lagi
+	if err := s.tokenRepo.InvalidateAll(ctx, record.UserID, domain.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+	// Keluarkan semua sesi: penyerang yang memegang RT lama harus login ulang
+	if err := s.rtRepo.RevokeAllByUser(ctx, record.UserID); err != nil {
+		return err
+	}
+	// AT yang sudah beredar juga langsung dicabut
+	if err := s.revocation.RevokeUser(ctx, record.UserID); err != nil {
+		return err
+	}
+
+	// Link reset terkirim ke inbox user → kepemilikan email terbukti
+	if user.EmailVerifiedAt == nil {
+		_ = s.userRepo.MarkEmailVerified(ctx, user.ID, time.Now())
+	}
+	s.logger.Info("password direset", zap.String("user_id", record.UserID.String()))
+	return nil
+}
+
+// sendResetLink membuat token reset dan mengirim link ke email jika akun ada dan belum melewati batas.
+func (s *PasswordResetService) sendResetLink(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil // email tidak terdaftar: diam saja
+	}
+	count, err := s.tokenRepo.CountSince(ctx, user.ID, domain.TokenPurposePasswordReset, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if count >= int64(s.cfg.PasswordResetPerHour) {
+		s.logger.Info("permintaan reset password dibatasi", zap.String("user_id", user.ID.String()))
+		return nil
+	}
+
+	plain, hash, err := newOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("gagal membuat token reset: %w", err)
+	}
+	record := &domain.UserToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Purpose:   domain.TokenPurposePasswordReset,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.cfg.PasswordResetTTL),
+	}
+	if err := s.tokenRepo.Create(ctx, record); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.cfg.FrontendURL, url.QueryEscape(plain))
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset password",
+		Body: fmt.Sprintf("Halo %s,\n\nKami menerima permintaan reset password untuk akun Anda. Klik link berikut:\n%s\n\nLink berlaku selama %s dan hanya bisa dipakai sekali. Abaikan email ini jika Anda tidak memintanya.",
+			user.Name, link, s.cfg.PasswordResetTTL),
+	})
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"github.com/itujun/project-ecommerce-go-next/internal/sms"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidPhone     = errors.New("nomor HP tidak valid")
+	ErrPhoneTaken       = errors.New("nomor HP sudah dipakai akun lain")
+	ErrPhoneNotVerified = errors.New("nomor HP belum diverifikasi")
+	ErrPhoneMFAEnabled  = errors.New("nonaktifkan 2FA SMS sebelum menghapus nomor HP")
+	ErrInvalidOTP       = errors.New("kode OTP tidak valid atau kedaluwarsa")
+	ErrOTPRateLimited   = errors.New("terlalu banyak permintaan kode, coba lagi nanti")
+)
+
+// e164Pattern adalah nomor E.164 setelah normalisasi: + lalu 8–15 digit tanpa awalan 0.
+var e164Pattern = regexp.MustCompile(`^\+[1-9]\d{7,14}$`)
+
+// PhoneService mengelola nomor HP user dan kode OTP SMS untuk tiga keperluan:
+// verifikasi nomor, login dengan nomor sebagai faktor utama, dan 2FA SMS.
+// Kode 6 digit disimpan sebagai hash, berumur PHONE_OTP_TTL, dan hangus setelah
+// PHONE_OTP_MAX_ATTEMPTS tebakan salah.
+type PhoneService struct {
+	userRepo  repository.UserRepository
+	otpRepo   repository.PhoneOTPRepository
+	sender    sms.Sender
+	validator *validator.Validate
+	cfg       *config.Config
+	logger    *zap.Logger
+}
+
+// NewPhoneService membuat instance PhoneService baru.
+func NewPhoneService(
+	userRepo repository.UserRepository,
+	otpRepo repository.PhoneOTPRepository,
+	sender sms.Sender,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *PhoneService {
+	return &PhoneService{
+		userRepo:  userRepo,
+		otpRepo:   otpRepo,
+		sender:    sender,
+		validator: validator.New(),
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+// Phone mengembalikan nomor HP user beserta status verifikasi dan 2FA SMS.
+func (s *PhoneService) Phone(ctx context.Context, userID uuid.UUID) (*dto.PhoneResponse, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return phoneResponse(user), nil
+}
+
+// RequestVerification mengirim kode OTP ke nomor baru. Nomor baru tersimpan di akun
+// setelah kode dikonfirmasi lewat ConfirmVerification; nomor lama tetap berlaku sampai saat itu.
+func (s *PhoneService) RequestVerification(ctx context.Context, userID uuid.UUID, req dto.PhoneRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+	phone, err := s.NormalizePhone(req.Phone)
+	if err != nil {
+		return err
+	}
+	if err := s.ensurePhoneAvailable(ctx, userID, phone); err != nil {
+		return err
+	}
+	return s.issue(ctx, userID, phone, domain.PhoneOTPPurposeVerify)
+}
+
+// ConfirmVerification menyimpan nomor HP setelah kode OTP verifikasi valid.
+func (s *PhoneService) ConfirmVerification(ctx context.Context, userID uuid.UUID, req dto.PhoneCodeRequest) (*dto.PhoneResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	otp, err := s.check(ctx, userID, domain.PhoneOTPPurposeVerify, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	// Nomor bisa saja diklaim akun lain selama kode menunggu konfirmasi; unique index menangkap
+	// klaim bersamaan yang lolos dari pengecekan ini
+	if err := s.ensurePhoneAvailable(ctx, userID, otp.Phone); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.userRepo.SetPhone(ctx, userID, &otp.Phone, &now); err != nil {
+		if errors.Is(err, repository.ErrDuplicatePhone) {
+			return nil, ErrPhoneTaken
+		}
+		return nil, err
+	}
+	// Kode login/2FA yang sempat terkirim ke nomor lama tidak boleh dipakai lagi
+	for _, purpose := range []string{domain.PhoneOTPPurposeLogin, domain.PhoneOTPPurposeMFA} {
+		if err := s.otpRepo.InvalidateAll(ctx, userID, purpose); err != nil {
+			return nil, err
+		}
+	}
+	s.logger.Info("nomor HP diverifikasi", zap.String("user_id", userID.String()))
+	return s.Phone(ctx, userID)
+}
+
+// RemovePhone menghapus nomor HP user. Ditolak selama 2FA SMS aktif agar 2FA tidak hilang diam-diam.
+func (s *PhoneService) RemovePhone(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.PhoneMFAEnabledAt != nil {
+		return ErrPhoneMFAEnabled
+	}
+	if err := s.userRepo.SetPhone(ctx, userID, nil, nil); err != nil {
+		return err
+	}
+	for _, purpose := range []string{domain.PhoneOTPPurposeVerify, domain.PhoneOTPPurposeLogin, domain.PhoneOTPPurposeMFA} {
+		if err := s.otpRepo.InvalidateAll(ctx, userID, purpose); err != nil {
+			return err
+		}
+	}
+	s.logger.Info("nomor HP dihapus", zap.String("user_id", userID.String()))
+	return nil
+}
+
+// RequestLoginCode memproses permintaan kode login via SMS. Seperti magic link, hasilnya selalu nil
+// (selain error validasi) dan pengiriman dijalankan di background agar tidak membocorkan nomor mana yang terdaftar.
+func (s *PhoneService) RequestLoginCode(ctx context.Context, req dto.PhoneRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+	phone, err := s.NormalizePhone(req.Phone)
+	if err != nil {
+		return err
+	}
+	go func() {
+		bgCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), resetMailTimeout)
+		defer cancel()
+		if err := s.sendLoginCode(bgCtx, phone); err != nil {
+			s.logger.Error("gagal mengirim kode login SMS", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Login memverifikasi kode login SMS dan mengembalikan user pemilik nomor.
+// Semua kegagalan (nomor tidak terdaftar, kode salah/kedaluwarsa) dikembalikan sebagai ErrInvalidOTP.
+func (s *PhoneService) Login(ctx context.Context, req dto.PhoneLoginRequest) (*domain.User, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	phone, err := s.NormalizePhone(req.Phone)
+	if err != nil {
+		return nil, ErrInvalidOTP
+	}
+	user, err := s.userRepo.GetUserByPhone(ctx, phone)
+	if err != nil || user.PhoneVerifiedAt == nil {
+		return nil, ErrInvalidOTP
+	}
+	otp, err := s.check(ctx, user.ID, domain.PhoneOTPPurposeLogin, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if otp.Phone != phone {
+		return nil, ErrInvalidOTP
+	}
+	s.logger.Info("login dengan kode SMS", zap.String("user_id", user.ID.String()))
+	return user, nil
+}
+
+// SendMFACode mengirim kode 2FA ke nomor terverifikasi milik user.
+func (s *PhoneService) SendMFACode(ctx context.Context, user *domain.User) error {
+	if user.Phone == nil || user.PhoneVerifiedAt == nil {
+		return ErrPhoneNotVerified
+	}
+	return s.issue(ctx, user.ID, *user.Phone, domain.PhoneOTPPurposeMFA)
+}
+
+// VerifyMFACode memeriksa kode 2FA SMS; kode harus dikirim ke nomor user yang berlaku saat ini.
+func (s *PhoneService) VerifyMFACode(ctx context.Context, user *domain.User, code string) error {
+	if user.Phone == nil || user.PhoneVerifiedAt == nil {
+		return ErrPhoneNotVerified
+	}
+	otp, err := s.check(ctx, user.ID, domain.PhoneOTPPurposeMFA, code)
+	if err != nil {
+		return err
+	}
+	if otp.Phone != *user.Phone {
+		return ErrInvalidOTP
+	}
+	return nil
+}
+
+// NormalizePhone mengubah nomor ke format E.164: spasi, tanda hubung, titik, dan kurung dibuang;
+// awalan 00 menjadi +, dan nomor lokal berawalan 0 diberi kode negara PHONE_DEFAULT_COUNTRY_CODE.
+func (s *PhoneService) NormalizePhone(raw string) (string, error) {
+	phone := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(strings.TrimSpace(raw))
+	switch {
+	case strings.HasPrefix(phone, "+"):
+	case strings.HasPrefix(phone, "00"):
+		phone = "+" + phone[2:]
+	case strings.HasPrefix(phone, "0"):
+		phone = "+" + s.cfg.PhoneDefaultCountryCode + phone[1:]
+	case strings.HasPrefix(phone, s.cfg.PhoneDefaultCountryCode):
+		phone = "+" + phone
+	default:
+		return "", ErrInvalidPhone
+	}
+	if !e164Pattern.MatchString(phone) {
+		return "", ErrInvalidPhone
+	}
+	return phone, nil
+}
+
+// sendLoginCode mengirim kode jika nomor terdaftar dan terverifikasi, akun aktif, dan belum melewati batas per jam.
+func (s *PhoneService) sendLoginCode(ctx context.Context, phone string) error {
+	user, err := s.userRepo.GetUserByPhone(ctx, phone)
+	if err != nil || user.PhoneVerifiedAt == nil || user.Suspended() {
+		return nil // nomor tidak terdaftar / belum diverifikasi / akun ditangguhkan: diam saja
+	}
+	err = s.issue(ctx, user.ID, phone, domain.PhoneOTPPurposeLogin)
+	if errors.Is(err, ErrOTPRateLimited) {
+		s.logger.Info("permintaan kode login SMS dibatasi", zap.String("user_id", user.ID.String()))
+		return nil
+	}
+	return err
+}
+
+// ensurePhoneAvailable menolak nomor yang sudah dipakai akun lain.
+func (s *PhoneService) ensurePhoneAvailable(ctx context.Context, userID uuid.UUID, phone string) error {
+	owner, err := s.userRepo.GetUserByPhone(ctx, phone)
+	if err == nil && owner.ID != userID {
+		return ErrPhoneTaken
+	}
+	return nil
+}
+
+// issue membuat kode baru (kode aktif sebelumnya untuk tujuan yang sama dibatalkan) lalu mengirimnya via SMS.
+func (s *PhoneService) issue(ctx context.Context, userID uuid.UUID, phone, purpose string) error {
+	count, err := s.otpRepo.CountSince(ctx, userID, purpose, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if count >= int64(s.cfg.PhoneOTPPerHour) {
+		return ErrOTPRateLimited
+	}
+	code, err := newOTPCode()
+	if err != nil {
+		return fmt.Errorf("gagal membuat kode OTP: %w", err)
+	}
+	if err := s.otpRepo.InvalidateAll(ctx, userID, purpose); err != nil {
+		return err
+	}
+	otp := &domain.PhoneOTP{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Phone:     phone,
+		Purpose:   purpose,
+		CodeHash:  hashOpaqueToken(code),
+		ExpiresAt: time.Now().Add(s.cfg.PhoneOTPTTL),
+	}
+	if err := s.otpRepo.Create(ctx, otp); err != nil {
+		return err
+	}
+	return s.sender.Send(ctx, sms.Message{
+		To: phone,
+		Body: fmt.Sprintf("%s: kode %s Anda %s, berlaku %s. JANGAN berikan kode ini kepada siapa pun.",
+			s.cfg.MFAIssuer, otpPurposeLabel(purpose), code, s.cfg.PhoneOTPTTL),
+	})
+}
+
+// check memvalidasi kode terhadap kode aktif terbaru. Setiap tebakan dipesan lebih dulu (attempts + 1
+// secara atomik) sebelum kode dibandingkan, sehingga tebakan paralel tidak bisa melewati
+// PHONE_OTP_MAX_ATTEMPTS; kode hangus saat jatahnya habis dan kode yang benar ditandai terpakai (sekali pakai).
+func (s *PhoneService) check(ctx context.Context, userID uuid.UUID, purpose, code string) (*domain.PhoneOTP, error) {
+	otp, err := s.otpRepo.FindActive(ctx, userID, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if otp == nil || time.Now().After(otp.ExpiresAt) {
+		return nil, ErrInvalidOTP
+	}
+	reserved, err := s.otpRepo.ReserveAttempt(ctx, otp.ID, s.cfg.PhoneOTPMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !reserved {
+		return nil, ErrInvalidOTP
+	}
+	if subtle.ConstantTimeCompare([]byte(hashOpaqueToken(code)), []byte(otp.CodeHash)) != 1 {
+		if otp.Attempts+1 >= s.cfg.PhoneOTPMaxAttempts {
+			if _, err := s.otpRepo.MarkUsed(ctx, otp.ID); err != nil {
+				return nil, err
+			}
+			s.logger.Info("kode OTP hangus karena terlalu banyak percobaan",
+				zap.String("user_id", userID.String()), zap.String("purpose", purpose))
+		}
+		return nil, ErrInvalidOTP
+	}
+	used, err := s.otpRepo.MarkUsed(ctx, otp.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrInvalidOTP
+	}
+	return otp, nil
+}
+
+// newOTPCode membuat kode 6 digit acak (000000–999999).
+func newOTPCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func otpPurposeLabel(purpose string) string {
+	switch purpose {
+	case domain.PhoneOTPPurposeVerify:
+		return "verifikasi nomor HP"
+	case domain.PhoneOTPPurposeLogin:
+		return "login"
+	default:
+		return "2FA"
+	}
+}
+
+func phoneResponse(user *domain.User) *dto.PhoneResponse {
+	return &dto.PhoneResponse{
+		Phone:      user.Phone,
+		VerifiedAt: user.PhoneVerifiedAt,
+		MFAEnabled: user.PhoneMFAEnabledAt != nil,
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"github.com/itujun/project-ecommerce-go-next/internal/sms"
+	"go.uber.org/zap"
+)
+
+// memPhoneOTPs adalah PhoneOTPRepository in-memory dengan syarat update yang sama seperti implementasi database.
+type memPhoneOTPs struct {
+	mu   sync.Mutex
+	otps []*domain.PhoneOTP
+}
+
+var _ repository.PhoneOTPRepository = (*memPhoneOTPs)(nil)
+
+func (m *memPhoneOTPs) Create(_ context.Context, otp *domain.PhoneOTP) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cp := *otp
+	cp.CreatedAt = time.Now()
+	m.otps = append(m.otps, &cp)
+	return nil
+}
+
+func (m *memPhoneOTPs) FindActive(_ context.Context, userID uuid.UUID, purpose string) (*domain.PhoneOTP, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.otps) - 1; i >= 0; i-- {
+		if otp := m.otps[i]; otp.UserID == userID && otp.Purpose == purpose && otp.UsedAt == nil {
+			cp := *otp
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memPhoneOTPs) ReserveAttempt(_ context.Context, id uuid.UUID, maxAttempts int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	otp := m.find(id)
+	if otp == nil || otp.UsedAt != nil || otp.Attempts >= maxAttempts {
+		return false, nil
+	}
+	otp.Attempts++
+	return true, nil
+}
+
+func (m *memPhoneOTPs) MarkUsed(_ context.Context, id uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	otp := m.find(id)
+	if otp == nil || otp.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	otp.UsedAt = &now
+	return true, nil
+}
+
+func (m *memPhoneOTPs) InvalidateAll(_ context.Context, userID uuid.UUID, purpose string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for _, otp := range m.otps {
+		if otp.UserID == userID && otp.Purpose == purpose && otp.UsedAt == nil {
+			otp.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *memPhoneOTPs) CountSince(_ context.Context, userID uuid.UUID, purpose string, since time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for _, otp := range m.otps {
+		if otp.UserID == userID && otp.Purpose == purpose && !otp.CreatedAt.Before(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memPhoneOTPs) find(id uuid.UUID) *domain.PhoneOTP {
+	for _, otp := range m.otps {
+		if otp.ID == id {
+			return otp
+		}
+	}
+	return nil
+}
+
+// lastSMS menyimpan pesan SMS terakhir agar test bisa membaca kode yang dikirim.
+type lastSMS struct {
+	mu  sync.Mutex
+	msg sms.Message
+}
+
+func (s *lastSMS) Send(_ context.Context, msg sms.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msg = msg
+	return nil
+}
+
+var otpCodePattern = regexp.MustCompile(`\b\d{6}\b`)
+
+func (s *lastSMS) code(t *testing.T) string {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code := otpCodePattern.FindString(s.msg.Body)
+	if code == "" {
+		t.Fatalf("SMS tanpa kode: %q", s.msg.Body)
+	}
+	return code
+}
+
+func newPhoneFixture(t *testing.T) (*PhoneService, *memPhoneOTPs, *lastSMS, *domain.User) {
+	t.Helper()
+	cfg := &config.Config{
+		PhoneOTPTTL:         5 * time.Minute,
+		PhoneOTPMaxAttempts: 3,
+		PhoneOTPPerHour:     10,
+		MFAIssuer:           "Ecommerce",
+	}
+	otps, sender := &memPhoneOTPs{}, &lastSMS{}
+	phone, now := "+6281234567890", time.Now()
+	user := &domain.User{ID: uuid.New(), Phone: &phone, PhoneVerifiedAt: &now}
+	return NewPhoneService(nil, otps, sender, cfg, zap.NewNop()), otps, sender, user
+}
+
+func TestPhoneOTPConcurrentGuessesStopAtMaxAttempts(t *testing.T) {
+	svc, otps, sender, user := newPhoneFixture(t)
+	if err := svc.SendMFACode(context.Background(), user); err != nil {
+		t.Fatalf("SendMFACode: %v", err)
+	}
+	code := sender.code(t)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	const parallel = 30
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for range parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if err := svc.VerifyMFACode(context.Background(), user, wrong); !errors.Is(err, ErrInvalidOTP) {
+				t.Errorf("tebakan salah: err = %v, want ErrInvalidOTP", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if got := otps.otps[0].Attempts; got != svc.cfg.PhoneOTPMaxAttempts {
+		t.Fatalf("attempts = %d, want tepat %d", got, svc.cfg.PhoneOTPMaxAttempts)
+	}
+	if err := svc.VerifyMFACode(context.Background(), user, code); !errors.Is(err, ErrInvalidOTP) {
+		t.Fatalf("kode benar setelah jatah habis: err = %v, want ErrInvalidOTP", err)
+	}
+}
+
+func TestPhoneOTPIsSingleUse(t *testing.T) {
+	svc, _, sender, user := newPhoneFixture(t)
+	if err := svc.SendMFACode(context.Background(), user); err != nil {
+		t.Fatalf("SendMFACode: %v", err)
+	}
+	code := sender.code(t)
+	if err := svc.VerifyMFACode(context.Background(), user, code); err != nil {
+		t.Fatalf("kode benar: %v", err)
+	}
+	if err := svc.VerifyMFACode(context.Background(), user, code); !errors.Is(err, ErrInvalidOTP) {
+		t.Fatalf("kode dipakai ulang: err = %v, want ErrInvalidOTP", err)
+	}
+}
+
+// phoneClaimedConcurrently mensimulasikan akun lain yang memverifikasi nomor yang sama setelah
+// pengecekan ketersediaan lolos: SetPhone ditolak unique index.
+type phoneClaimedConcurrently struct {
+	repository.UserRepository
+}
+
+func (phoneClaimedConcurrently) GetUserByPhone(context.Context, string) (*domain.User, error) {
+	return nil, errors.New("user tidak ditemukan")
+}
+
+func (phoneClaimedConcurrently) SetPhone(context.Context, uuid.UUID, *string, *time.Time) error {
+	return repository.ErrDuplicatePhone
+}
+
+func TestConfirmVerificationMapsDuplicatePhoneToTaken(t *testing.T) {
+	svc, _, sender, user := newPhoneFixture(t)
+	svc.userRepo = phoneClaimedConcurrently{}
+	ctx := context.Background()
+
+	if err := svc.RequestVerification(ctx, user.ID, dto.PhoneRequest{Phone: "+6281299998888"}); err != nil {
+		t.Fatalf("RequestVerification: %v", err)
+	}
+	_, err := svc.ConfirmVerification(ctx, user.ID, dto.PhoneCodeRequest{Code: sender.code(t)})
+	if !errors.Is(err, ErrPhoneTaken) {
+		t.Fatalf("ConfirmVerification: err = %v, want ErrPhoneTaken", err)
+	}
+}
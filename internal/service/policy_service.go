@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/authorization"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrPolicyExists     = errors.New("policy sudah ada")
+	ErrPolicyNotFound   = errors.New("policy tidak ditemukan")
+	ErrPolicyProtected  = errors.New("policy ini melindungi pengelolaan policy dan tidak bisa dihapus")
+	ErrInvalidCondition = errors.New("kondisi policy tidak valid")
+)
+
+// protectedPolicy adalah rule yang membuka /admin/policies untuk super_admin. Menghapusnya akan
+// mengunci semua orang dari API policy sehingga hanya bisa dipulihkan lewat database.
+var protectedPolicy = dto.PolicyRule{Subject: domain.RoleSuperAdmin, Object: "policy", Action: "manage", Condition: authorization.ConditionAlways}
+
+// PolicyService mengelola policy Casbin saat aplikasi berjalan. Perubahan lewat enforcer langsung
+// tersimpan di database (adapter) dan berlaku di instance ini; instance lain menyusul lewat PolicyWatcher.
+type PolicyService struct {
+	enforcer  *casbin.SyncedEnforcer
+	audit     *AuditService
+	validator *validator.Validate
+	logger    *zap.Logger
+}
+
+// NewPolicyService membuat instance PolicyService baru.
+func NewPolicyService(enforcer *casbin.SyncedEnforcer, audit *AuditService, logger *zap.Logger) *PolicyService {
+	return &PolicyService{enforcer: enforcer, audit: audit, validator: validator.New(), logger: logger}
+}
+
+// List mengembalikan semua rule p dan g yang sedang berlaku.
+func (s *PolicyService) List() (*dto.PolicyListResponse, error) {
+	policies, err := s.enforcer.GetPolicy()
+	if err != nil {
+		return nil, err
+	}
+	groupings, err := s.enforcer.GetGroupingPolicy()
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.PolicyListResponse{
+		Policies:  make([]dto.PolicyRule, 0, len(policies)),
+		Groupings: make([]dto.GroupingRule, 0, len(groupings)),
+	}
+	for _, p := range policies {
+		if len(p) >= 4 {
+			res.Policies = append(res.Policies, dto.PolicyRule{Subject: p[0], Object: p[1], Action: p[2], Condition: p[3]})
+		}
+	}
+	for _, g := range groupings {
+		if len(g) >= 2 {
+			res.Groupings = append(res.Groupings, dto.GroupingRule{Subject: g[0], Role: g[1]})
+		}
+	}
+	return res, nil
+}
+
+// AddPolicy menambah rule p.
+func (s *PolicyService) AddPolicy(ctx context.Context, actorID uuid.UUID, req dto.PolicyRule, ipAddress, userAgent string) error {
+	req, err := s.normalizePolicy(req)
+	if err != nil {
+		return err
+	}
+	added, err := s.enforcer.AddPolicy(req.Subject, req.Object, req.Action, req.Condition)
+	if err != nil {
+		return err
+	}
+	if !added {
+		return ErrPolicyExists
+	}
+	s.record(ctx, actorID, domain.AuditPolicyAdd, "p", []string{req.Subject, req.Object, req.Action, req.Condition}, ipAddress, userAgent)
+	return nil
+}
+
+// RemovePolicy menghapus rule p. Rule yang membuka API policy untuk admin tidak bisa dihapus.
+func (s *PolicyService) RemovePolicy(ctx context.Context, actorID uuid.UUID, req dto.PolicyRule, ipAddress, userAgent string) error {
+	req, err := s.normalizePolicy(req)
+	if err != nil {
+		return err
+	}
+	if req == protectedPolicy {
+		return ErrPolicyProtected
+	}
+	removed, err := s.enforcer.RemovePolicy(req.Subject, req.Object, req.Action, req.Condition)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrPolicyNotFound
+	}
+	s.record(ctx, actorID, domain.AuditPolicyRemove, "p", []string{req.Subject, req.Object, req.Action, req.Condition}, ipAddress, userAgent)
+	return nil
+}
+
+// AddGrouping menambah rule g: Subject mewarisi izin Role.
+func (s *PolicyService) AddGrouping(ctx context.Context, actorID uuid.UUID, req dto.GroupingRule, ipAddress, userAgent string) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+	added, err := s.enforcer.AddGroupingPolicy(req.Subject, req.Role)
+	if err != nil {
+		return err
+	}
+	if !added {
+		return ErrPolicyExists
+	}
+	s.record(ctx, actorID, domain.AuditPolicyAdd, "g", []string{req.Subject, req.Role}, ipAddress, userAgent)
+	return nil
+}
+
+// RemoveGrouping menghapus rule g.
+func (s *PolicyService) RemoveGrouping(ctx context.Context, actorID uuid.UUID, req dto.GroupingRule, ipAddress, userAgent string) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+	removed, err := s.enforcer.RemoveGroupingPolicy(req.Subject, req.Role)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrPolicyNotFound
+	}
+	s.record(ctx, actorID, domain.AuditPolicyRemove, "g", []string{req.Subject, req.Role}, ipAddress, userAgent)
+	return nil
+}
+
+// normalizePolicy memvalidasi rule p dan mengisi Condition kosong dengan ConditionAlways.
+func (s *PolicyService) normalizePolicy(req dto.PolicyRule) (dto.PolicyRule, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return req, err
+	}
+	if req.Condition == "" {
+		req.Condition = authorization.ConditionAlways
+	}
+	if err := authorization.ValidateCondition(req.Condition); err != nil {
+		return req, ErrInvalidCondition
+	}
+	return req, nil
+}
+
+// record mencatat perubahan policy di audit log. Policy sudah berubah sehingga kegagalan
+// pencatatan tidak membatalkan request (AuditService sudah menulisnya ke log aplikasi).
+func (s *PolicyService) record(ctx context.Context, actorID uuid.UUID, action, ptype string, rule []string, ipAddress, userAgent string) {
+	metadata, _ := json.Marshal(map[string]any{"ptype": ptype, "rule": rule})
+	_ = s.audit.Record(ctx, &domain.AuditLog{
+		ActorID:   actorID,
+		Action:    action,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Metadata:  string(metadata),
+	})
+	s.logger.Info("policy Casbin diubah",
+		zap.String("actor_id", actorID.String()),
+		zap.String("action", action),
+		zap.String("ptype", ptype),
+		zap.Strings("rule", rule),
+	)
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/mail"
+	"github.com/itujun/project-ecommerce-go-next/internal/password"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+// PrivacyService menangani permintaan subjek data: ekspor data pribadi dan penghapusan akun.
+// Penghapusan dijadwalkan setelah masa tenggang (ACCOUNT_DELETION_GRACE) dan bisa dibatalkan;
+// setelah lewat, job account_deletion menganonimkan PII secara permanen tetapi pesanan tetap disimpan.
+type PrivacyService struct {
+	userRepo     repository.UserRepository
+	orderRepo    repository.OrderRepository
+	rtRepo       repository.RefreshTokenRepository
+	identityRepo repository.UserIdentityRepository
+	apiKeyRepo   repository.APIKeyRepository
+	passkeyRepo  repository.WebAuthnCredentialRepository
+	revocation   *TokenRevocationService
+	hasher       *password.Hasher
+	mailer       mail.Sender
+	cfg          *config.Config
+	logger       *zap.Logger
+}
+
+// NewPrivacyService membuat instance PrivacyService baru.
+func NewPrivacyService(
+	userRepo repository.UserRepository,
+	orderRepo repository.OrderRepository,
+	rtRepo repository.RefreshTokenRepository,
+	identityRepo repository.UserIdentityRepository,
+	apiKeyRepo repository.APIKeyRepository,
+	passkeyRepo repository.WebAuthnCredentialRepository,
+	revocation *TokenRevocationService,
+	hasher *password.Hasher,
+	mailer mail.Sender,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *PrivacyService {
+	return &PrivacyService{
+		userRepo:     userRepo,
+		orderRepo:    orderRepo,
+		rtRepo:       rtRepo,
+		identityRepo: identityRepo,
+		apiKeyRepo:   apiKeyRepo,
+		passkeyRepo:  passkeyRepo,
+		revocation:   revocation,
+		hasher:       hasher,
+		mailer:       mailer,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+// exportProfile adalah isi profile.json pada arsip ekspor.
+type exportProfile struct {
+	ID                  string  `json:"id"`
+	Name                string  `json:"name"`
+	Email               string  `json:"email"`
+	PendingEmail        *string `json:"pending_email"`
+	Phone               *string `json:"phone"`
+	PhoneVerifiedAt     *string `json:"phone_verified_at"`
+	Role                string  `json:"role"`
+	EmailVerifiedAt     *string `json:"email_verified_at"`
+	DeletionScheduledAt *string `json:"deletion_scheduled_at"`
+	CreatedAt           string  `json:"created_at"`
+	UpdatedAt           string  `json:"updated_at"`
+}
+
+// exportOrder adalah satu pesanan pada orders.json.
+type exportOrder struct {
+	ID        string            `json:"id"`
+	OrderDate string            `json:"order_date"`
+	Total     float64           `json:"total"`
+	Status    string            `json:"status"`
+	Items     []exportOrderItem `json:"items"`
+}
+
+type exportOrderItem struct {
+	ProductID   string  `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Quantity    int     `json:"quantity"`
+	Price       float64 `json:"price"`
+}
+
+// Export menyusun arsip ZIP berisi profil, pesanan beserta item, sesi aktif, identitas sosial, dan API key user.
+func (s *PrivacyService) Export(ctx context.Context, userID uuid.UUID) ([]byte, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	profile := exportProfile{
+		ID:                  user.ID.String(),
+		Name:                user.Name,
+		Email:               user.Email,
+		PendingEmail:        user.PendingEmail,
+		Phone:               user.Phone,
+		PhoneVerifiedAt:     formatTimePtr(user.PhoneVerifiedAt),
+		Role:                user.Role.Name,
+		EmailVerifiedAt:     formatTimePtr(user.EmailVerifiedAt),
+		DeletionScheduledAt: formatTimePtr(user.DeletionScheduledAt),
+		CreatedAt:           user.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:           user.UpdatedAt.Format(time.RFC3339),
+	}
+
+	orders, err := s.orderRepo.ListOrdersByBuyer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	exportedOrders := make([]exportOrder, 0, len(orders))
+	for _, o := range orders {
+		eo := exportOrder{
+			ID:        o.ID.String(),
+			OrderDate: o.OrderDate.Format(time.RFC3339),
+			Total:     o.Total,
+			Status:    o.Status,
+			Items:     make([]exportOrderItem, 0, len(o.Items)),
+		}
+		for _, item := range o.Items {
+			eo.Items = append(eo.Items, exportOrderItem{
+				ProductID:   item.ProductID.String(),
+				ProductName: item.Product.Name,
+				Quantity:    item.Quantity,
+				Price:       item.Price,
+			})
+		}
+		exportedOrders = append(exportedOrders, eo)
+	}
+
+	tokens, err := s.rtRepo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]dto.SessionResponse, 0, len(tokens))
+	for _, rt := range tokens {
+		lastUsed := rt.IssuedAt
+		if rt.LastUsedAt != nil {
+			lastUsed = *rt.LastUsedAt
+		}
+		sessions = append(sessions, dto.SessionResponse{
+			ID:         rt.FamilyID.String(),
+			UserAgent:  rt.UserAgent,
+			IPAddress:  rt.IPAddress,
+			LastUsedAt: lastUsed.Format(time.RFC3339),
+			ExpiresAt:  rt.ExpiresAt.Format(time.RFC3339),
+		})
+	}
+
+	identities, err := s.identityRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.apiKeyRepo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	apiKeys := make([]dto.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		apiKeys = append(apiKeys, apiKeyResponse(&keys[i]))
+	}
+	credentials, err := s.passkeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	passkeys := make([]dto.WebAuthnCredentialResponse, 0, len(credentials))
+	for i := range credentials {
+		passkeys = append(passkeys, webauthnCredentialResponse(&credentials[i]))
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", profile},
+		{"orders.json", exportedOrders},
+		{"sessions.json", sessions},
+		{"identities.json", identities},
+		{"api_keys.json", apiKeys},
+		{"passkeys.json", passkeys},
+	}
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return nil, fmt.Errorf("gagal menulis %s: %w", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	s.logger.Info("data pribadi diekspor", zap.String("user_id", userID.String()))
+	return buf.Bytes(), nil
+}
+
+// RequestDeletion menjadwalkan anonimisasi akun setelah masa tenggang. Password wajib dikonfirmasi
+// untuk akun yang memiliki password. Permintaan ulang mengembalikan jadwal yang sudah ada.
+func (s *PrivacyService) RequestDeletion(ctx context.Context, userID uuid.UUID, req dto.DeleteAccountRequest) (*dto.AccountDeletionResponse, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.Password != "" {
+		ok, _, err := s.hasher.Verify(req.Password, user.Password)
+		if err != nil || !ok {
+			return nil, ErrInvalidCurrentPassword
+		}
+	}
+	if user.DeletionScheduledAt != nil {
+		return &dto.AccountDeletionResponse{ScheduledAt: user.DeletionScheduledAt.Format(time.RFC3339)}, nil
+	}
+
+	at := time.Now().Add(s.cfg.AccountDeletionGrace)
+	if err := s.userRepo.ScheduleDeletion(ctx, userID, &at); err != nil {
+		return nil, err
+	}
+	s.logger.Info("penghapusan akun dijadwalkan", zap.String("user_id", userID.String()), zap.Time("scheduled_at", at))
+
+	if err := s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Permintaan penghapusan akun",
+		Body: fmt.Sprintf("Halo %s,\n\nAkun Anda akan dihapus permanen pada %s.\nLogin dan batalkan penghapusan dari halaman akun sebelum waktu tersebut jika Anda berubah pikiran.",
+			user.Name, at.Format(time.RFC1123)),
+	}); err != nil {
+		s.logger.Warn("gagal mengirim email penghapusan akun", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+	return &dto.AccountDeletionResponse{ScheduledAt: at.Format(time.RFC3339)}, nil
+}
+
+// CancelDeletion membatalkan penghapusan yang masih dalam masa tenggang.
+func (s *PrivacyService) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.DeletionScheduledAt == nil {
+		return nil
+	}
+	if err := s.userRepo.ScheduleDeletion(ctx, userID, nil); err != nil {
+		return err
+	}
+	s.logger.Info("penghapusan akun dibatalkan", zap.String("user_id", userID.String()))
+	return nil
+}
+
+// AnonymizeDue menganonimkan maksimal limit akun yang masa tenggangnya sudah lewat.
+// Mengembalikan jumlah akun yang berhasil dianonimkan.
+func (s *PrivacyService) AnonymizeDue(ctx context.Context, limit int) (int, error) {
+	now := time.Now()
+	users, err := s.userRepo.ListDueForDeletion(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	done := 0
+	for _, user := range users {
+		if err := s.userRepo.AnonymizeUser(ctx, user.ID, now); err != nil {
+			return done, fmt.Errorf("gagal menganonimkan user %s: %w", user.ID, err)
+		}
+		done++
+		if err := s.revocation.RevokeUser(ctx, user.ID); err != nil {
+			s.logger.Warn("gagal mencabut access token user terhapus", zap.String("user_id", user.ID.String()), zap.Error(err))
+		}
+		// Email terakhir ke alamat asli (sudah tidak tersimpan di database)
+		if err := s.mailer.Send(ctx, mail.Message{
+			To:      user.Email,
+			Subject: "Akun Anda telah dihapus",
+			Body:    fmt.Sprintf("Halo %s,\n\nSesuai permintaan Anda, data pribadi akun Anda telah dihapus permanen.", user.Name),
+		}); err != nil {
+			s.logger.Warn("gagal mengirim email konfirmasi penghapusan", zap.String("user_id", user.ID.String()), zap.Error(err))
+		}
+		s.logger.Info("akun dianonimkan", zap.String("user_id", user.ID.String()))
+	}
+	return done, nil
+}
+
+// formatTimePtr memformat waktu opsional sebagai RFC3339.
+func formatTimePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	v := t.Format(time.RFC3339)
+	return &v
+}
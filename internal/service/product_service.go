@@ -7,6 +7,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/gosimple/slug"
+	"github.com/itujun/project-ecommerce-go-next/internal/authorization"
 	"github.com/itujun/project-ecommerce-go-next/internal/domain"
 	"github.com/itujun/project-ecommerce-go-next/internal/dto"
 	"github.com/itujun/project-ecommerce-go-next/internal/repository"
@@ -28,7 +29,8 @@ func NewProductService(productRepo repository.ProductRepository, userRepo reposi
 	}
 }
 
-// CreateProduct membuat produk baru.
+// CreateProduct membuat produk baru milik sellerID.
+// Izin membuat produk diperiksa policy Casbin (product:create) di middleware.
 func (s *ProductService) CreateProduct(ctx context.Context, sellerID uuid.UUID, req dto.CreateProductRequest) (*dto.ProductResponse, error) {
 	// validasi request
 	if err := s.validator.Struct(req); err != nil {
@@ -39,10 +41,6 @@ func (s *ProductService) CreateProduct(ctx context.Context, sellerID uuid.UUID,
 	if err != nil {
 		return nil, fmt.Errorf("seller tidak ditemukan")
 	}
-	// Hanya penjual atau admin yang boleh membuat produk.
-	if user.Role.Name != "seller" && user.Role.Name != "admin" {
-		return nil, fmt.Errorf("anda tidak memiliki izin untuk membuat produk")
-	}
 	// Generate slug unik
 	prodSlug := slug.Make(req.Name)
 	// Pastikan slug belum ada; jika ada, tambahkan suffix
@@ -120,8 +118,18 @@ func (s *ProductService) ListProducts(ctx context.Context) ([]dto.ProductRespons
 	return result, nil
 }
 
+// ProductOwner mengembalikan ID seller pemilik produk; dipakai middleware.AuthorizeResource.
+func (s *ProductService) ProductOwner(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	product, err := s.productRepo.GetProductByID(ctx, id)
+	if err != nil {
+		return uuid.Nil, authorization.ErrResourceNotFound
+	}
+	return product.SellerID, nil
+}
+
 // UpdateProduct memperbarui data produk.
-func (s *ProductService) UpdateProduct(ctx context.Context, sellerID uuid.UUID, id uuid.UUID, req dto.UpdateProductRequest) (*dto.ProductResponse, error) {
+// Kepemilikan (seller hanya produknya sendiri, admin semua) diperiksa policy Casbin sebelum service dipanggil.
+func (s *ProductService) UpdateProduct(ctx context.Context, id uuid.UUID, req dto.UpdateProductRequest) (*dto.ProductResponse, error) {
 	if err := s.validator.Struct(req); err != nil {
 		return nil, err
 	}
@@ -129,11 +137,6 @@ func (s *ProductService) UpdateProduct(ctx context.Context, sellerID uuid.UUID,
 	if err != nil {
 		return nil, fmt.Errorf("produk tidak ditemukan")
 	}
-	// Pastikan hanya seller yang membuat produk ini atau admin yang bisa edit.
-	seller, _ := s.userRepo.GetUserByID(ctx, sellerID)
-	if seller.Role.Name != "admin" && product.SellerID != seller.ID {
-		return nil, fmt.Errorf("anda tidak memiliki izin untuk mengubah produk ini")
-	}
 	product.Name = req.Name
 	product.Slug = slug.Make(req.Name)
 	product.Description = req.Description
@@ -156,14 +159,10 @@ func (s *ProductService) UpdateProduct(ctx context.Context, sellerID uuid.UUID,
 }
 
 // DeleteProduct melakukan soft delete produk.
-func (s *ProductService) DeleteProduct(ctx context.Context, sellerID uuid.UUID, id uuid.UUID) error {
-	prod, err := s.productRepo.GetProductByID(ctx, id)
-	if err != nil {
+// Kepemilikan diperiksa policy Casbin sebelum service dipanggil.
+func (s *ProductService) DeleteProduct(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.productRepo.GetProductByID(ctx, id); err != nil {
 		return fmt.Errorf("produk tidak ditemukan")
-	} 
-	seller, _ := s.userRepo.GetUserByID(ctx, sellerID)
-	if seller.Role.Name != "admin" && prod.SellerID != seller.ID {
-		return fmt.Errorf("anda tidak memiliki izin untuk menghapus produk ini")
 	}
 	return s.productRepo.DeleteProduct(ctx, id)
 }
\ No newline at end of file
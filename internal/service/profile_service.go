@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/mail"
+	"github.com/itujun/project-ecommerce-go-next/internal/password"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrEmailTaken dikembalikan saat email sudah dipakai akun lain.
+	ErrEmailTaken = errors.New("email sudah terdaftar")
+	// ErrEmailChangeLimit dikembalikan saat permintaan ganti email melewati batas per jam.
+	ErrEmailChangeLimit = errors.New("terlalu banyak permintaan ganti email, coba lagi nanti")
+	// ErrInvalidEmailChangeToken dikembalikan untuk token ganti email yang tidak valid, kedaluwarsa, atau sudah dipakai.
+	ErrInvalidEmailChangeToken = errors.New("token konfirmasi email tidak valid atau kedaluwarsa")
+	// ErrInvalidCurrentPassword dikembalikan saat password saat ini salah.
+	ErrInvalidCurrentPassword = errors.New("password saat ini salah")
+	// ErrPasswordNotSet dikembalikan untuk akun tanpa password (mis. dibuat lewat login sosial).
+	ErrPasswordNotSet = errors.New("akun belum memiliki password, gunakan lupa password untuk membuatnya")
+)
+
+// ProfileService menangani perubahan profil oleh user sendiri: nama, email (dengan konfirmasi), dan password.
+type ProfileService struct {
+	userRepo   repository.UserRepository
+	tokenRepo  repository.UserTokenRepository
+	rtRepo     repository.RefreshTokenRepository
+	revocation *TokenRevocationService
+	hasher     *password.Hasher
+	policy     *password.Policy
+	mailer     mail.Sender
+	validator  *validator.Validate
+	cfg        *config.Config
+	logger     *zap.Logger
+}
+
+// NewProfileService membuat instance ProfileService baru.
+func NewProfileService(
+	userRepo repository.UserRepository,
+	tokenRepo repository.UserTokenRepository,
+	rtRepo repository.RefreshTokenRepository,
+	revocation *TokenRevocationService,
+	hasher *password.Hasher,
+	policy *password.Policy,
+	mailer mail.Sender,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *ProfileService {
+	return &ProfileService{
+		userRepo:   userRepo,
+		tokenRepo:  tokenRepo,
+		rtRepo:     rtRepo,
+		revocation: revocation,
+		hasher:     hasher,
+		policy:     policy,
+		mailer:     mailer,
+		validator:  validator.New(),
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+// UpdateProfile mengganti nama secara langsung. Email baru tidak langsung dipakai: disimpan sebagai
+// pending_email dan link konfirmasi dikirim ke alamat baru; email lama tetap berlaku sampai dikonfirmasi.
+func (s *ProfileService) UpdateProfile(ctx context.Context, userID uuid.UUID, req dto.UpdateProfileRequest) (*dto.ProfileResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name != user.Name {
+			if err := s.userRepo.UpdateName(ctx, userID, name); err != nil {
+				return nil, err
+			}
+			user.Name = name
+		}
+	}
+
+	if req.Email != nil {
+		email := strings.TrimSpace(*req.Email)
+		if strings.EqualFold(email, user.Email) {
+			// Kembali ke email saat ini: batalkan perubahan yang tertunda
+			if user.PendingEmail != nil {
+				if err := s.cancelEmailChange(ctx, userID); err != nil {
+					return nil, err
+				}
+				user.PendingEmail = nil
+			}
+		} else {
+			if err := s.requestEmailChange(ctx, user, email); err != nil {
+				return nil, err
+			}
+			user.PendingEmail = &email
+		}
+	}
+
+	return &dto.ProfileResponse{User: userResponseDTO(user), PendingEmail: user.PendingEmail}, nil
+}
+
+// ConfirmEmailChange memvalidasi token dari link konfirmasi lalu mengganti email user ke pending_email.
+func (s *ProfileService) ConfirmEmailChange(ctx context.Context, req dto.ConfirmEmailChangeRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+	record, err := s.tokenRepo.FindByHash(ctx, domain.TokenPurposeEmailChange, hashOpaqueToken(req.Token))
+	if err != nil {
+		return ErrInvalidEmailChangeToken
+	}
+	if record.UsedAt != nil || time.Now().After(record.ExpiresAt) {
+		return ErrInvalidEmailChangeToken
+	}
+	user, err := s.userRepo.GetUserByID(ctx, record.UserID)
+	if err != nil || user.PendingEmail == nil {
+		return ErrInvalidEmailChangeToken
+	}
+	// Email bisa saja sudah diambil akun lain sejak link dikirim
+	if existing, _ := s.userRepo.GetUserByEmail(ctx, *user.PendingEmail); existing != nil && existing.ID != user.ID {
+		return ErrEmailTaken
+	}
+	used, err := s.tokenRepo.MarkUsed(ctx, record.ID)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidEmailChangeToken
+	}
+	oldEmail, newEmail := user.Email, *user.PendingEmail
+	if err := s.userRepo.ChangeEmail(ctx, user.ID, newEmail, time.Now()); err != nil {
+		return err
+	}
+	s.logger.Info("email user diganti", zap.String("user_id", user.ID.String()))
+
+	// Beri tahu alamat lama agar pemilik akun sadar jika bukan dia yang mengganti
+	if err := s.mailer.Send(ctx, mail.Message{
+		To:      oldEmail,
+		Subject: "Email akun Anda telah diganti",
+		Body: fmt.Sprintf("Halo %s,\n\nEmail akun Anda telah diganti menjadi %s.\nJika ini bukan Anda, segera hubungi dukungan.",
+			user.Name, newEmail),
+	}); err != nil {
+		s.logger.Warn("gagal mengirim notifikasi ganti email", zap.String("user_id", user.ID.String()), zap.Error(err))
+	}
+	return nil
+}
+
+// ChangePassword mengganti password setelah memeriksa password saat ini. Semua sesi lain (RT) dicabut,
+// sesi saat ini (currentSessionID) dipertahankan. Semua AT ikut dicabut sehingga pemanggil
+// perlu menerbitkan ulang AT untuk sesi saat ini.
+func (s *ProfileService) ChangePassword(ctx context.Context, userID, currentSessionID uuid.UUID, req dto.ChangePasswordRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.Password == "" {
+		return ErrPasswordNotSet
+	}
+	ok, _, err := s.hasher.Verify(req.CurrentPassword, user.Password)
+	if err != nil || !ok {
+		return ErrInvalidCurrentPassword
+	}
+	if err := s.policy.Check("new_password", req.NewPassword, user.Name, user.Email); err != nil {
+		return err
+	}
+
+	hashed, err := s.hasher.Hash(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("gagal membuat hash password: %w", err)
+	}
+	if err := s.userRepo.UpdatePassword(ctx, userID, hashed); err != nil {
+		return err
+	}
+	// Token reset password yang masih aktif tidak boleh dipakai lagi
+	if err := s.tokenRepo.InvalidateAll(ctx, userID, domain.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+	if err := s.rtRepo.RevokeAllByUserExceptFamily(ctx, userID, currentSessionID); err != nil {
+		return err
+	}
+	if err := s.revocation.RevokeUser(ctx, userID); err != nil {
+		return err
+	}
+	s.logger.Info("password diganti oleh user, sesi lain dicabut", zap.String("user_id", userID.String()))
+
+	if err := s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Password akun Anda telah diganti",
+		Body:    fmt.Sprintf("Halo %s,\n\nPassword akun Anda baru saja diganti dan perangkat lain telah dikeluarkan.\nJika ini bukan Anda, segera reset password Anda.", user.Name),
+	}); err != nil {
+		s.logger.Warn("gagal mengirim notifikasi ganti password", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+	return nil
+}
+
+// RecordAccessToken mencatat jti AT yang diterbitkan ulang setelah ChangePassword pada sesi saat ini.
+func (s *ProfileService) RecordAccessToken(ctx context.Context, sessionID uuid.UUID, jti string) error {
+	return s.rtRepo.SetAccessTokenID(ctx, sessionID, jti)
+}
+
+// requestEmailChange menyimpan pending_email lalu mengirim link konfirmasi ke alamat baru.
+func (s *ProfileService) requestEmailChange(ctx context.Context, user *domain.User, email string) error {
+	if existing, _ := s.userRepo.GetUserByEmail(ctx, email); existing != nil {
+		return ErrEmailTaken
+	}
+	count, err := s.tokenRepo.CountSince(ctx, user.ID, domain.TokenPurposeEmailChange, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if count >= int64(s.cfg.EmailVerificationPerHour) {
+		return ErrEmailChangeLimit
+	}
+
+	plain, hash, err := newOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("gagal membuat token konfirmasi email: %w", err)
+	}
+	// Hanya link terakhir yang berlaku
+	if err := s.tokenRepo.InvalidateAll(ctx, user.ID, domain.TokenPurposeEmailChange); err != nil {
+		return err
+	}
+	if err := s.tokenRepo.Create(ctx, &domain.UserToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Purpose:   domain.TokenPurposeEmailChange,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.cfg.EmailVerificationTTL),
+	}); err != nil {
+		return err
+	}
+	if err := s.userRepo.SetPendingEmail(ctx, user.ID, &email); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/confirm-email-change?token=%s", s.cfg.FrontendURL, url.QueryEscape(plain))
+	return s.mailer.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "Konfirmasi email baru Anda",
+		Body: fmt.Sprintf("Halo %s,\n\nKlik link berikut untuk memakai alamat ini sebagai email akun Anda:\n%s\n\nLink berlaku selama %s.",
+			user.Name, link, s.cfg.EmailVerificationTTL),
+	})
+}
+
+// cancelEmailChange membatalkan link konfirmasi dan mengosongkan pending_email.
+func (s *ProfileService) cancelEmailChange(ctx context.Context, userID uuid.UUID) error {
+	if err := s.tokenRepo.InvalidateAll(ctx, userID, domain.TokenPurposeEmailChange); err != nil {
+		return err
+	}
+	return s.userRepo.SetPendingEmail(ctx, userID, nil)
+}
+
+func userResponseDTO(user *domain.User) dto.UserResponse {
+	return dto.UserResponse{
+		ID:    user.ID.String(),
+		Name:  user.Name,
+		Email: user.Email,
+		Role:  user.Role.Name,
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrRoleExists      = errors.New("role sudah ada")
+	ErrInvalidRoleName = errors.New("nama role hanya boleh huruf kecil, angka, dan underscore, diawali huruf")
+)
+
+// roleNamePattern membatasi nama role agar aman dipakai sebagai subject di policy Casbin.
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// RoleService mengelola role. Role baru belum punya izin apa pun; izinnya diberikan lewat
+// /admin/policies (rule p) atau dengan mewarisi role lain (rule g).
+type RoleService struct {
+	roleRepo  repository.RoleRepository
+	audit     *AuditService
+	validator *validator.Validate
+	logger    *zap.Logger
+}
+
+// NewRoleService membuat instance RoleService baru.
+func NewRoleService(roleRepo repository.RoleRepository, audit *AuditService, logger *zap.Logger) *RoleService {
+	return &RoleService{roleRepo: roleRepo, audit: audit, validator: validator.New(), logger: logger}
+}
+
+// List mengembalikan semua role.
+func (s *RoleService) List(ctx context.Context) ([]dto.RoleResponse, error) {
+	roles, err := s.roleRepo.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.RoleResponse, 0, len(roles))
+	for i := range roles {
+		res = append(res, roleResponse(&roles[i]))
+	}
+	return res, nil
+}
+
+// Create membuat role kustom lalu mencatatnya di audit log.
+func (s *RoleService) Create(ctx context.Context, actorID uuid.UUID, req dto.CreateRoleRequest, ipAddress, userAgent string) (*dto.RoleResponse, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	if !roleNamePattern.MatchString(req.Name) {
+		return nil, ErrInvalidRoleName
+	}
+	if existing, err := s.roleRepo.GetRoleByName(ctx, req.Name); err == nil && existing != nil {
+		return nil, ErrRoleExists
+	}
+
+	role := &domain.Role{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		Description: strings.TrimSpace(req.Description),
+	}
+	if err := s.roleRepo.CreateRole(ctx, role); err != nil {
+		return nil, err
+	}
+
+	metadata, _ := json.Marshal(map[string]string{"role_id": role.ID.String(), "name": role.Name})
+	_ = s.audit.Record(ctx, &domain.AuditLog{
+		ActorID:   actorID,
+		Action:    domain.AuditRoleCreate,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Metadata:  string(metadata),
+	})
+	s.logger.Info("role dibuat",
+		zap.String("actor_id", actorID.String()),
+		zap.String("role_id", role.ID.String()),
+		zap.String("name", role.Name),
+	)
+	res := roleResponse(role)
+	return &res, nil
+}
+
+func roleResponse(role *domain.Role) dto.RoleResponse {
+	return dto.RoleResponse{
+		ID:          role.ID.String(),
+		Name:        role.Name,
+		Description: role.Description,
+		CreatedAt:   role.CreatedAt.Format(time.RFC3339),
+	}
+}
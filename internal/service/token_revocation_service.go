@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+// TokenRevocationService mencabut AT sebelum kedaluwarsa dan diperiksa oleh Authenticator di setiap request.
+// Ada dua cara pencabutan:
+// - per jti: satu token (logout, pencabutan sesi saat ini);
+// - per user: semua AT yang diterbitkan sebelum waktu tertentu (ganti role, suspend, reset password).
+type TokenRevocationService struct {
+	repo   repository.TokenRevocationRepository
+	cfg    *config.Config
+	logger *zap.Logger
+}
+
+// NewTokenRevocationService membuat instance TokenRevocationService baru.
+func NewTokenRevocationService(repo repository.TokenRevocationRepository, cfg *config.Config, logger *zap.Logger) *TokenRevocationService {
+	return &TokenRevocationService{repo: repo, cfg: cfg, logger: logger}
+}
+
+// RevokeToken mencabut satu AT berdasarkan klaimnya. Entri kedaluwarsa bersamaan dengan token.
+func (s *TokenRevocationService) RevokeToken(ctx context.Context, claims *CustomClaims) error {
+	if claims.ExpiresAt == nil {
+		return nil
+	}
+	return s.RevokeTokenID(ctx, claims.ID, claims.UserID, claims.ExpiresAt.Time)
+}
+
+// RevokeTokenID mencabut satu AT berdasarkan jti (mis. dari Principal).
+func (s *TokenRevocationService) RevokeTokenID(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	return s.repo.RevokeJTI(ctx, jti, userID, expiresAt)
+}
+
+// RevokeSessionTokens mencabut AT terakhir dari sesi yang dicabut (jti dicatat pada RT sesi).
+// Waktu kedaluwarsa AT tidak diketahui, jadi entri disimpan selama umur AT sejak sekarang.
+func (s *TokenRevocationService) RevokeSessionTokens(ctx context.Context, userID uuid.UUID, jtis []string) error {
+	expiresAt := time.Now().Add(s.cfg.AccessTTL)
+	for _, jti := range jtis {
+		if err := s.RevokeTokenID(ctx, jti, userID, expiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeUser mencabut semua AT user yang sudah diterbitkan, termasuk token impersonasi atas nama user.
+// Cutoff disimpan berpresisi mikrodetik dan dibandingkan dengan klaim iat_us AT (lihat issuedBeforeCutoff),
+// sehingga AT yang diterbitkan di detik yang sama sebelum pencabutan ikut dicabut, sedangkan AT yang
+// diterbitkan ulang sesudahnya tetap valid. Cutoff disimpan selama umur AT terpanjang (biasa atau impersonasi).
+func (s *TokenRevocationService) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	before := time.Now().Truncate(time.Microsecond)
+	ttl := max(s.cfg.AccessTTL, s.cfg.ImpersonationTTL)
+	if err := s.repo.RevokeUserBefore(ctx, userID, before, before.Add(ttl+time.Second)); err != nil {
+		return err
+	}
+	s.logger.Info("semua access token user dicabut", zap.String("user_id", userID.String()))
+	return nil
+}
+
+// IsTokenIDRevoked mengembalikan true jika jti ada di denylist. Dipakai juga untuk token sekali pakai
+// selain AT (mis. mfa_token yang sudah ditukar dengan sesi).
+func (s *TokenRevocationService) IsTokenIDRevoked(ctx context.Context, jti string) (bool, error) {
+	return s.repo.IsJTIRevoked(ctx, jti)
+}
+
+// IsRevoked mengembalikan true jika AT dicabut lewat jti atau cutoff user.
+func (s *TokenRevocationService) IsRevoked(ctx context.Context, claims *CustomClaims) (bool, error) {
+	if claims.ID != "" {
+		revoked, err := s.repo.IsJTIRevoked(ctx, claims.ID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	revoked, err := s.issuedBeforeCutoff(ctx, claims.UserID, claims)
+	if err != nil || revoked {
+		return revoked, err
+	}
+	// Token impersonasi juga gugur jika semua AT admin pelakunya dicabut (mis. admin disuspend)
+	if claims.Actor != nil {
+		return s.issuedBeforeCutoff(ctx, claims.Actor.UserID, claims)
+	}
+	return false, nil
+}
+
+// issuedBeforeCutoff mengecek apakah token diterbitkan sebelum (atau tepat pada) cutoff pencabutan milik userID.
+// Token tanpa iat_us hanya punya iat berpresisi detik, jadi dibandingkan dengan cutoff yang dibulatkan ke
+// bawah ke detik: token dari detik yang sama dengan pencabutan ikut dianggap dicabut.
+func (s *TokenRevocationService) issuedBeforeCutoff(ctx context.Context, userID uuid.UUID, claims *CustomClaims) (bool, error) {
+	before, ok, err := s.repo.UserRevokedBefore(ctx, userID)
+	if err != nil || !ok {
+		return false, err
+	}
+	if claims.IssuedAtMicro != 0 {
+		return !time.UnixMicro(claims.IssuedAtMicro).After(before.Truncate(time.Microsecond)), nil
+	}
+	return claims.IssuedAt == nil || !claims.IssuedAt.Time.After(before.Truncate(time.Second)), nil
+}
+
+// Run menghapus entri kedaluwarsa secara berkala sampai ctx dibatalkan.
+func (s *TokenRevocationService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := s.repo.DeleteExpired(ctx, now)
+			if err != nil {
+				s.logger.Error("gagal membersihkan denylist access token", zap.Error(err))
+				continue
+			}
+			if n > 0 {
+				s.logger.Info("denylist access token dibersihkan", zap.Int64("deleted", n))
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository/memory"
+	"go.uber.org/zap"
+)
+
+func TestRevokeUserCutoff(t *testing.T) {
+	cfg := &config.Config{AccessTTL: 15 * time.Minute, ImpersonationTTL: 15 * time.Minute}
+	repo := memory.NewTokenRevocationRepository()
+	svc := NewTokenRevocationService(repo, cfg, zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	if err := svc.RevokeUser(ctx, userID); err != nil {
+		t.Fatalf("RevokeUser: %v", err)
+	}
+	cutoff, ok, err := repo.UserRevokedBefore(ctx, userID)
+	if err != nil || !ok {
+		t.Fatalf("UserRevokedBefore: ok %v, err %v", ok, err)
+	}
+	second := cutoff.Truncate(time.Second)
+
+	tests := []struct {
+		name   string
+		claims CustomClaims
+		want   bool
+	}{
+		{name: "iat_us sebelum cutoff", claims: CustomClaims{IssuedAtMicro: cutoff.Add(-time.Microsecond).UnixMicro()}, want: true},
+		{name: "iat_us tepat cutoff", claims: CustomClaims{IssuedAtMicro: cutoff.UnixMicro()}, want: true},
+		{name: "iat_us sesudah cutoff di detik yang sama", claims: CustomClaims{IssuedAtMicro: cutoff.Add(time.Microsecond).UnixMicro()}, want: false},
+		{name: "iat detik yang sama tanpa iat_us", claims: CustomClaims{RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(second)}}, want: true},
+		{name: "iat detik berikutnya tanpa iat_us", claims: CustomClaims{RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(second.Add(time.Second))}}, want: false},
+		{name: "tanpa iat", claims: CustomClaims{}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := tt.claims
+			claims.UserID = userID
+			got, err := svc.IsRevoked(ctx, &claims)
+			if err != nil {
+				t.Fatalf("IsRevoked: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("IsRevoked = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessTokenCarriesMicrosecondIssuedAt(t *testing.T) {
+	cfg := &config.Config{
+		JWTKeyDir:      t.TempDir(),
+		JWTSigningAlg:  AlgEdDSA,
+		JWTKeyRotation: time.Hour,
+		AccessTTL:      15 * time.Minute,
+	}
+	keys, err := NewKeyManager(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService := NewJWTService(cfg, keys)
+	issued, token, err := jwtService.GenerateAccessToken(uuid.New(), uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := jwtService.VerifyAccessToken(token)
+	if err != nil {
+		t.Fatalf("VerifyAccessToken: %v", err)
+	}
+	if parsed.IssuedAtMicro == 0 || parsed.IssuedAtMicro != issued.IssuedAtMicro {
+		t.Fatalf("iat_us = %d, want %d", parsed.IssuedAtMicro, issued.IssuedAtMicro)
+	}
+	if parsed.IssuedAt.Time.Unix() != time.UnixMicro(parsed.IssuedAtMicro).Unix() {
+		t.Fatalf("iat %v tidak sesuai iat_us %d", parsed.IssuedAt.Time, parsed.IssuedAtMicro)
+	}
+}
+
+// cutoffExpiry mencatat masa simpan cutoff terakhir yang diminta RevokeUser.
+type cutoffExpiry struct {
+	repository.TokenRevocationRepository
+	expiresAt time.Time
+}
+
+func (c *cutoffExpiry) RevokeUserBefore(ctx context.Context, userID uuid.UUID, before, expiresAt time.Time) error {
+	c.expiresAt = expiresAt
+	return c.TokenRevocationRepository.RevokeUserBefore(ctx, userID, before, expiresAt)
+}
+
+func TestRevokeUserKeepsCutoffForLongestAccessToken(t *testing.T) {
+	tests := []struct {
+		name             string
+		accessTTL        time.Duration
+		impersonationTTL time.Duration
+	}{
+		{name: "AT biasa lebih lama", accessTTL: 30 * time.Minute, impersonationTTL: 10 * time.Minute},
+		{name: "AT impersonasi lebih lama", accessTTL: 10 * time.Minute, impersonationTTL: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{AccessTTL: tt.accessTTL, ImpersonationTTL: tt.impersonationTTL}
+			repo := &cutoffExpiry{TokenRevocationRepository: memory.NewTokenRevocationRepository()}
+			svc := NewTokenRevocationService(repo, cfg, zap.NewNop())
+
+			start := time.Now()
+			if err := svc.RevokeUser(context.Background(), uuid.New()); err != nil {
+				t.Fatalf("RevokeUser: %v", err)
+			}
+			if want := start.Add(max(tt.accessTTL, tt.impersonationTTL)); repo.expiresAt.Before(want) {
+				t.Fatalf("cutoff kedaluwarsa %v, want setidaknya %v", repo.expiresAt, want)
+			}
+		})
+	}
+}
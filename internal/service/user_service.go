@@ -4,15 +4,32 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
 	"github.com/itujun/project-ecommerce-go-next/internal/domain"
 	"github.com/itujun/project-ecommerce-go-next/internal/dto"
 	"github.com/itujun/project-ecommerce-go-next/internal/repository"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/itujun/project-ecommerce-go-next/internal/password"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrEmailAlreadyExists dikembalikan saat mendaftar dengan email yang sudah terdaftar.
+	ErrEmailAlreadyExists = errors.New("email sudah terdaftar")
+	// ErrRefreshTokenReused dikembalikan saat RT yang sudah dicabut dipakai ulang; seluruh family ikut dicabut.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+	// ErrRefreshTokenConcurrent dikembalikan saat RT sudah dirotasi oleh request lain dalam grace window.
+	ErrRefreshTokenConcurrent = errors.New("refresh token already rotated")
+	// ErrSessionNotFound dikembalikan saat sesi tidak ada, sudah berakhir, atau bukan milik user.
+	ErrSessionNotFound = errors.New("sesi tidak ditemukan")
+	// ErrUserNotFound dikembalikan saat user yang dituju tidak ada.
+	ErrUserNotFound = errors.New("user tidak ditemukan")
 )
 
 // UserService menyediakan logika bisnis terkait pengguna.
@@ -20,11 +37,19 @@ type UserService struct {
 	userRepo	repository.UserRepository
 	roleRepo 	repository.RoleRepository
 	validator 	*validator.Validate
-	jwtSecret	string
+	cfg			*config.Config
+	logger		*zap.Logger
 	
 	// dependency untuk JWT & Refresh Token
     jwtSvc *JWTService
     rtRepo repository.RefreshTokenRepository
+
+	loginGuard *LoginGuard
+	hasher     *password.Hasher
+	policy     *password.Policy
+	// dummyHash dipakai saat email tidak terdaftar agar waktu respons login
+	// setara dengan pemeriksaan hash akun yang ada (mencegah enumerasi lewat timing).
+	dummyHash string
 }
 
 // ====== Perbarui constructor agar menerima semua dependency ======
@@ -34,15 +59,25 @@ func NewUserService(
     roleRepo repository.RoleRepository,
     rtRepo repository.RefreshTokenRepository,
     jwtSvc *JWTService,
-    jwtSecret string,
+    loginGuard *LoginGuard,
+    hasher *password.Hasher,
+    policy *password.Policy,
+    cfg *config.Config,
+    logger *zap.Logger,
 ) *UserService {
+    dummyHash, _ := hasher.Hash("dummy-password")
     return &UserService{
         userRepo:  userRepo,
         roleRepo:  roleRepo,
         validator: validator.New(),
-        jwtSecret: jwtSecret,
+        cfg:       cfg,
+        logger:    logger,
         jwtSvc:    jwtSvc,
         rtRepo:    rtRepo,
+        loginGuard: loginGuard,
+        hasher:     hasher,
+        policy:     policy,
+        dummyHash:  dummyHash,
     }
 }
 
@@ -66,12 +101,16 @@ func (s *UserService) RegisterUser(ctx context.Context, req dto.RegisterUserRequ
 	if err := s.validator.Struct(req); err != nil {
 		return nil, err
 	}
+	// Kebijakan password (panjang, jenis karakter, daftar umum/bocor, tidak memuat nama/email)
+	if err := s.policy.Check("password", req.Password, req.Name, req.Email); err != nil {
+		return nil, err
+	}
 	// Pastikan email belum terdaftar
 	if existing, _ := s.userRepo.GetUserByEmail(ctx, req.Email); existing != nil {
-		return nil, fmt.Errorf("email %s sudah terdaftar", req.Email)
+		return nil, ErrEmailAlreadyExists
 	}
 	// Hash password
-	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashed, err := s.hasher.Hash(req.Password)
 	if err != nil {
 		return nil, fmt.Errorf("gagal membuat hash password: %w", err)
 	}
@@ -84,10 +123,14 @@ func (s *UserService) RegisterUser(ctx context.Context, req dto.RegisterUserRequ
 		ID:			uuid.New(),
 		Name:		req.Name,
 		Email:		req.Email,
-		Password:	string(hashed),
+		Password:	hashed,
 		RoleID:		role.ID,
 	}
 	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		// Pendaftaran bersamaan dengan email yang sama lolos pengecekan di atas; unique index yang menolak
+		if errors.Is(err, repository.ErrDuplicateEmail) {
+			return nil, ErrEmailAlreadyExists
+		}
 		return nil, err
 	}
 	
@@ -99,29 +142,41 @@ func (s *UserService) RegisterUser(ctx context.Context, req dto.RegisterUserRequ
 	}, nil
 }
 
-// LoginUser memverifikasi kredensial dan mengembalikan token JWT.
-func (s *UserService) LoginUser(ctx context.Context, req dto.LoginRequest) (*dto.LoginResponse, error) {
+// LoginUser memverifikasi kredensial dan mengembalikan data pengguna.
+// Penerbitan token dilakukan oleh AuthHandler lewat JWTService (AT & RT di cookie).
+// Percobaan dibatasi LoginGuard per akun dan per IP (clientIP); kegagalan selalu mengembalikan ErrInvalidCredentials.
+func (s *UserService) LoginUser(ctx context.Context, req dto.LoginRequest, clientIP string) (*dto.LoginResponse, error) {
 	if err := s.validator.Struct(req); err != nil {
 		return nil, err
 	}
+	// Tolak sebelum hashing jika akun/IP sedang dikunci atau masih dalam jeda; percobaan akun langsung dipesan
+	if err := s.loginGuard.Reserve(ctx, req.Email, clientIP); err != nil {
+		return nil, err
+	}
 	user, err := s.userRepo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, fmt.Errorf("email tidak terdaftar")
+		_, _, _ = s.hasher.Verify(req.Password, s.dummyHash)
+		s.loginGuard.Fail(ctx, req.Email, clientIP)
+		return nil, ErrInvalidCredentials
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, fmt.Errorf("email atau password salah")
+	ok, needsRehash, err := s.hasher.Verify(req.Password, user.Password)
+	if err != nil || !ok {
+		s.loginGuard.Fail(ctx, req.Email, clientIP)
+		return nil, ErrInvalidCredentials
 	}
-	// Buat klaim JWT
-	claims := jwt.MapClaims{
-		"user_id": 	user.ID.String(),
-		"email":	user.Email,
-		"role":		user.Role.Name,
-		"exp":		time.Now().Add(24 * time.Hour).Unix(), // token berlaku 24 jam
+	s.loginGuard.Succeed(ctx, req.Email)
+	// Upgrade hash lama (bcrypt atau parameter argon2id lama) selagi password plaintext tersedia
+	if needsRehash {
+		s.rehashPassword(ctx, user.ID, req.Password)
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := token.SignedString([]byte(s.jwtSecret))
-	if err != nil {
-		return nil, fmt.Errorf("gagal menandatangani token: %w", err)
+
+	// Akun ditangguhkan admin; diperiksa setelah password agar status akun tidak bocor
+	if user.Suspended() {
+		return nil, ErrAccountSuspended
+	}
+	// Blokir login sampai email terverifikasi jika EMAIL_VERIFICATION_MODE=login
+	if s.cfg.EmailVerificationMode == config.EmailVerificationLogin && user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
 	}
 
 	return &dto.LoginResponse{
@@ -131,34 +186,83 @@ func (s *UserService) LoginUser(ctx context.Context, req dto.LoginRequest) (*dto
 			Email: user.Email,
 			Role:  user.Role.Name,
 		},
-		Token: signed,
 	},nil
 }
 
-func (s *UserService) SaveRefreshToken(ctx context.Context, userID uuid.UUID, refreshToken string, issuedAt, expiresAt time.Time, jti string) error {
+// rehashPassword menyimpan hash baru dengan algoritma & parameter saat ini.
+// Kegagalan hanya dicatat; login tetap berhasil dan hash di-upgrade pada login berikutnya.
+func (s *UserService) rehashPassword(ctx context.Context, userID uuid.UUID, plain string) {
+	hashed, err := s.hasher.Hash(plain)
+	if err == nil {
+		err = s.userRepo.UpdatePassword(ctx, userID, hashed)
+	}
+	if err != nil {
+		s.logger.Warn("gagal memperbarui hash password", zap.String("user_id", userID.String()), zap.Error(err))
+		return
+	}
+	s.logger.Info("hash password diperbarui ke parameter terbaru", zap.String("user_id", userID.String()))
+}
+
+// UnlockUser membuka kunci login akun yang terkunci karena terlalu banyak percobaan gagal.
+func (s *UserService) UnlockUser(ctx context.Context, id uuid.UUID) error {
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if err := s.loginGuard.Unlock(ctx, user.Email); err != nil {
+		return err
+	}
+	s.logger.Info("akun dibuka kuncinya oleh admin", zap.String("user_id", id.String()))
+	return nil
+}
+
+// SessionMeta berisi info perangkat yang dicatat pada setiap RT (ditampilkan di daftar sesi).
+type SessionMeta struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SaveRefreshToken menyimpan RT hasil login sebagai anggota pertama dari family (rantai rotasi) baru.
+// ID family sekaligus menjadi ID sesi perangkat.
+func (s *UserService) SaveRefreshToken(ctx context.Context, userID uuid.UUID, refreshToken string, issuedAt, expiresAt time.Time, jti string, meta SessionMeta) error {
+	id := uuid.MustParse(jti)
+	// RT pertama menjadi akar family: family_id = id dirinya sendiri
+	return s.saveRefreshToken(ctx, userID, refreshToken, issuedAt, expiresAt, id, id, nil, meta)
+}
+
+// saveRefreshToken menyimpan hash RT beserta family, parent, dan info perangkat.
+func (s *UserService) saveRefreshToken(ctx context.Context, userID uuid.UUID, refreshToken string, issuedAt, expiresAt time.Time, id, familyID uuid.UUID, parentID *uuid.UUID, meta SessionMeta) error {
 	// Simpan hash dari RT
 	hash := s.jwtSvc.HashRefreshToken(refreshToken)
+	lastUsed := issuedAt
 	rt := &domain.RefreshToken{
-		ID:        uuid.MustParse(jti),
-		UserID:    userID,
-		TokenHash: hash,
-		IssuedAt:  issuedAt,
-		ExpiresAt: expiresAt,
-		Revoked:   false,
+		ID:         id,
+		UserID:     userID,
+		FamilyID:   familyID,
+		ParentID:   parentID,
+		TokenHash:  hash,
+		IssuedAt:   issuedAt,
+		ExpiresAt:  expiresAt,
+		Revoked:    false,
+		UserAgent:  truncate(meta.UserAgent, 255),
+		IPAddress:  truncate(meta.IPAddress, 45),
+		LastUsedAt: &lastUsed,
 	}
 	return s.rtRepo.Save(ctx, rt)
 }
 
 // VerifyRefreshTokenDB: validasi RT berdasar klaim JWT + cek DB (revoked/expired)
-func (s *UserService) VerifyRefreshTokenDB(ctx context.Context, tokenStr string) (*domain.User, *uuid.UUID, error) {
+// Jika RT yang sudah dicabut dipakai lagi (replay), seluruh family dicabut (OAuth 2.0 Security BCP),
+// kecuali pencabutan terjadi dalam grace window (refresh bersamaan dari beberapa tab browser).
+func (s *UserService) VerifyRefreshTokenDB(ctx context.Context, tokenStr string) (*domain.User, *domain.RefreshToken, error) {
 	claims, err := s.jwtSvc.VerifyRefreshToken(tokenStr)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Ambil jti & userID dari klaim
-	jti := claims.ID
-	if jti == "" {
+	jti, err := uuid.Parse(claims.ID)
+	if err != nil {
 		return nil, nil, errors.New("missing jti")
 	}
 	userID, err := uuid.Parse(claims.Subject)
@@ -167,38 +271,147 @@ func (s *UserService) VerifyRefreshTokenDB(ctx context.Context, tokenStr string)
 	}
 
 	// Cek record RT di DB
-	rtModel, err := s.rtRepo.FindByID(ctx, uuid.MustParse(jti))
+	rtModel, err := s.rtRepo.FindByID(ctx, jti)
 	if err != nil {
 		return nil, nil, errors.New("refresh token not found")
 	}
-	if rtModel.Revoked || time.Now().After(rtModel.ExpiresAt) {
-		return nil, nil, errors.New("refresh token revoked or expired")
+	if rtModel.UserID != userID {
+		return nil, nil, errors.New("refresh token subject mismatch")
+	}
+	if rtModel.Revoked {
+		// RT baru saja dirotasi oleh request lain (mis. tab lain) → bukan serangan
+		if rtModel.RevokedAt != nil && time.Since(*rtModel.RevokedAt) <= s.cfg.RefreshReuseGrace {
+			return nil, nil, ErrRefreshTokenConcurrent
+		}
+		// Replay RT lama → anggap token bocor, cabut seluruh family
+		if err := s.rtRepo.RevokeFamily(ctx, rtModel.FamilyID); err != nil {
+			return nil, nil, err
+		}
+		s.logger.Warn("security: refresh token reuse terdeteksi, family dicabut",
+			zap.String("event", "refresh_token_reuse"),
+			zap.String("user_id", rtModel.UserID.String()),
+			zap.String("family_id", rtModel.FamilyID.String()),
+			zap.String("token_id", rtModel.ID.String()),
+		)
+		return nil, nil, ErrRefreshTokenReused
+	}
+	if time.Now().After(rtModel.ExpiresAt) {
+		return nil, nil, errors.New("refresh token expired")
 	}
 
-	// Validasi hash (opsional—kalau ingin bind token ke DB)
-	// hash := s.jwtSvc.HashRefreshToken(tokenStr)
-	// if hash != rtModel.TokenHash { return nil, nil, errors.New("token mismatch") }
+	// Validasi hash: pastikan token yang dikirim sama dengan yang tersimpan
+	if s.jwtSvc.HashRefreshToken(tokenStr) != rtModel.TokenHash {
+		return nil, nil, errors.New("token mismatch")
+	}
 
 	// Ambil user (pastikan repository user Anda tersedia)
 	user, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		return nil, nil, err
 	}
-	rtID := uuid.MustParse(jti)
-	return user, &rtID, nil
+	if user.Suspended() {
+		return nil, nil, ErrAccountSuspended
+	}
+	return user, rtModel, nil
 }
 
-// RotateRefreshToken: revoke RT lama & simpan RT baru
-func (s *UserService) RotateRefreshToken(ctx context.Context, user *domain.User, oldRTID uuid.UUID, newRT string, issuedAt, expiresAt time.Time, newJTI string) error {
-	// Revoke RT lama
-	if err := s.rtRepo.Revoke(ctx, oldRTID); err != nil {
+// RotateRefreshToken: revoke RT lama & simpan RT baru dalam family yang sama
+func (s *UserService) RotateRefreshToken(ctx context.Context, user *domain.User, oldRT *domain.RefreshToken, newRT string, issuedAt, expiresAt time.Time, newJTI string, meta SessionMeta) error {
+	// Revoke RT lama secara kondisional; jika sudah dicabut berarti request lain menang balapan
+	revoked, err := s.rtRepo.RevokeIfActive(ctx, oldRT.ID)
+	if err != nil {
 		return err
 	}
-	// Simpan RT baru
-	return s.SaveRefreshToken(ctx, user.ID, newRT, issuedAt, expiresAt, newJTI)
+	if !revoked {
+		return ErrRefreshTokenConcurrent
+	}
+	// Simpan RT baru sebagai anak dari RT lama
+	parentID := oldRT.ID
+	return s.saveRefreshToken(ctx, user.ID, newRT, issuedAt, expiresAt, uuid.MustParse(newJTI), oldRT.FamilyID, &parentID, meta)
 }
 
 // RevokeAllUserTokens: logout dari semua sesi
 func (s *UserService) RevokeAllUserTokens(ctx context.Context, userID uuid.UUID) error {
 	return s.rtRepo.RevokeAllByUser(ctx, userID)
-}
\ No newline at end of file
+}
+
+// ListSessions mengembalikan sesi perangkat aktif milik user.
+// Satu sesi = satu family RT; RT aktif di family tersebut mewakili kondisi terakhir sesi.
+func (s *UserService) ListSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]dto.SessionResponse, error) {
+	tokens, err := s.rtRepo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]dto.SessionResponse, 0, len(tokens))
+	for _, rt := range tokens {
+		lastUsed := rt.IssuedAt
+		if rt.LastUsedAt != nil {
+			lastUsed = *rt.LastUsedAt
+		}
+		sessions = append(sessions, dto.SessionResponse{
+			ID:         rt.FamilyID.String(),
+			UserAgent:  rt.UserAgent,
+			IPAddress:  rt.IPAddress,
+			LastUsedAt: lastUsed.Format(time.RFC3339),
+			ExpiresAt:  rt.ExpiresAt.Format(time.RFC3339),
+			Current:    rt.FamilyID == currentSessionID,
+		})
+	}
+	return sessions, nil
+}
+
+// RecordAccessToken mencatat jti AT terakhir yang diterbitkan untuk sesi (family RT).
+func (s *UserService) RecordAccessToken(ctx context.Context, sessionID uuid.UUID, jti string) error {
+	return s.rtRepo.SetAccessTokenID(ctx, sessionID, jti)
+}
+
+// RevokeSession mencabut satu sesi perangkat milik user (seluruh RT dalam family-nya).
+// Mengembalikan jti AT terakhir sesi tersebut agar pemanggil bisa memasukkannya ke denylist.
+func (s *UserService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) ([]string, error) {
+	tokens, err := s.rtRepo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Pastikan sesi memang milik user ini sebelum dicabut
+	for _, rt := range tokens {
+		if rt.FamilyID == sessionID {
+			return sessionAccessTokenIDs([]domain.RefreshToken{rt}), s.rtRepo.RevokeFamily(ctx, sessionID)
+		}
+	}
+	return nil, ErrSessionNotFound
+}
+
+// RevokeOtherSessions mencabut semua sesi user kecuali sesi saat ini ("log out other devices").
+// Mengembalikan jti AT terakhir setiap sesi yang dicabut.
+func (s *UserService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]string, error) {
+	tokens, err := s.rtRepo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	others := slices.DeleteFunc(tokens, func(rt domain.RefreshToken) bool { return rt.FamilyID == currentSessionID })
+	return sessionAccessTokenIDs(others), s.rtRepo.RevokeAllByUserExceptFamily(ctx, userID, currentSessionID)
+}
+
+// sessionAccessTokenIDs mengumpulkan jti AT yang tercatat pada RT sesi.
+func sessionAccessTokenIDs(tokens []domain.RefreshToken) []string {
+	jtis := make([]string, 0, len(tokens))
+	for _, rt := range tokens {
+		if rt.AccessTokenID != "" {
+			jtis = append(jtis, rt.AccessTokenID)
+		}
+	}
+	return jtis
+}
+
+// truncate memotong string agar muat di kolom database. Pemotongan mundur ke awal rune dan byte
+// UTF-8 yang tidak valid dibuang, karena string UTF-8 rusak ditolak kolom utf8mb4 (strict mode).
+func truncate(str string, max int) string {
+	str = strings.ToValidUTF8(str, "")
+	if len(str) <= max {
+		return str
+	}
+	for max > 0 && !utf8.RuneStart(str[max]) {
+		max--
+	}
+	return str[:max]
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	webauthnCeremonyRegistration = "registration"
+	webauthnCeremonyLogin        = "login"
+	// WebAuthnSessionTTL adalah batas waktu antara langkah begin dan finish sebuah ceremony.
+	WebAuthnSessionTTL            = 5 * time.Minute
+	maxWebAuthnCredentialsPerUser = 10
+	defaultWebAuthnCredentialName = "Passkey"
+)
+
+var (
+	// ErrInvalidWebAuthnSession dikembalikan saat cookie sesi ceremony hilang, kedaluwarsa, atau bukan milik user.
+	ErrInvalidWebAuthnSession = errors.New("sesi passkey tidak valid atau kedaluwarsa, ulangi dari awal")
+	// ErrInvalidPasskey dikembalikan saat respons authenticator gagal diverifikasi.
+	ErrInvalidPasskey = errors.New("passkey tidak valid")
+	// ErrWebAuthnCredentialLimit dikembalikan saat user sudah memiliki terlalu banyak passkey.
+	ErrWebAuthnCredentialLimit = fmt.Errorf("maksimal %d passkey per user", maxWebAuthnCredentialsPerUser)
+	// ErrWebAuthnCredentialNotFound dikembalikan saat passkey tidak ada atau bukan milik user.
+	ErrWebAuthnCredentialNotFound = errors.New("passkey tidak ditemukan")
+)
+
+// WebAuthnService mengelola passkey: pendaftaran oleh user yang login dan login tanpa password.
+// Challenge tiap ceremony disimpan di token bertanda tangan (cookie) sehingga server tetap stateless.
+// Login memakai discoverable credential: browser memilih passkey tanpa user mengetik email.
+type WebAuthnService struct {
+	webauthn   *webauthn.WebAuthn
+	credRepo   repository.WebAuthnCredentialRepository
+	userRepo   repository.UserRepository
+	jwtService *JWTService
+	validator  *validator.Validate
+	logger     *zap.Logger
+}
+
+// NewWebAuthnService membuat instance WebAuthnService baru dari konfigurasi WEBAUTHN_*.
+func NewWebAuthnService(credRepo repository.WebAuthnCredentialRepository, userRepo repository.UserRepository, jwtService *JWTService, cfg *config.Config, logger *zap.Logger) (*WebAuthnService, error) {
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.WebAuthnRPID,
+		RPDisplayName: cfg.WebAuthnRPName,
+		RPOrigins:     cfg.WebAuthnRPOrigins,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("konfigurasi WebAuthn tidak valid: %w", err)
+	}
+	return &WebAuthnService{
+		webauthn:   wa,
+		credRepo:   credRepo,
+		userRepo:   userRepo,
+		jwtService: jwtService,
+		validator:  validator.New(),
+		logger:     logger,
+	}, nil
+}
+
+// BeginRegistration membuat opsi navigator.credentials.create() untuk user yang login.
+// Mengembalikan opsi untuk browser dan token sesi yang disimpan di cookie hingga FinishRegistration.
+func (s *WebAuthnService) BeginRegistration(ctx context.Context, userID uuid.UUID) (*protocol.CredentialCreation, string, time.Time, error) {
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	if len(user.credentials) >= maxWebAuthnCredentialsPerUser {
+		return nil, "", time.Time{}, ErrWebAuthnCredentialLimit
+	}
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.credentials))
+	for _, c := range user.credentials {
+		exclusions = append(exclusions, c.Descriptor())
+	}
+	creation, session, err := s.webauthn.BeginRegistration(user,
+		// Passkey wajib resident agar bisa dipakai login tanpa mengetik email
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+		webauthn.WithExclusions(exclusions),
+	)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	token, exp, err := s.jwtService.GenerateWebAuthnSessionToken(webauthnCeremonyRegistration, *session, WebAuthnSessionTTL)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	return creation, token, exp, nil
+}
+
+// FinishRegistration memverifikasi attestation dari authenticator lalu menyimpan passkey baru.
+func (s *WebAuthnService) FinishRegistration(ctx context.Context, userID uuid.UUID, sessionToken string, req dto.FinishWebAuthnRegistrationRequest) (*dto.WebAuthnCredentialResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	session, err := s.jwtService.VerifyWebAuthnSessionToken(sessionToken, webauthnCeremonyRegistration)
+	if err != nil || !bytes.Equal(session.UserID, userID[:]) {
+		return nil, ErrInvalidWebAuthnSession
+	}
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(user.credentials) >= maxWebAuthnCredentialsPerUser {
+		return nil, ErrWebAuthnCredentialLimit
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(req.Credential))
+	if err != nil {
+		return nil, s.passkeyError("parse attestation", userID, err)
+	}
+	credential, err := s.webauthn.CreateCredential(user, *session, parsed)
+	if err != nil {
+		return nil, s.passkeyError("verifikasi attestation", userID, err)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = defaultWebAuthnCredentialName
+	}
+	transports := make([]string, 0, len(credential.Transport))
+	for _, t := range credential.Transport {
+		transports = append(transports, string(t))
+	}
+	record := &domain.WebAuthnCredential{
+		ID:              uuid.New(),
+		UserID:          userID,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      strings.Join(transports, ","),
+		AAGUID:          credential.Authenticator.AAGUID,
+		Flags:           uint8(credential.Flags.ProtocolValue()),
+		SignCount:       credential.Authenticator.SignCount,
+		Name:            name,
+		CreatedAt:       time.Now(),
+	}
+	if err := s.credRepo.Create(ctx, record); err != nil {
+		return nil, err
+	}
+	s.logger.Info("passkey didaftarkan",
+		zap.String("user_id", userID.String()),
+		zap.String("credential_id", record.ID.String()),
+	)
+	res := webauthnCredentialResponse(record)
+	return &res, nil
+}
+
+// BeginLogin membuat opsi navigator.credentials.get() untuk login dengan discoverable credential.
+func (s *WebAuthnService) BeginLogin() (*protocol.CredentialAssertion, string, time.Time, error) {
+	assertion, session, err := s.webauthn.BeginDiscoverableLogin()
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	token, exp, err := s.jwtService.GenerateWebAuthnSessionToken(webauthnCeremonyLogin, *session, WebAuthnSessionTTL)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	return assertion, token, exp, nil
+}
+
+// FinishLogin memverifikasi assertion dari authenticator dan mengembalikan pemilik passkey.
+// userVerified bernilai true jika authenticator memverifikasi user (PIN/biometrik), artinya
+// passkey sudah memenuhi dua faktor sehingga tantangan 2FA tidak diperlukan lagi.
+func (s *WebAuthnService) FinishLogin(ctx context.Context, sessionToken string, body io.Reader) (user *domain.User, userVerified bool, err error) {
+	session, err := s.jwtService.VerifyWebAuthnSessionToken(sessionToken, webauthnCeremonyLogin)
+	if err != nil {
+		return nil, false, ErrInvalidWebAuthnSession
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBody(body)
+	if err != nil {
+		return nil, false, s.passkeyError("parse assertion", uuid.Nil, err)
+	}
+
+	var owner *webauthnUser
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		record, err := s.credRepo.FindByCredentialID(ctx, rawID)
+		if err != nil {
+			return nil, err
+		}
+		if record == nil || !bytes.Equal(userHandle, record.UserID[:]) {
+			return nil, ErrInvalidPasskey
+		}
+		owner, err = s.loadUser(ctx, record.UserID)
+		return owner, err
+	}
+	_, credential, err := s.webauthn.ValidatePasskeyLogin(handler, *session, parsed)
+	if err != nil {
+		return nil, false, s.passkeyError("verifikasi assertion", uuid.Nil, err)
+	}
+
+	record := owner.record(credential.ID)
+	now := time.Now()
+	record.SignCount = credential.Authenticator.SignCount
+	record.Flags = credentialFlags(credential.Flags)
+	record.CloneWarning = credential.Authenticator.CloneWarning
+	record.LastUsedAt = &now
+	if err := s.credRepo.UpdateAfterLogin(ctx, record); err != nil {
+		return nil, false, err
+	}
+	// Sign count mundur berarti kunci privat kemungkinan digandakan: tolak dan minta user mendaftar ulang
+	if credential.Authenticator.CloneWarning {
+		s.logger.Warn("passkey terindikasi digandakan (sign count mundur)",
+			zap.String("user_id", owner.user.ID.String()),
+			zap.String("credential_id", record.ID.String()),
+		)
+		return nil, false, ErrInvalidPasskey
+	}
+	s.logger.Info("login dengan passkey",
+		zap.String("user_id", owner.user.ID.String()),
+		zap.String("credential_id", record.ID.String()),
+	)
+	return owner.user, credential.Flags.UserVerified, nil
+}
+
+// ListCredentials mengembalikan passkey milik user.
+func (s *WebAuthnService) ListCredentials(ctx context.Context, userID uuid.UUID) ([]dto.WebAuthnCredentialResponse, error) {
+	records, err := s.credRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.WebAuthnCredentialResponse, 0, len(records))
+	for i := range records {
+		res = append(res, webauthnCredentialResponse(&records[i]))
+	}
+	return res, nil
+}
+
+// DeleteCredential menghapus passkey milik user.
+func (s *WebAuthnService) DeleteCredential(ctx context.Context, userID, id uuid.UUID) error {
+	ok, err := s.credRepo.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrWebAuthnCredentialNotFound
+	}
+	s.logger.Info("passkey dihapus", zap.String("user_id", userID.String()), zap.String("credential_id", id.String()))
+	return nil
+}
+
+// loadUser memuat user beserta passkey-nya dalam bentuk webauthn.User.
+func (s *WebAuthnService) loadUser(ctx context.Context, userID uuid.UUID) (*webauthnUser, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	records, err := s.credRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	u := &webauthnUser{user: user, records: records}
+	for _, r := range records {
+		transports := make([]protocol.AuthenticatorTransport, 0)
+		for _, t := range r.TransportList() {
+			transports = append(transports, protocol.AuthenticatorTransport(t))
+		}
+		u.credentials = append(u.credentials, webauthn.Credential{
+			ID:              r.CredentialID,
+			PublicKey:       r.PublicKey,
+			AttestationType: r.AttestationType,
+			Transport:       transports,
+			Flags:           webauthn.NewCredentialFlags(protocol.AuthenticatorFlags(r.Flags)),
+			Authenticator: webauthn.Authenticator{
+				AAGUID:       r.AAGUID,
+				SignCount:    r.SignCount,
+				CloneWarning: r.CloneWarning,
+			},
+		})
+	}
+	return u, nil
+}
+
+// passkeyError mencatat detail kegagalan verifikasi dari library lalu mengembalikan ErrInvalidPasskey.
+// Detail tidak dikirim ke klien agar tidak membantu penyerang.
+func (s *WebAuthnService) passkeyError(step string, userID uuid.UUID, err error) error {
+	fields := []zap.Field{zap.String("step", step), zap.Error(err)}
+	var pe *protocol.Error
+	if errors.As(err, &pe) {
+		fields = append(fields, zap.String("details", pe.Details), zap.String("info", pe.DevInfo))
+	}
+	if userID != uuid.Nil {
+		fields = append(fields, zap.String("user_id", userID.String()))
+	}
+	s.logger.Info("verifikasi passkey gagal", fields...)
+	return ErrInvalidPasskey
+}
+
+// webauthnUser mengadaptasi domain.User ke interface webauthn.User.
+// User handle memakai 16 byte UUID user: acak dan tidak mengandung data pribadi.
+type webauthnUser struct {
+	user        *domain.User
+	records     []domain.WebAuthnCredential
+	credentials []webauthn.Credential
+}
+
+func (u *webauthnUser) WebAuthnID() []byte                         { return u.user.ID[:] }
+func (u *webauthnUser) WebAuthnName() string                       { return u.user.Email }
+func (u *webauthnUser) WebAuthnDisplayName() string                { return u.user.Name }
+func (u *webauthnUser) WebAuthnCredentials() []webauthn.Credential { return u.credentials }
+
+// record mengembalikan baris database untuk credential ID tertentu.
+func (u *webauthnUser) record(credentialID []byte) *domain.WebAuthnCredential {
+	for i := range u.records {
+		if bytes.Equal(u.records[i].CredentialID, credentialID) {
+			return &u.records[i]
+		}
+	}
+	return nil
+}
+
+// credentialFlags menyusun ulang byte flag dari hasil login (ProtocolValue tidak ikut diperbarui library).
+func credentialFlags(f webauthn.CredentialFlags) uint8 {
+	var flags protocol.AuthenticatorFlags
+	if f.UserPresent {
+		flags |= protocol.FlagUserPresent
+	}
+	if f.UserVerified {
+		flags |= protocol.FlagUserVerified
+	}
+	if f.BackupEligible {
+		flags |= protocol.FlagBackupEligible
+	}
+	if f.BackupState {
+		flags |= protocol.FlagBackupState
+	}
+	return uint8(flags)
+}
+
+func webauthnCredentialResponse(c *domain.WebAuthnCredential) dto.WebAuthnCredentialResponse {
+	return dto.WebAuthnCredentialResponse{
+		ID:         c.ID.String(),
+		Name:       c.Name,
+		Transports: c.TransportList(),
+		Synced:     protocol.AuthenticatorFlags(c.Flags).HasBackupState(),
+		LastUsedAt: formatTimePtr(c.LastUsedAt),
+		CreatedAt:  c.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"github.com/itujun/project-ecommerce-go-next/internal/domain"
+	"github.com/itujun/project-ecommerce-go-next/internal/dto"
+	"github.com/itujun/project-ecommerce-go-next/internal/repository"
+	"github.com/itujun/project-ecommerce-go-next/internal/webauthntest"
+	"go.uber.org/zap"
+)
+
+const testWebAuthnOrigin = "http://localhost:3000"
+
+type webauthnFixture struct {
+	svc   *WebAuthnService
+	creds *memWebAuthnCredentials
+	users map[uuid.UUID]*domain.User
+}
+
+func newWebAuthnFixture(t *testing.T) *webauthnFixture {
+	t.Helper()
+	cfg := &config.Config{
+		JWTKeyDir:         t.TempDir(),
+		JWTSigningAlg:     AlgEdDSA,
+		JWTKeyRotation:    time.Hour,
+		AccessTTL:         15 * time.Minute,
+		WebAuthnRPID:      "localhost",
+		WebAuthnRPName:    "Ecommerce",
+		WebAuthnRPOrigins: []string{testWebAuthnOrigin},
+	}
+	keys, err := NewKeyManager(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &webauthnFixture{creds: &memWebAuthnCredentials{}, users: make(map[uuid.UUID]*domain.User)}
+	f.svc, err = NewWebAuthnService(f.creds, staticUsers{users: f.users}, NewJWTService(cfg, keys), cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f *webauthnFixture) addUser(email string) *domain.User {
+	user := &domain.User{ID: uuid.New(), Name: email, Email: email}
+	f.users[user.ID] = user
+	return user
+}
+
+// register mendaftarkan passkey baru dari authenticator software untuk user.
+func (f *webauthnFixture) register(t *testing.T, user *domain.User, auth *webauthntest.Authenticator) *dto.WebAuthnCredentialResponse {
+	t.Helper()
+	creation, session, _, err := f.svc.BeginRegistration(t.Context(), user.ID)
+	if err != nil {
+		t.Fatalf("BeginRegistration: %v", err)
+	}
+	credential, err := auth.Create(creation)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	res, err := f.svc.FinishRegistration(t.Context(), user.ID, session, dto.FinishWebAuthnRegistrationRequest{Credential: credential})
+	if err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+	return res
+}
+
+// login menjalankan ceremony login discoverable dengan authenticator software.
+func (f *webauthnFixture) login(t *testing.T, auth *webauthntest.Authenticator) (*domain.User, bool, error) {
+	t.Helper()
+	assertion, session, _, err := f.svc.BeginLogin()
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	response, err := auth.Get(assertion)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	return f.svc.FinishLogin(t.Context(), session, bytes.NewReader(response))
+}
+
+func newAuthenticator(t *testing.T) *webauthntest.Authenticator {
+	t.Helper()
+	auth, err := webauthntest.New(testWebAuthnOrigin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth
+}
+
+func TestWebAuthnRegisterAndLoginRoundTrip(t *testing.T) {
+	f := newWebAuthnFixture(t)
+	user := f.addUser("budi@example.com")
+	auth := newAuthenticator(t)
+
+	registered := f.register(t, user, auth)
+	if registered.Name != defaultWebAuthnCredentialName {
+		t.Fatalf("nama passkey = %q, want %q", registered.Name, defaultWebAuthnCredentialName)
+	}
+
+	got, verified, err := f.login(t, auth)
+	if err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+	if got.ID != user.ID || !verified {
+		t.Fatalf("login passkey: user %s verified %v, want user %s verified true", got.ID, verified, user.ID)
+	}
+	record := f.creds.get(uuid.MustParse(registered.ID))
+	if record.SignCount != auth.SignCount || record.LastUsedAt == nil {
+		t.Fatalf("sign count %d (want %d) dan waktu pakai harus tersimpan", record.SignCount, auth.SignCount)
+	}
+}
+
+func TestWebAuthnLoginRejectsSignCountRegression(t *testing.T) {
+	f := newWebAuthnFixture(t)
+	user := f.addUser("budi@example.com")
+	auth := newAuthenticator(t)
+	registered := f.register(t, user, auth)
+	for range 2 {
+		if _, _, err := f.login(t, auth); err != nil {
+			t.Fatalf("FinishLogin: %v", err)
+		}
+	}
+
+	// Salinan kunci dengan counter lama (authenticator digandakan)
+	auth.SignCount = 0
+	if _, _, err := f.login(t, auth); !errors.Is(err, ErrInvalidPasskey) {
+		t.Fatalf("sign count mundur: err = %v, want ErrInvalidPasskey", err)
+	}
+	if record := f.creds.get(uuid.MustParse(registered.ID)); !record.CloneWarning {
+		t.Fatal("clone warning harus tersimpan pada passkey")
+	}
+}
+
+func TestWebAuthnDeleteRejectsOtherUsersCredential(t *testing.T) {
+	f := newWebAuthnFixture(t)
+	owner, other := f.addUser("budi@example.com"), f.addUser("sari@example.com")
+	auth := newAuthenticator(t)
+	id := uuid.MustParse(f.register(t, owner, auth).ID)
+
+	if err := f.svc.DeleteCredential(t.Context(), other.ID, id); !errors.Is(err, ErrWebAuthnCredentialNotFound) {
+		t.Fatalf("hapus passkey user lain: err = %v, want ErrWebAuthnCredentialNotFound", err)
+	}
+	if f.creds.get(id) == nil {
+		t.Fatal("passkey pemilik tidak boleh terhapus")
+	}
+	if _, _, err := f.login(t, auth); err != nil {
+		t.Fatalf("pemilik harus tetap bisa login: %v", err)
+	}
+
+	if err := f.svc.DeleteCredential(t.Context(), owner.ID, id); err != nil {
+		t.Fatalf("hapus oleh pemilik: %v", err)
+	}
+	if f.creds.get(id) != nil {
+		t.Fatal("passkey harus terhapus")
+	}
+}
+
+// staticUsers adalah UserRepository yang hanya mendukung GetUserByID; method lain akan panic.
+type staticUsers struct {
+	repository.UserRepository
+	users map[uuid.UUID]*domain.User
+}
+
+func (m staticUsers) GetUserByID(_ context.Context, id uuid.UUID) (*domain.User, error) {
+	user, ok := m.users[id]
+	if !ok {
+		return nil, errors.New("user tidak ditemukan")
+	}
+	return user, nil
+}
+
+// memWebAuthnCredentials adalah WebAuthnCredentialRepository in-memory dengan aturan kepemilikan
+// yang sama seperti implementasi database (Delete hanya menghapus milik userID).
+type memWebAuthnCredentials struct {
+	mu      sync.Mutex
+	records []domain.WebAuthnCredential
+}
+
+var _ repository.WebAuthnCredentialRepository = (*memWebAuthnCredentials)(nil)
+
+func (m *memWebAuthnCredentials) get(id uuid.UUID) *domain.WebAuthnCredential {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range m.records {
+		if r.ID == id {
+			return &r
+		}
+	}
+	return nil
+}
+
+func (m *memWebAuthnCredentials) Create(_ context.Context, credential *domain.WebAuthnCredential) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, *credential)
+	return nil
+}
+
+func (m *memWebAuthnCredentials) FindByCredentialID(_ context.Context, credentialID []byte) (*domain.WebAuthnCredential, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range m.records {
+		if bytes.Equal(r.CredentialID, credentialID) {
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memWebAuthnCredentials) ListByUser(_ context.Context, userID uuid.UUID) ([]domain.WebAuthnCredential, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []domain.WebAuthnCredential
+	for _, r := range m.records {
+		if r.UserID == userID {
+			list = append(list, r)
+		}
+	}
+	return list, nil
+}
+
+func (m *memWebAuthnCredentials) UpdateAfterLogin(_ context.Context, credential *domain.WebAuthnCredential) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.records {
+		if m.records[i].ID == credential.ID {
+			m.records[i].SignCount = credential.SignCount
+			m.records[i].Flags = credential.Flags
+			m.records[i].CloneWarning = credential.CloneWarning
+			m.records[i].LastUsedAt = credential.LastUsedAt
+		}
+	}
+	return nil
+}
+
+func (m *memWebAuthnCredentials) Delete(_ context.Context, userID, id uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	before := len(m.records)
+	m.records = slices.DeleteFunc(m.records, func(r domain.WebAuthnCredential) bool {
+		return r.ID == id && r.UserID == userID
+	})
+	return len(m.records) < before, nil
+}
@@ -0,0 +1,79 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/itujun/project-ecommerce-go-next/internal/config"
+	"go.uber.org/zap"
+)
+
+// Message adalah SMS teks yang dikirim aplikasi. To berformat E.164, mis. +6281234567890.
+type Message struct {
+	To   string
+	Body string
+}
+
+// Sender adalah antarmuka gateway SMS. Implementasi penyedia (Twilio, Zenziva, dst.)
+// cukup memenuhi interface ini tanpa mengubah service.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSender memilih implementasi Sender berdasarkan SMS_DRIVER ("log" atau "file").
+func NewSender(cfg *config.Config, logger *zap.Logger) (Sender, error) {
+	switch cfg.SMSDriver {
+	case "log", "":
+		return NewLogSender(logger), nil
+	case "file":
+		return NewFileSender(cfg.SMSFileDir)
+	default:
+		return nil, fmt.Errorf("SMS_DRIVER tidak dikenal: %s", cfg.SMSDriver)
+	}
+}
+
+// LogSender menulis SMS ke logger; cocok untuk dev lokal.
+type LogSender struct {
+	logger *zap.Logger
+}
+
+// NewLogSender membuat LogSender baru.
+func NewLogSender(logger *zap.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+// Send mencatat isi SMS ke log.
+func (s *LogSender) Send(_ context.Context, msg Message) error {
+	s.logger.Info("sms terkirim (log)",
+		zap.String("to", msg.To),
+		zap.String("body", msg.Body),
+	)
+	return nil
+}
+
+// FileSender menyimpan setiap SMS sebagai file .txt di direktori;
+// test dan dev lokal bisa membaca kode OTP dari file tersebut.
+type FileSender struct {
+	dir string
+}
+
+// NewFileSender membuat FileSender dan memastikan direktorinya ada.
+func NewFileSender(dir string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("gagal membuat direktori sms: %w", err)
+	}
+	return &FileSender{dir: dir}, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Send menulis SMS ke <dir>/<timestamp>-<penerima>.txt.
+func (s *FileSender) Send(_ context.Context, msg Message) error {
+	name := fmt.Sprintf("%s-%s.txt", time.Now().UTC().Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	content := fmt.Sprintf("To: %s\r\nDate: %s\r\n\r\n%s\r\n", msg.To, time.Now().Format(time.RFC1123Z), msg.Body)
+	return os.WriteFile(filepath.Join(s.dir, name), []byte(content), 0o644)
+}
@@ -1,9 +1,11 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/itujun/project-ecommerce-go-next/internal/password"
 )
 
 // ValidationErrorsToMap mengubah ValidationErrors menjadi map[field]pesan.
@@ -17,6 +19,8 @@ func ValidationErrorsToMap(errs validator.ValidationErrors) map[string]string {
                 errorsMap["name"] = "Nama wajib diisi"
             case "min":
                 errorsMap["name"] = fmt.Sprintf("Nama minimal %s karakter", e.Param())
+            case "max":
+                errorsMap["name"] = fmt.Sprintf("Nama maksimal %s karakter", e.Param())
             }
         case "Email":
             switch e.Tag() {
@@ -29,8 +33,137 @@ func ValidationErrorsToMap(errs validator.ValidationErrors) map[string]string {
             switch e.Tag() {
             case "required":
                 errorsMap["password"] = "Kata sandi wajib diisi"
-            case "min":
-                errorsMap["password"] = fmt.Sprintf("Kata sandi minimal %s karakter", e.Param())
+            }
+        case "Token":
+            switch e.Tag() {
+            case "required":
+                errorsMap["token"] = "Token wajib diisi"
+            }
+        case "Code":
+            switch e.Tag() {
+            case "required", "required_without", "required_without_all":
+                errorsMap["code"] = "Kode 2FA wajib diisi"
+            case "len", "numeric":
+                errorsMap["code"] = "Kode 2FA harus 6 digit angka"
+            }
+        case "CurrentPassword":
+            switch e.Tag() {
+            case "required":
+                errorsMap["current_password"] = "Kata sandi saat ini wajib diisi"
+            }
+        case "NewPassword":
+            switch e.Tag() {
+            case "required":
+                errorsMap["new_password"] = "Kata sandi baru wajib diisi"
+            }
+        case "RecoveryCode":
+            switch e.Tag() {
+            case "required_without", "required_without_all":
+                errorsMap["recovery_code"] = "Kode 2FA atau recovery code wajib diisi"
+            }
+        case "MFAToken":
+            switch e.Tag() {
+            case "required":
+                errorsMap["mfa_token"] = "MFA token wajib diisi"
+            }
+        case "Scopes":
+            switch e.Tag() {
+            case "required", "min":
+                errorsMap["scopes"] = "Minimal satu scope wajib dipilih"
+            }
+        case "ExpiresInDays":
+            switch e.Tag() {
+            case "min", "max":
+                errorsMap["expires_in_days"] = "Masa berlaku harus antara 1 dan 365 hari"
+            }
+        case "RoleID":
+            switch e.Tag() {
+            case "required":
+                errorsMap["role_id"] = "Role wajib diisi"
+            case "uuid":
+                errorsMap["role_id"] = "Role tidak valid"
+            }
+        case "Status":
+            errorsMap["status"] = "Status harus active atau suspended"
+        case "Page":
+            errorsMap["page"] = "Halaman minimal 1"
+        case "PageSize":
+            errorsMap["page_size"] = "Ukuran halaman antara 1 dan 100"
+        case "Query":
+            errorsMap["q"] = "Kata kunci terlalu panjang"
+        case "Reason":
+            switch e.Tag() {
+            case "required":
+                errorsMap["reason"] = "Alasan wajib diisi"
+            case "max":
+                errorsMap["reason"] = "Alasan maksimal 255 karakter"
+            }
+        case "ActorID":
+            errorsMap["actor_id"] = "Actor tidak valid"
+        case "UserID":
+            errorsMap["user_id"] = "User tidak valid"
+        case "Action":
+            switch e.Tag() {
+            case "required":
+                errorsMap["action"] = "Aksi wajib diisi"
+            case "excludesall":
+                errorsMap["action"] = "Aksi tidak boleh mengandung koma"
+            default:
+                errorsMap["action"] = "Aksi terlalu panjang"
+            }
+        case "Subject":
+            switch e.Tag() {
+            case "required":
+                errorsMap["subject"] = "Subject wajib diisi"
+            case "max":
+                errorsMap["subject"] = "Subject maksimal 100 karakter"
+            case "excludesall":
+                errorsMap["subject"] = "Subject tidak boleh mengandung koma"
+            }
+        case "Object":
+            switch e.Tag() {
+            case "required":
+                errorsMap["object"] = "Resource wajib diisi"
+            case "max":
+                errorsMap["object"] = "Resource maksimal 100 karakter"
+            case "excludesall":
+                errorsMap["object"] = "Resource tidak boleh mengandung koma"
+            }
+        case "Description":
+            errorsMap["description"] = fmt.Sprintf("Deskripsi maksimal %s karakter", e.Param())
+        case "Condition":
+            switch e.Tag() {
+            case "max":
+                errorsMap["condition"] = "Kondisi maksimal 100 karakter"
+            case "excludesall":
+                errorsMap["condition"] = "Kondisi tidak boleh mengandung koma"
+            }
+        case "Role":
+            switch e.Tag() {
+            case "required":
+                errorsMap["role"] = "Role wajib diisi"
+            case "max":
+                errorsMap["role"] = "Role maksimal 100 karakter"
+            case "excludesall":
+                errorsMap["role"] = "Role tidak boleh mengandung koma"
+            case "nefield":
+                errorsMap["role"] = "Role tidak boleh sama dengan subject"
+            }
+        case "Credential":
+            errorsMap["credential"] = "Data passkey wajib diisi"
+        case "Phone":
+            switch e.Tag() {
+            case "required":
+                errorsMap["phone"] = "Nomor HP wajib diisi"
+            case "max":
+                errorsMap["phone"] = "Nomor HP terlalu panjang"
+            }
+        case "SMSCode":
+            switch e.Tag() {
+            case "required":
+                errorsMap["sms_code"] = "Kode SMS wajib diisi"
+            case "len", "numeric":
+                errorsMap["sms_code"] = "Kode SMS harus 6 digit angka"
             }
         // Tambahkan field lain sesuai kebutuhan
         default:
@@ -40,3 +173,17 @@ func ValidationErrorsToMap(errs validator.ValidationErrors) map[string]string {
     }
     return errorsMap
 }
+
+// FieldErrors mengubah error validasi input menjadi map[field]pesan: ValidationErrors dari validator
+// maupun PolicyError dari kebijakan password. ok bernilai false untuk error lain.
+func FieldErrors(err error) (map[string]string, bool) {
+    var ve validator.ValidationErrors
+    if errors.As(err, &ve) {
+        return ValidationErrorsToMap(ve), true
+    }
+    var pe *password.PolicyError
+    if errors.As(err, &pe) {
+        return map[string]string{pe.Field: pe.Error()}, true
+    }
+    return nil, false
+}
@@ -0,0 +1,183 @@
+// Package webauthntest menyediakan authenticator WebAuthn software untuk menguji passkey tanpa browser:
+// dipakai oleh test WebAuthnService dan oleh cmd/softauthn. Tidak untuk produksi.
+package webauthntest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+// Flag authenticator data (WebAuthn §6.1).
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttestedData = 0x40
+)
+
+var b64 = base64.RawURLEncoding
+
+// Authenticator menyimpan satu passkey: kunci privat ECDSA P-256, credential ID, user handle, dan sign counter.
+// SignCount bisa diubah test untuk mensimulasikan authenticator yang digandakan.
+type Authenticator struct {
+	SignCount uint32
+
+	origin       string
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+}
+
+// options adalah bagian opsi begin yang dibutuhkan authenticator.
+type options struct {
+	PublicKey struct {
+		Challenge string `json:"challenge"`
+		RPID      string `json:"rpId"` // login
+		RP        struct {
+			ID string `json:"id"`
+		} `json:"rp"` // registrasi
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+	} `json:"publicKey"`
+}
+
+// New membuat authenticator untuk origin front-end tertentu (harus termasuk WEBAUTHN_RP_ORIGINS).
+func New(origin string) (*Authenticator, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return &Authenticator{origin: origin, key: key, credentialID: id}, nil
+}
+
+// Create menjawab navigator.credentials.create(): attestation "none" dengan kunci publik COSE ES256.
+// creation adalah opsi begin registrasi dalam bentuk apa pun yang di-encode menjadi JSON yang diterima browser.
+func (a *Authenticator) Create(creation any) (json.RawMessage, error) {
+	opts, err := decodeOptions(creation)
+	if err != nil {
+		return nil, err
+	}
+	userHandle, err := b64.DecodeString(opts.PublicKey.User.ID)
+	if err != nil {
+		return nil, fmt.Errorf("user.id: %w", err)
+	}
+	a.userHandle = userHandle
+	clientData, err := a.clientData("webauthn.create", opts.PublicKey.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	x, y := make([]byte, 32), make([]byte, 32)
+	a.key.PublicKey.X.FillBytes(x)
+	a.key.PublicKey.Y.FillBytes(y)
+	coseKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: x,
+		YCoord: y,
+	})
+	if err != nil {
+		return nil, err
+	}
+	authData := a.authData(opts.PublicKey.RP.ID, flagUserPresent|flagUserVerified|flagAttestedData)
+	authData = append(authData, make([]byte, 16)...) // AAGUID nol
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credentialID)))
+	authData = append(authData, a.credentialID...)
+	authData = append(authData, coseKey...)
+
+	attestation, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]any{
+		"id":    b64.EncodeToString(a.credentialID),
+		"rawId": b64.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]any{
+			"clientDataJSON":    b64.EncodeToString(clientData),
+			"attestationObject": b64.EncodeToString(attestation),
+			"transports":        []string{"internal"},
+		},
+	})
+}
+
+// Get menjawab navigator.credentials.get(): tanda tangan ES256 atas authenticatorData || sha256(clientDataJSON).
+func (a *Authenticator) Get(assertion any) (json.RawMessage, error) {
+	opts, err := decodeOptions(assertion)
+	if err != nil {
+		return nil, err
+	}
+	clientData, err := a.clientData("webauthn.get", opts.PublicKey.Challenge)
+	if err != nil {
+		return nil, err
+	}
+	a.SignCount++
+	authData := a.authData(opts.PublicKey.RPID, flagUserPresent|flagUserVerified)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(bytes.Clone(authData), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]any{
+		"id":    b64.EncodeToString(a.credentialID),
+		"rawId": b64.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]any{
+			"clientDataJSON":    b64.EncodeToString(clientData),
+			"authenticatorData": b64.EncodeToString(authData),
+			"signature":         b64.EncodeToString(signature),
+			"userHandle":        b64.EncodeToString(a.userHandle),
+		},
+	})
+}
+
+func (a *Authenticator) clientData(typ, challenge string) ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"type":        typ,
+		"challenge":   challenge,
+		"origin":      a.origin,
+		"crossOrigin": false,
+	})
+}
+
+// authData menyusun rpIdHash (32) || flags (1) || signCount (4).
+func (a *Authenticator) authData(rpID string, flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, a.SignCount)
+}
+
+// decodeOptions membaca opsi begin seperti yang diterima browser (JSON).
+func decodeOptions(v any) (options, error) {
+	var opts options
+	data, err := json.Marshal(v)
+	if err != nil {
+		return opts, err
+	}
+	if err := json.Unmarshal(data, &opts); err != nil {
+		return opts, fmt.Errorf("opsi WebAuthn tidak valid: %w", err)
+	}
+	return opts, nil
+}